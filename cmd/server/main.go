@@ -1,21 +1,37 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"github.com/wjoseperez20/zenwallet/pkg/amazon"
 	"github.com/wjoseperez20/zenwallet/pkg/api"
 	"github.com/wjoseperez20/zenwallet/pkg/cache"
 	"github.com/wjoseperez20/zenwallet/pkg/database"
 	"github.com/wjoseperez20/zenwallet/pkg/gmail"
+	"github.com/wjoseperez20/zenwallet/pkg/jobs"
+	"github.com/wjoseperez20/zenwallet/pkg/reconciliation"
 	"log"
+	"os"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		reconcile(os.Args[2:])
+		return
+	}
+
 	cache.InitRedis()
 	database.ConnectDatabase()
 	amazon.ConnectAWS()
 	gmail.ConnectGmail()
+	jobs.StartHoldSweeper()
+	jobs.StartScheduler()
+	jobs.StartBudgetMonitor()
+	jobs.StartInterestAccrual()
+	jobs.StartFeeCharger()
+	jobs.StartDailyClose()
 
 	//gin.SetMode(gin.ReleaseMode)
 	gin.SetMode(gin.DebugMode)
@@ -26,3 +42,28 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// reconcile prints the reconciliation report and exits with status 1 when
+// discrepancies are left unfixed. Usage: server reconcile [--fix]
+func reconcile(args []string) {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	fix := flags.Bool("fix", false, "post adjusting entries for every divergent account")
+	_ = flags.Parse(args)
+
+	database.ConnectDatabase()
+
+	report, err := reconciliation.Run(database.DB, *fix, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+
+	if !*fix && len(report.Discrepancies) > 0 {
+		os.Exit(1)
+	}
+}
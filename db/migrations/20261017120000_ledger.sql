@@ -0,0 +1,67 @@
+-- migrate:up
+
+-- Create the sequences
+CREATE SEQUENCE seq_journal_entries_id START WITH 1;
+CREATE SEQUENCE seq_postings_id START WITH 1;
+
+-- Create the tables
+CREATE TABLE journal_entries
+(
+    id             integer                  NOT NULL DEFAULT nextval('seq_journal_entries_id'),
+    transaction_id integer,
+    description    varchar(255)             NOT NULL,
+    date           date                     NOT NULL,
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+-- Account numbers below 10001 are reserved for the system accounts
+-- of the ledger (1 = cash in, 2 = cash out, 3 = transit between
+-- accounts, 4 = reconciliation adjustments, see the constants of the
+-- ledger package), so account_id has no foreign key to the accounts
+-- table.
+CREATE TABLE postings
+(
+    id               integer                  NOT NULL DEFAULT nextval('seq_postings_id'),
+    journal_entry_id integer                  NOT NULL,
+    account_id       integer                  NOT NULL,
+    amount           DECIMAL(10, 2)           NOT NULL,
+    created_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+-- Alter table for foreign keys
+ALTER TABLE postings
+    ADD CONSTRAINT fk_journal_entry FOREIGN KEY (journal_entry_id) REFERENCES journal_entries (id);
+
+CREATE INDEX idx_postings_account_id ON postings (account_id);
+CREATE INDEX idx_journal_entries_transaction_id ON journal_entries (transaction_id);
+
+-- Open the ledger with one entry per existing transaction
+INSERT INTO journal_entries (transaction_id, description, date)
+SELECT id, 'opening balance', date
+FROM transactions;
+
+INSERT INTO postings (journal_entry_id, account_id, amount)
+SELECT j.id, t.account_id, t.amount
+FROM journal_entries j
+         JOIN transactions t ON t.id = j.transaction_id;
+
+INSERT INTO postings (journal_entry_id, account_id, amount)
+SELECT j.id, CASE WHEN t.amount < 0 THEN 2 ELSE 1 END, -t.amount
+FROM journal_entries j
+         JOIN transactions t ON t.id = j.transaction_id;
+
+-- Derive the balances from the postings
+UPDATE accounts
+SET balance = COALESCE((SELECT SUM(p.amount) FROM postings p WHERE p.account_id = accounts.account), 0);
+
+-- migrate:down
+
+-- Drop the ledger tables
+DROP TABLE if exists postings;
+DROP TABLE if exists journal_entries;
+
+-- Drop the sequences
+DROP SEQUENCE seq_postings_id;
+DROP SEQUENCE seq_journal_entries_id;
@@ -0,0 +1,43 @@
+-- migrate:up
+
+-- Create the sequence
+CREATE SEQUENCE seq_transfers_id START WITH 1;
+
+-- Create the table
+CREATE TABLE transfers
+(
+    id              integer                  NOT NULL DEFAULT nextval('seq_transfers_id'),
+    from_account_id integer                  NOT NULL,
+    to_account_id   integer                  NOT NULL,
+    amount          DECIMAL(10, 2)           NOT NULL,
+    date            date                     NOT NULL,
+    status          varchar(20)              NOT NULL DEFAULT 'posted',
+    reversal_of_id  integer,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+-- Link the transactions to their transfer
+ALTER TABLE transactions
+    ADD COLUMN transfer_id integer;
+
+-- Alter table for foreign keys
+ALTER TABLE transfers
+    ADD CONSTRAINT fk_transfer_from_account FOREIGN KEY (from_account_id) REFERENCES accounts (account),
+    ADD CONSTRAINT fk_transfer_to_account FOREIGN KEY (to_account_id) REFERENCES accounts (account),
+    ADD CONSTRAINT fk_transfer_reversal_of FOREIGN KEY (reversal_of_id) REFERENCES transfers (id);
+ALTER TABLE transactions
+    ADD CONSTRAINT fk_transaction_transfer FOREIGN KEY (transfer_id) REFERENCES transfers (id);
+
+-- migrate:down
+
+-- Unlink the transactions
+ALTER TABLE transactions
+    DROP COLUMN transfer_id;
+
+-- Drop the transfers table
+DROP TABLE if exists transfers;
+
+-- Drop the sequence
+DROP SEQUENCE seq_transfers_id;
@@ -0,0 +1,22 @@
+-- migrate:up
+
+-- Link every reversal to the transaction it compensates
+ALTER TABLE transactions
+    ADD COLUMN reversal_of_id integer,
+    ADD COLUMN reversed_by_id integer;
+
+-- Alter table for foreign keys
+ALTER TABLE transactions
+    ADD CONSTRAINT fk_transaction_reversal_of FOREIGN KEY (reversal_of_id) REFERENCES transactions (id),
+    ADD CONSTRAINT fk_transaction_reversed_by FOREIGN KEY (reversed_by_id) REFERENCES transactions (id);
+
+-- A transaction can only be reversed once
+CREATE UNIQUE INDEX idx_transactions_reversal_of_id ON transactions (reversal_of_id);
+
+-- migrate:down
+
+-- Drop the reversal links
+DROP INDEX if exists idx_transactions_reversal_of_id;
+ALTER TABLE transactions
+    DROP COLUMN reversed_by_id,
+    DROP COLUMN reversal_of_id;
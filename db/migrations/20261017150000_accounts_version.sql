@@ -0,0 +1,11 @@
+-- migrate:up
+
+-- Version of the account, increased on every write
+ALTER TABLE accounts
+    ADD COLUMN version integer NOT NULL DEFAULT 1;
+
+-- migrate:down
+
+-- Drop the version column
+ALTER TABLE accounts
+    DROP COLUMN version;
@@ -0,0 +1,46 @@
+-- migrate:up
+
+-- Create the sequence
+CREATE SEQUENCE seq_holds_id START WITH 1;
+
+-- Create the table
+CREATE TABLE holds
+(
+    id             integer                  NOT NULL DEFAULT nextval('seq_holds_id'),
+    account_id     integer                  NOT NULL,
+    amount         DECIMAL(10, 2)           NOT NULL,
+    captured       DECIMAL(10, 2)           NOT NULL DEFAULT 0,
+    description    varchar(255)             NOT NULL DEFAULT '',
+    status         varchar(20)              NOT NULL DEFAULT 'pending',
+    transaction_id integer,
+    expires_at     TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+-- Alter table for foreign keys
+ALTER TABLE holds
+    ADD CONSTRAINT fk_hold_account FOREIGN KEY (account_id) REFERENCES accounts (account),
+    ADD CONSTRAINT fk_hold_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id);
+
+-- The sweeper looks for pending holds past their expiration
+CREATE INDEX idx_holds_pending_expires_at ON holds (expires_at) WHERE status = 'pending';
+
+-- Balance minus the pending holds, no hold exists yet
+ALTER TABLE accounts
+    ADD COLUMN available_balance DECIMAL(10, 2) NOT NULL DEFAULT 0;
+UPDATE accounts
+SET available_balance = balance;
+
+-- migrate:down
+
+-- Drop the available balance
+ALTER TABLE accounts
+    DROP COLUMN available_balance;
+
+-- Drop the holds table
+DROP TABLE if exists holds;
+
+-- Drop the sequence
+DROP SEQUENCE seq_holds_id;
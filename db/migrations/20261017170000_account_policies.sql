@@ -0,0 +1,38 @@
+-- migrate:up
+
+-- Currency of the transactions, the existing ones are in dollars
+ALTER TABLE transactions
+    ADD COLUMN currency varchar(3) NOT NULL DEFAULT 'USD';
+
+-- Create the table, accounts without a row use the default policy
+CREATE TABLE account_policies
+(
+    account_id          integer                  NOT NULL,
+    overdraft_limit     DECIMAL(10, 2)           NOT NULL DEFAULT 0,
+    max_debit           DECIMAL(10, 2),
+    daily_debit_limit   DECIMAL(10, 2),
+    monthly_debit_limit DECIMAL(10, 2),
+    allowed_currencies  text                     NOT NULL DEFAULT '[]',
+    created_at          TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (account_id)
+);
+
+-- Alter table for foreign keys
+ALTER TABLE account_policies
+    ADD CONSTRAINT fk_account_policy_account FOREIGN KEY (account_id) REFERENCES accounts (account) ON DELETE CASCADE;
+
+-- The debit caps sum the debits of an account over a period
+CREATE INDEX idx_transactions_account_id_date ON transactions (account_id, date);
+
+-- migrate:down
+
+-- Drop the index
+DROP INDEX if exists idx_transactions_account_id_date;
+
+-- Drop the account policies table
+DROP TABLE if exists account_policies;
+
+-- Drop the currency column
+ALTER TABLE transactions
+    DROP COLUMN currency;
@@ -0,0 +1,61 @@
+-- migrate:up
+
+-- Create the sequences
+CREATE SEQUENCE seq_schedules_id START WITH 1;
+CREATE SEQUENCE seq_schedule_runs_id START WITH 1;
+
+-- Create the tables
+CREATE TABLE schedules
+(
+    id           integer                  NOT NULL DEFAULT nextval('seq_schedules_id'),
+    account_id   integer                  NOT NULL,
+    amount       DECIMAL(10, 2)           NOT NULL,
+    currency     varchar(3)               NOT NULL DEFAULT 'USD',
+    description  varchar(255)             NOT NULL DEFAULT '',
+    frequency    varchar(20)              NOT NULL,
+    day_of_month integer                  NOT NULL DEFAULT 0,
+    start_date   date                     NOT NULL,
+    end_date     date,
+    count        integer,
+    occurrences  integer                  NOT NULL DEFAULT 0,
+    next_run     date                     NOT NULL,
+    status       varchar(20)              NOT NULL DEFAULT 'active',
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE schedule_runs
+(
+    id             integer                  NOT NULL DEFAULT nextval('seq_schedule_runs_id'),
+    schedule_id    integer                  NOT NULL,
+    date           date                     NOT NULL,
+    status         varchar(20)              NOT NULL,
+    attempts       integer                  NOT NULL DEFAULT 0,
+    transaction_id integer,
+    error          text                     NOT NULL DEFAULT '',
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    UNIQUE (schedule_id, date)
+);
+
+-- Alter table for foreign keys
+ALTER TABLE schedules
+    ADD CONSTRAINT fk_schedule_account FOREIGN KEY (account_id) REFERENCES accounts (account);
+ALTER TABLE schedule_runs
+    ADD CONSTRAINT fk_schedule_run_schedule FOREIGN KEY (schedule_id) REFERENCES schedules (id),
+    ADD CONSTRAINT fk_schedule_run_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id);
+
+-- The scheduler looks for active schedules that are due
+CREATE INDEX idx_schedules_active_next_run ON schedules (next_run) WHERE status = 'active';
+
+-- migrate:down
+
+-- Drop the tables
+DROP TABLE if exists schedule_runs;
+DROP TABLE if exists schedules;
+
+-- Drop the sequences
+DROP SEQUENCE seq_schedule_runs_id;
+DROP SEQUENCE seq_schedules_id;
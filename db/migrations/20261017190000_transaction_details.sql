@@ -0,0 +1,59 @@
+-- migrate:up
+
+-- Create the sequence
+CREATE SEQUENCE seq_categories_id START WITH 1;
+
+-- Create the catalog of categories
+CREATE TABLE categories
+(
+    id         integer                  NOT NULL DEFAULT nextval('seq_categories_id'),
+    code       varchar(50)              NOT NULL,
+    name       varchar(255)             NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (code)
+);
+
+INSERT INTO categories (code, name)
+VALUES ('income', 'Income'),
+       ('groceries', 'Groceries'),
+       ('restaurants', 'Restaurants'),
+       ('transport', 'Transport'),
+       ('housing', 'Housing'),
+       ('utilities', 'Utilities'),
+       ('health', 'Health'),
+       ('entertainment', 'Entertainment'),
+       ('shopping', 'Shopping'),
+       ('fees', 'Fees'),
+       ('other', 'Other');
+
+-- Describe the transactions
+ALTER TABLE transactions
+    ADD COLUMN description        varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN counterparty       varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN category           varchar(50),
+    ADD COLUMN external_reference varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN metadata           jsonb;
+
+-- Alter table for foreign keys
+ALTER TABLE transactions
+    ADD CONSTRAINT fk_transaction_category FOREIGN KEY (category) REFERENCES categories (code);
+
+-- Transactions are filtered by category
+CREATE INDEX idx_transactions_category ON transactions (category);
+
+-- migrate:down
+
+-- Drop the details of the transactions
+ALTER TABLE transactions
+    DROP COLUMN metadata,
+    DROP COLUMN external_reference,
+    DROP COLUMN category,
+    DROP COLUMN counterparty,
+    DROP COLUMN description;
+
+-- Drop the categories table
+DROP TABLE if exists categories;
+
+-- Drop the sequence
+DROP SEQUENCE seq_categories_id;
@@ -0,0 +1,49 @@
+-- migrate:up
+
+-- Create the sequence
+CREATE SEQUENCE seq_rules_id START WITH 1;
+
+-- Create the table
+CREATE TABLE rules
+(
+    id                   integer                  NOT NULL DEFAULT nextval('seq_rules_id'),
+    name                 varchar(255)             NOT NULL,
+    priority             integer                  NOT NULL DEFAULT 0,
+    enabled              boolean                  NOT NULL DEFAULT true,
+    description_contains varchar(255)             NOT NULL DEFAULT '',
+    description_pattern  varchar(255)             NOT NULL DEFAULT '',
+    counterparty         varchar(255)             NOT NULL DEFAULT '',
+    account_id           integer,
+    min_amount           DECIMAL(10, 2),
+    max_amount           DECIMAL(10, 2),
+    category             varchar(50),
+    tags                 jsonb,
+    created_at           TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at           TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+-- Tag the transactions and remember the rule that categorized them
+ALTER TABLE transactions
+    ADD COLUMN tags    jsonb,
+    ADD COLUMN rule_id integer;
+
+-- Alter table for foreign keys
+ALTER TABLE rules
+    ADD CONSTRAINT fk_rule_account FOREIGN KEY (account_id) REFERENCES accounts (account),
+    ADD CONSTRAINT fk_rule_category FOREIGN KEY (category) REFERENCES categories (code);
+ALTER TABLE transactions
+    ADD CONSTRAINT fk_transaction_rule FOREIGN KEY (rule_id) REFERENCES rules (id) ON DELETE SET NULL;
+
+-- migrate:down
+
+-- Drop the columns of the transactions
+ALTER TABLE transactions
+    DROP COLUMN rule_id,
+    DROP COLUMN tags;
+
+-- Drop the rules table
+DROP TABLE if exists rules;
+
+-- Drop the sequence
+DROP SEQUENCE seq_rules_id;
@@ -0,0 +1,56 @@
+-- migrate:up
+
+-- Create the sequences
+CREATE SEQUENCE seq_budgets_id START WITH 1;
+CREATE SEQUENCE seq_budget_alerts_id START WITH 1;
+
+-- Create the tables
+CREATE TABLE budgets
+(
+    id           integer                  NOT NULL DEFAULT nextval('seq_budgets_id'),
+    name         varchar(255)             NOT NULL,
+    account_id   integer,
+    client       varchar(255)             NOT NULL DEFAULT '',
+    category     varchar(50)              NOT NULL,
+    limit_amount DECIMAL(10, 2)           NOT NULL,
+    thresholds   jsonb                    NOT NULL DEFAULT '[80, 100]',
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    CHECK ((account_id IS NULL) <> (client = ''))
+);
+
+CREATE TABLE budget_alerts
+(
+    id         integer                  NOT NULL DEFAULT nextval('seq_budget_alerts_id'),
+    budget_id  integer                  NOT NULL,
+    period     date                     NOT NULL,
+    threshold  integer                  NOT NULL,
+    spent      DECIMAL(10, 2)           NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    UNIQUE (budget_id, period, threshold)
+);
+
+-- Alter table for foreign keys
+ALTER TABLE budgets
+    ADD CONSTRAINT fk_budget_account FOREIGN KEY (account_id) REFERENCES accounts (account),
+    ADD CONSTRAINT fk_budget_category FOREIGN KEY (category) REFERENCES categories (code);
+ALTER TABLE budget_alerts
+    ADD CONSTRAINT fk_budget_alert_budget FOREIGN KEY (budget_id) REFERENCES budgets (id) ON DELETE CASCADE;
+
+-- Spending is summed per category and month
+CREATE INDEX idx_transactions_category_date ON transactions (category, date);
+
+-- migrate:down
+
+-- Drop the index
+DROP INDEX if exists idx_transactions_category_date;
+
+-- Drop the tables
+DROP TABLE if exists budget_alerts;
+DROP TABLE if exists budgets;
+
+-- Drop the sequences
+DROP SEQUENCE seq_budget_alerts_id;
+DROP SEQUENCE seq_budgets_id;
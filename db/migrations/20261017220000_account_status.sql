@@ -0,0 +1,43 @@
+-- migrate:up
+
+-- Create the sequence
+CREATE SEQUENCE seq_account_status_changes_id START WITH 1;
+
+-- Accounts are closed instead of deleted
+ALTER TABLE accounts
+    ADD COLUMN status            varchar(20)  NOT NULL DEFAULT 'active',
+    ADD COLUMN status_reason     varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN status_changed_at TIMESTAMP WITH TIME ZONE;
+
+-- Create the history of the transitions
+CREATE TABLE account_status_changes
+(
+    id          integer                  NOT NULL DEFAULT nextval('seq_account_status_changes_id'),
+    account_id  integer                  NOT NULL,
+    from_status varchar(20)              NOT NULL,
+    to_status   varchar(20)              NOT NULL,
+    reason      varchar(255)             NOT NULL,
+    actor       varchar(255)             NOT NULL DEFAULT '',
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+-- Alter table for foreign keys
+ALTER TABLE account_status_changes
+    ADD CONSTRAINT fk_account_status_change_account FOREIGN KEY (account_id) REFERENCES accounts (account);
+
+CREATE INDEX idx_account_status_changes_account ON account_status_changes (account_id, created_at);
+
+-- migrate:down
+
+-- Drop the history table
+DROP TABLE if exists account_status_changes;
+
+-- Drop the status of the accounts
+ALTER TABLE accounts
+    DROP COLUMN status_changed_at,
+    DROP COLUMN status_reason,
+    DROP COLUMN status;
+
+-- Drop the sequence
+DROP SEQUENCE seq_account_status_changes_id;
@@ -0,0 +1,47 @@
+-- migrate:up
+
+-- Create the sequence
+CREATE SEQUENCE seq_audit_entries_id START WITH 1;
+
+-- Create the audit log
+CREATE TABLE audit_entries
+(
+    id         integer                  NOT NULL DEFAULT nextval('seq_audit_entries_id'),
+    actor      varchar(255)             NOT NULL DEFAULT '',
+    action     varchar(50)              NOT NULL,
+    entity     varchar(50)              NOT NULL,
+    entity_id  varchar(255)             NOT NULL DEFAULT '',
+    before     jsonb,
+    after      jsonb,
+    request_id varchar(64)              NOT NULL DEFAULT '',
+    client_ip  varchar(45)              NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+-- The audit log is append-only
+CREATE FUNCTION reject_audit_entry_change() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit entries are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_entries_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE
+    ON audit_entries
+    FOR EACH STATEMENT
+EXECUTE FUNCTION reject_audit_entry_change();
+
+-- Entries are searched by entity and by actor
+CREATE INDEX idx_audit_entries_entity ON audit_entries (entity, entity_id);
+CREATE INDEX idx_audit_entries_actor ON audit_entries (actor);
+
+-- migrate:down
+
+-- Drop the audit log
+DROP TABLE if exists audit_entries;
+DROP FUNCTION if exists reject_audit_entry_change();
+
+-- Drop the sequence
+DROP SEQUENCE seq_audit_entries_id;
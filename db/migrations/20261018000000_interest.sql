@@ -0,0 +1,64 @@
+-- migrate:up
+
+-- Create the sequences
+CREATE SEQUENCE seq_interest_accruals_id START WITH 1;
+
+-- Create the tables, accounts without a product earn no interest
+CREATE TABLE interest_products
+(
+    account_id      integer                  NOT NULL,
+    annual_rate     DECIMAL(7, 4)            NOT NULL CHECK (annual_rate >= 0),
+    day_count       varchar(7)               NOT NULL,
+    compounding     varchar(7)               NOT NULL,
+    posting         varchar(9)               NOT NULL,
+    start_date      date                     NOT NULL,
+    accrued_through date,
+    accrued         DECIMAL(20, 10)          NOT NULL DEFAULT 0,
+    compounded      DECIMAL(20, 10)          NOT NULL DEFAULT 0,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (account_id)
+);
+
+CREATE TABLE interest_accruals
+(
+    id             integer                  NOT NULL DEFAULT nextval('seq_interest_accruals_id'),
+    account_id     integer                  NOT NULL,
+    date           date                     NOT NULL,
+    balance        DECIMAL(10, 2)           NOT NULL,
+    base           DECIMAL(20, 10)          NOT NULL,
+    annual_rate    DECIMAL(7, 4)            NOT NULL,
+    day_count      varchar(7)               NOT NULL,
+    fraction       DECIMAL(20, 10)          NOT NULL,
+    interest       DECIMAL(20, 10)          NOT NULL,
+    accrued        DECIMAL(20, 10)          NOT NULL,
+    posted         DECIMAL(10, 2),
+    transaction_id integer,
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (id),
+    -- A day is accrued at most once
+    UNIQUE (account_id, date)
+);
+
+-- Alter table for foreign keys
+ALTER TABLE interest_products
+    ADD CONSTRAINT fk_interest_product_account FOREIGN KEY (account_id) REFERENCES accounts (account);
+ALTER TABLE interest_accruals
+    ADD CONSTRAINT fk_interest_accrual_account FOREIGN KEY (account_id) REFERENCES accounts (account);
+ALTER TABLE interest_accruals
+    ADD CONSTRAINT fk_interest_accrual_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id);
+
+-- The end-of-day balances sum the postings by date
+CREATE INDEX idx_journal_entries_date ON journal_entries (date);
+
+-- migrate:down
+
+-- Drop the index
+DROP INDEX if exists idx_journal_entries_date;
+
+-- Drop the tables
+DROP TABLE if exists interest_accruals;
+DROP TABLE if exists interest_products;
+
+-- Drop the sequences
+DROP SEQUENCE if exists seq_interest_accruals_id;
@@ -0,0 +1,56 @@
+-- migrate:up
+
+-- Create the sequences
+CREATE SEQUENCE seq_fee_schedules_id START WITH 1;
+
+-- Create the tables, accounts without a fee schedule are not charged
+CREATE TABLE fee_schedules
+(
+    id                   integer                  NOT NULL DEFAULT nextval('seq_fee_schedules_id'),
+    name                 varchar(255)             NOT NULL,
+    monthly_fee          DECIMAL(10, 2) CHECK (monthly_fee > 0),
+    transaction_fee      DECIMAL(10, 2) CHECK (transaction_fee > 0),
+    percentage_fee       DECIMAL(7, 4) CHECK (percentage_fee > 0 AND percentage_fee <= 100),
+    percentage_threshold DECIMAL(10, 2)           NOT NULL DEFAULT 0,
+    waiver_balance       DECIMAL(10, 2),
+    created_at           TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at           TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE account_fees
+(
+    account_id      integer                  NOT NULL,
+    fee_schedule_id integer                  NOT NULL,
+    next_charge     date                     NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (account_id)
+);
+
+-- Fee transactions reference the transaction that triggered them
+ALTER TABLE transactions
+    ADD COLUMN fee_of_id integer;
+
+-- Alter table for foreign keys
+ALTER TABLE account_fees
+    ADD CONSTRAINT fk_account_fee_account FOREIGN KEY (account_id) REFERENCES accounts (account);
+ALTER TABLE account_fees
+    ADD CONSTRAINT fk_account_fee_schedule FOREIGN KEY (fee_schedule_id) REFERENCES fee_schedules (id);
+ALTER TABLE transactions
+    ADD CONSTRAINT fk_transaction_fee_of FOREIGN KEY (fee_of_id) REFERENCES transactions (id);
+
+CREATE INDEX idx_transactions_fee_of_id ON transactions (fee_of_id);
+
+-- migrate:down
+
+-- Drop the fee column
+ALTER TABLE transactions
+    DROP COLUMN fee_of_id;
+
+-- Drop the tables
+DROP TABLE if exists account_fees;
+DROP TABLE if exists fee_schedules;
+
+-- Drop the sequences
+DROP SEQUENCE if exists seq_fee_schedules_id;
@@ -0,0 +1,46 @@
+-- migrate:up
+
+-- Create the tables
+CREATE TABLE periods
+(
+    date        date                     NOT NULL,
+    status      varchar(6)               NOT NULL,
+    closed_at   TIMESTAMP WITH TIME ZONE,
+    reopened_at TIMESTAMP WITH TIME ZONE,
+    reopened_by varchar(255)             NOT NULL DEFAULT '',
+    reason      varchar(255)             NOT NULL DEFAULT '',
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (date)
+);
+
+CREATE TABLE balance_snapshots
+(
+    account_id        integer                  NOT NULL,
+    date              date                     NOT NULL,
+    opening_balance   DECIMAL(10, 2)           NOT NULL,
+    closing_balance   DECIMAL(10, 2)           NOT NULL,
+    total_debits      DECIMAL(10, 2)           NOT NULL,
+    total_credits     DECIMAL(10, 2)           NOT NULL,
+    debit_count       integer                  NOT NULL,
+    credit_count      integer                  NOT NULL,
+    transaction_count integer                  NOT NULL,
+    created_at        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (account_id, date)
+);
+
+-- Alter table for foreign keys
+ALTER TABLE balance_snapshots
+    ADD CONSTRAINT fk_balance_snapshot_account FOREIGN KEY (account_id) REFERENCES accounts (account);
+
+-- The closing job sums the transactions of a day
+CREATE INDEX idx_transactions_date ON transactions (date);
+
+-- migrate:down
+
+-- Drop the index
+DROP INDEX if exists idx_transactions_date;
+
+-- Drop the tables
+DROP TABLE if exists balance_snapshots;
+DROP TABLE if exists periods;
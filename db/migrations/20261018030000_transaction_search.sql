@@ -0,0 +1,10 @@
+-- migrate:up
+
+-- The description search matches any part of the text
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX idx_transactions_description_trgm ON transactions USING gin (description gin_trgm_ops);
+
+-- migrate:down
+
+-- Drop the index
+DROP INDEX if exists idx_transactions_description_trgm;
@@ -0,0 +1,34 @@
+-- migrate:up
+
+-- Create the sequence
+CREATE SEQUENCE seq_transaction_splits_id START WITH 1;
+
+-- Create the table of the lines a transaction is split into
+CREATE TABLE transaction_splits
+(
+    id             integer                  NOT NULL DEFAULT nextval('seq_transaction_splits_id'),
+    transaction_id integer                  NOT NULL,
+    amount         DECIMAL(10, 2)           NOT NULL,
+    category       varchar(50)              NOT NULL,
+    note           varchar(255)             NOT NULL DEFAULT '',
+    created_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    PRIMARY KEY (id)
+);
+
+-- Alter table for foreign keys
+ALTER TABLE transaction_splits
+    ADD CONSTRAINT fk_transaction_split_transaction FOREIGN KEY (transaction_id) REFERENCES transactions (id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_transaction_split_category FOREIGN KEY (category) REFERENCES categories (code);
+
+-- The splits are loaded with their transaction and reported by category
+CREATE INDEX idx_transaction_splits_transaction_id ON transaction_splits (transaction_id);
+CREATE INDEX idx_transaction_splits_category ON transaction_splits (category);
+
+-- migrate:down
+
+-- Drop the transaction splits table
+DROP TABLE if exists transaction_splits;
+
+-- Drop the sequence
+DROP SEQUENCE seq_transaction_splits_id;
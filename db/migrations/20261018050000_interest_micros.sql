@@ -0,0 +1,21 @@
+-- migrate:up
+
+-- The interest is accrued in micro-cents
+ALTER TABLE interest_products
+    ALTER COLUMN accrued TYPE DECIMAL(20, 8),
+    ALTER COLUMN compounded TYPE DECIMAL(20, 8);
+ALTER TABLE interest_accruals
+    ALTER COLUMN base TYPE DECIMAL(20, 8),
+    ALTER COLUMN interest TYPE DECIMAL(20, 8),
+    ALTER COLUMN accrued TYPE DECIMAL(20, 8);
+
+-- migrate:down
+
+-- Restore the previous precision
+ALTER TABLE interest_products
+    ALTER COLUMN accrued TYPE DECIMAL(20, 10),
+    ALTER COLUMN compounded TYPE DECIMAL(20, 10);
+ALTER TABLE interest_accruals
+    ALTER COLUMN base TYPE DECIMAL(20, 10),
+    ALTER COLUMN interest TYPE DECIMAL(20, 10),
+    ALTER COLUMN accrued TYPE DECIMAL(20, 10);
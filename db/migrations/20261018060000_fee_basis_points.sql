@@ -0,0 +1,21 @@
+-- migrate:up
+
+-- The percentage fees are stored in basis points
+ALTER TABLE fee_schedules
+    ADD COLUMN percentage_fee_bps integer CHECK (percentage_fee_bps > 0 AND percentage_fee_bps <= 10000);
+UPDATE fee_schedules
+SET percentage_fee_bps = GREATEST(ROUND(percentage_fee * 100), 1)
+WHERE percentage_fee IS NOT NULL;
+ALTER TABLE fee_schedules
+    DROP COLUMN percentage_fee;
+
+-- migrate:down
+
+-- Restore the percentage column
+ALTER TABLE fee_schedules
+    ADD COLUMN percentage_fee DECIMAL(7, 4) CHECK (percentage_fee > 0 AND percentage_fee <= 100);
+UPDATE fee_schedules
+SET percentage_fee = percentage_fee_bps / 100.0
+WHERE percentage_fee_bps IS NOT NULL;
+ALTER TABLE fee_schedules
+    DROP COLUMN percentage_fee_bps;
@@ -0,0 +1,16 @@
+-- migrate:up
+
+-- Fee transactions are marked, the fees category alone does not tell
+-- them apart from the transactions a client or a rule put in it
+ALTER TABLE transactions
+    ADD COLUMN fee boolean NOT NULL DEFAULT false;
+UPDATE transactions
+SET fee = true
+WHERE fee_of_id IS NOT NULL
+   OR (category = 'fees' AND rule_id IS NULL AND description = 'monthly maintenance fee');
+
+-- migrate:down
+
+-- Drop the marker
+ALTER TABLE transactions
+    DROP COLUMN fee;
@@ -0,0 +1,2 @@
+// Package zenwallet is a RESTful API for account management.
+package zenwallet
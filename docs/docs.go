@@ -45,38 +45,59 @@ const docTemplate = `{
                         "JwtAuth": []
                     }
                 ],
-                "description": "Get a list of all accounts with optional pagination",
+                "description": "Get a page of accounts ordered by account number. Pass the next_cursor of a page as cursor to get the next one.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Accounts"
                 ],
-                "summary": "Get all accounts with pagination",
+                "summary": "Get all accounts with cursor pagination",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Offset for pagination",
-                        "name": "offset",
+                        "enum": [
+                            "active",
+                            "frozen",
+                            "dormant",
+                            "closed"
+                        ],
+                        "type": "string",
+                        "description": "Account status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor with the previous page",
+                        "name": "cursor",
                         "in": "query"
                     },
                     {
                         "type": "integer",
                         "default": 10,
-                        "description": "Limit for pagination",
+                        "description": "Page size, at most 100",
                         "name": "limit",
                         "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include the number of accounts matching the filters",
+                        "name": "total",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved list of accounts",
+                        "description": "Successfully retrieved page of accounts",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Account"
-                            }
+                            "$ref": "#/definitions/pagination.Page-models_Account"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
@@ -131,52 +152,139 @@ const docTemplate = `{
                 }
             }
         },
-        "/accounts/{id}": {
+        "/accounts/{account}/transactions": {
             "get": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Get details of an account by its ID",
+                "description": "Get a page of the transactions of an account, with the balance of the account right after each of them. Accepts the filters and sort orders of the transactions list, the account being the one of the path.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Accounts"
                 ],
-                "summary": "Find an account by ID",
+                "summary": "Get the transactions of an account with cursor pagination",
                 "parameters": [
                     {
-                        "type": "string",
+                        "type": "integer",
                         "description": "Account ID",
-                        "name": "id",
+                        "name": "account",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day as YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day as YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum amount, signed",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum amount, signed",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "debit",
+                            "credit"
+                        ],
+                        "type": "string",
+                        "description": "Debits or credits only",
+                        "name": "sign",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category code",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text contained in the description, case insensitive",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "date",
+                            "-date",
+                            "amount",
+                            "-amount"
+                        ],
+                        "type": "string",
+                        "default": "id",
+                        "description": "Sort order, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor with the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include the number of transactions matching the filters",
+                        "name": "total",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved account",
+                        "description": "Successfully retrieved page of transactions",
                         "schema": {
-                            "$ref": "#/definitions/models.Account"
+                            "$ref": "#/definitions/pagination.Page-models_AccountTransaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "404": {
-                        "description": "Account not found",
+                        "description": "account not found",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Update the account details for the given ID",
+                "description": "Create a new transaction on the account of the path, as POST /transactions does",
                 "consumes": [
                     "application/json"
                 ],
@@ -186,30 +294,36 @@ const docTemplate = `{
                 "tags": [
                     "Accounts"
                 ],
-                "summary": "Update an account by ID",
+                "summary": "Create a transaction on an account",
                 "parameters": [
                     {
                         "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
                         "description": "Account ID",
-                        "name": "id",
+                        "name": "account",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Update account object",
+                        "description": "Create transaction object",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateAccount"
+                            "$ref": "#/definitions/models.CreateAccountTransaction"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Successfully updated account",
+                    "201": {
+                        "description": "Successfully created transaction",
                         "schema": {
-                            "$ref": "#/definitions/models.Account"
+                            "$ref": "#/definitions/models.Transaction"
                         }
                     },
                     "400": {
@@ -218,70 +332,118 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "404": {
-                        "description": "account not found",
+                        "description": "account or category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "account status or a closed period rejects the posting or request with the same Idempotency-Key in progress",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "rejected by the account policy or Idempotency-Key reused with a different request",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/accounts/{account}/transactions/summary": {
+            "get": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Delete the account with the given ID",
+                "description": "Count and total the debits and credits of the account matching the filters of the transactions list, with the balance of the account before from and at the end of to",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Accounts"
                 ],
-                "summary": "Delete an account by ID",
+                "summary": "Summarize the transactions of an account",
                 "parameters": [
                     {
-                        "type": "string",
+                        "type": "integer",
                         "description": "Account ID",
-                        "name": "id",
+                        "name": "account",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day as YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day as YYYY-MM-DD, today by default",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum amount, signed",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum amount, signed",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "debit",
+                            "credit"
+                        ],
+                        "type": "string",
+                        "description": "Debits or credits only",
+                        "name": "sign",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category code",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text contained in the description, case insensitive",
+                        "name": "q",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Successfully deleted account",
+                    "200": {
+                        "description": "Successfully summarized transactions",
                         "schema": {
-                            "$ref": "#/definitions/models.Account"
+                            "$ref": "#/definitions/models.AccountTransactionSummary"
                         }
                     },
-                    "404": {
-                        "description": "account not found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "string"
                         }
-                    }
-                }
-            }
-        },
-        "/emails/{emails}": {
-            "post": {
-                "security": [
-                    {
-                        "JwtAuth": []
-                    }
-                ],
-                "consumes": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Emails"
-                ],
-                "summary": "Send account statement by Email",
-                "responses": {
-                    "200": {
-                        "description": "Email sent successfully",
+                    },
+                    "404": {
+                        "description": "account not found",
                         "schema": {
                             "type": "string"
                         }
@@ -289,60 +451,58 @@ const docTemplate = `{
                 }
             }
         },
-        "/files": {
+        "/accounts/{id}": {
             "get": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Get a list of all files with optional pagination",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get details of an account by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Files"
+                    "Accounts"
                 ],
-                "summary": "Get all files with pagination",
+                "summary": "Find an account by ID",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Offset for pagination",
-                        "name": "offset",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Limit for pagination",
-                        "name": "limit",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved list of files",
+                        "description": "Successfully retrieved account, its version is returned in the ETag header",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.File"
+                            "$ref": "#/definitions/models.Account"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the account"
                             }
                         }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
-            }
-        },
-        "/files/process": {
-            "post": {
+            },
+            "put": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
+                "description": "Update the account details for the given ID. The If-Match header must hold the ETag returned by the last read.",
                 "consumes": [
                     "application/json"
                 ],
@@ -350,25 +510,45 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Files"
+                    "Accounts"
                 ],
-                "summary": "Process a file",
+                "summary": "Update an account by ID",
                 "parameters": [
                     {
-                        "description": "Upload file",
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the account",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Update account object",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.ProcessFile"
+                            "$ref": "#/definitions/models.UpdateAccount"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully processed file",
+                    "200": {
+                        "description": "Successfully updated account",
                         "schema": {
-                            "$ref": "#/definitions/models.File"
+                            "$ref": "#/definitions/models.Account"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the account"
+                            }
                         }
                     },
                     "400": {
@@ -377,58 +557,90 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "account not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "account was modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header required",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            }
-        },
-        "/files/upload": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Close the account with the given ID, which must have a zero balance and no pending holds. Accounts are never deleted, a closed account rejects every posting. The If-Match header must hold the ETag returned by the last read.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Files"
+                    "Accounts"
                 ],
-                "summary": "Upload a new file",
+                "summary": "Close an account by ID",
                 "parameters": [
                     {
-                        "description": "Upload file",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UploadFile"
-                        }
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "closed on request",
+                        "description": "Reason of the closure",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the account",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully created file",
+                    "202": {
+                        "description": "Successfully closed account",
                         "schema": {
-                            "$ref": "#/definitions/models.File"
+                            "$ref": "#/definitions/models.Account"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "account not found",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "409": {
+                        "description": "account already closed or balance not zero",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "412": {
+                        "description": "account was modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "428": {
+                        "description": "If-Match header required",
                         "schema": {
                             "type": "string"
                         }
@@ -436,42 +648,51 @@ const docTemplate = `{
                 }
             }
         },
-        "/files/{id}": {
+        "/accounts/{id}/balance": {
             "get": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Get details of a file by its ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the balance of the account at the end of a day, computed from the transactions dated on or before it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Files"
+                    "Accounts"
                 ],
-                "summary": "Find file by ID",
+                "summary": "Find the balance of an account at a date",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "File ID",
+                        "description": "Account ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Day as YYYY-MM-DD, today by default",
+                        "name": "as_of",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved file",
+                        "description": "Successfully retrieved balance",
                         "schema": {
-                            "$ref": "#/definitions/models.File"
+                            "$ref": "#/definitions/models.AccountBalance"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid as_of format",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "404": {
-                        "description": "File not found",
+                        "description": "account not found",
                         "schema": {
                             "type": "string"
                         }
@@ -479,40 +700,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/login": {
-            "post": {
+        "/accounts/{id}/balance/daily": {
+            "get": {
                 "security": [
                     {
-                        "ApiKeyAuth": []
+                        "JwtAuth": []
                     }
                 ],
-                "description": "Authenticates a user using username and password, returns a JWT token if successful",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the balance of the account at the end of every day of a range of at most 366 days, the last 30 days by default",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Accounts"
                 ],
-                "summary": "Authenticate a user",
+                "summary": "Get the daily balances of an account",
                 "parameters": [
                     {
-                        "description": "User login object",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.LoginUser"
-                        }
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day as YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day as YYYY-MM-DD, today by default",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "JWT Token",
+                        "description": "Successfully retrieved balance series",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.BalanceSeries"
                         }
                     },
                     "400": {
@@ -521,14 +749,8 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "account not found",
                         "schema": {
                             "type": "string"
                         }
@@ -536,143 +758,133 @@ const docTemplate = `{
                 }
             }
         },
-        "/register": {
-            "post": {
+        "/accounts/{id}/fees": {
+            "get": {
                 "security": [
                     {
-                        "ApiKeyAuth": []
+                        "JwtAuth": []
                     }
                 ],
-                "description": "Registers a new user with the given username and password",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get the fee schedule attached to the account and the end of the next month its monthly fee is charged for",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Accounts"
                 ],
-                "summary": "Register a new user",
+                "summary": "Find the fee schedule attached to an account",
                 "parameters": [
                     {
-                        "description": "User registration object",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.LoginUser"
-                        }
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully registered",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                        "description": "Successfully retrieved account fees",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.AccountFee"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "account has no fee schedule",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            }
-        },
-        "/transactions": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Get a list of all transactions with optional pagination",
+                "description": "Attach a fee schedule to the account, replacing the previous one. The monthly fee is first charged at the end of the current month.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Accounts"
                 ],
-                "summary": "Get all transactions with pagination",
+                "summary": "Attach a fee schedule to an account",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "Offset for pagination",
-                        "name": "offset",
-                        "in": "query"
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Limit for pagination",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Account fee object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateAccountFee"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved list of transactions",
+                        "description": "Successfully attached fee schedule",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Transaction"
-                            }
+                            "$ref": "#/definitions/models.AccountFee"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "account or fee schedule not found",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Create a new transaction with the given input data",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Stop charging fees to the account. The monthly fee of the current month is not charged.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Accounts"
                 ],
-                "summary": "Create a new transaction",
+                "summary": "Detach the fee schedule of an account",
                 "parameters": [
                     {
-                        "description": "Create transaction object",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateTransaction"
-                        }
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Successfully created transaction",
-                        "schema": {
-                            "$ref": "#/definitions/models.Transaction"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "202": {
+                        "description": "Successfully detached fee schedule",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.AccountFee"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "404": {
+                        "description": "account has no fee schedule",
                         "schema": {
                             "type": "string"
                         }
@@ -680,25 +892,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions/{id}": {
+        "/accounts/{id}/interest": {
             "get": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Get details of a transaction by its ID",
+                "description": "Get the interest terms of the account and the interest accrued and not posted yet",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Accounts"
                 ],
-                "summary": "Find a transaction by ID",
+                "summary": "Find the interest product of an account",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Transaction ID",
+                        "description": "Account ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -706,13 +918,13 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully retrieved transaction",
+                        "description": "Successfully retrieved interest product",
                         "schema": {
-                            "$ref": "#/definitions/models.Transaction"
+                            "$ref": "#/definitions/models.InterestProduct"
                         }
                     },
                     "404": {
-                        "description": "Transaction not found",
+                        "description": "account not found or account has no interest product",
                         "schema": {
                             "type": "string"
                         }
@@ -725,7 +937,7 @@ const docTemplate = `{
                         "JwtAuth": []
                     }
                 ],
-                "description": "Update the transaction details for the given ID",
+                "description": "Set the annual rate (a percentage), day count convention, compounding and posting frequency of the account. A new product accrues from today, changed terms apply from the next day accrued and keep the interest accrued so far.",
                 "consumes": [
                     "application/json"
                 ],
@@ -733,32 +945,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Accounts"
                 ],
-                "summary": "Update a transaction by ID",
+                "summary": "Update the interest product of an account",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Transaction ID",
+                        "description": "Account ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Update transaction object",
+                        "description": "Interest product object",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateTransaction"
+                            "$ref": "#/definitions/models.UpdateInterestProduct"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successfully updated transaction",
+                        "description": "Successfully updated interest product",
                         "schema": {
-                            "$ref": "#/definitions/models.Transaction"
+                            "$ref": "#/definitions/models.InterestProduct"
                         }
                     },
                     "400": {
@@ -768,156 +980,5012 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "transaction not found",
+                        "description": "account not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "account is closed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{id}/interest/accruals": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get the daily interest accruals of the account, oldest first, with the balance, rate and day fraction each one was calculated with and the interest posted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Get the interest accrual history of an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Limit for pagination",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved interest accruals",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.InterestAccrual"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "account not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{id}/policy": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get the overdraft limit, debit limits and allowed currencies of the account. Accounts without a policy get the default one, which forbids overdrafts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Find the policy of an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved policy",
+                        "schema": {
+                            "$ref": "#/definitions/models.AccountPolicy"
+                        }
+                    },
+                    "404": {
+                        "description": "account not found",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
             },
-            "delete": {
+            "put": {
                 "security": [
                     {
                         "JwtAuth": []
                     }
                 ],
-                "description": "Delete the transaction with the given ID",
+                "description": "Replace the policy of the account. Limits left empty are not enforced and an empty list of currencies accepts any currency.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Transactions"
+                    "Accounts"
                 ],
-                "summary": "Delete a transaction by ID",
+                "summary": "Update the policy of an account",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Transaction ID",
+                        "description": "Account ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Account policy object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateAccountPolicy"
+                        }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Successfully deleted transaction",
+                    "200": {
+                        "description": "Successfully updated policy",
                         "schema": {
-                            "$ref": "#/definitions/models.Transaction"
+                            "$ref": "#/definitions/models.AccountPolicy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "404": {
-                        "description": "transaction not found",
+                        "description": "account not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{id}/snapshots": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get the opening and closing balance and the debit and credit totals of the account for every closed day of a range, the last 30 days by default",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Get the balance snapshots of an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day as YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day as YYYY-MM-DD, today by default",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved snapshots",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BalanceSnapshot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "account not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{id}/status": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Move the account to another status. Active accounts can be frozen, marked dormant or closed; frozen and dormant accounts can be reactivated or closed, dormant ones also frozen. Frozen and dormant accounts reject debits, closed accounts reject every posting and are final.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Change the status of an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Status and reason",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateAccountStatus"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully changed status",
+                        "schema": {
+                            "$ref": "#/definitions/models.Account"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "New version of the account"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "account not found",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "409": {
+                        "description": "transition not allowed or balance not zero",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{id}/status/history": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
                     }
+                ],
+                "description": "Get every status transition of the account, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Accounts"
+                ],
+                "summary": "Get the status history of an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved status history",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AccountStatusChange"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "account not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/audit": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get the writes made through the API, newest first, optionally restricted to an entity, an actor, an action and a time range",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Search the audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entity type, e.g. account or transaction",
+                        "name": "entity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Entity ID",
+                        "name": "entity_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Username of the actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Action, e.g. create, update or delete",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Oldest entry as RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Newest entry as RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Limit for pagination",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Audit entries",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AuditEntry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reconciliation": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Recompute the balance of every account from its transactions and report the accounts whose stored balance differs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Reconcile account balances",
+                "responses": {
+                    "200": {
+                        "description": "Discrepancy report",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReconciliationReport"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Same as the reconciliation report, but every divergent account gets an adjusting journal entry so its balance matches its transactions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Reconcile and fix account balances",
+                "responses": {
+                    "200": {
+                        "description": "Discrepancy report with the fixed accounts",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReconciliationReport"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get a list of budgets, optionally restricted to an account or a client",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budgets"
+                ],
+                "summary": "Get all budgets with pagination",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account number",
+                        "name": "account",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client name",
+                        "name": "client",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit for pagination",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved list of budgets",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Budget"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Create a monthly budget for the debits of a category, on an account or on every account of a client. Alerts are emailed when the spending reaches each threshold, 80% and 100% of the limit by default.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budgets"
+                ],
+                "summary": "Create a budget",
+                "parameters": [
+                    {
+                        "description": "Create budget object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateBudget"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created budget",
+                        "schema": {
+                            "$ref": "#/definitions/models.Budget"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "account, client or category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get details of a budget",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budgets"
+                ],
+                "summary": "Find a budget by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved budget",
+                        "schema": {
+                            "$ref": "#/definitions/models.Budget"
+                        }
+                    },
+                    "404": {
+                        "description": "budget not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Update the name, limit and thresholds of a budget. Thresholds already alerted this month are not alerted again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budgets"
+                ],
+                "summary": "Update a budget by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update budget object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateBudget"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated budget",
+                        "schema": {
+                            "$ref": "#/definitions/models.Budget"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "budget not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Delete a budget and the record of its alerts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budgets"
+                ],
+                "summary": "Delete a budget by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Successfully deleted budget",
+                        "schema": {
+                            "$ref": "#/definitions/models.Budget"
+                        }
+                    },
+                    "404": {
+                        "description": "budget not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/budgets/{id}/progress": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get the amount spent and remaining of a budget during a month, the thresholds reached and the alerts sent",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Budgets"
+                ],
+                "summary": "Get the consumption of a budget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Month as YYYY-MM, the current month by default",
+                        "name": "month",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved budget progress",
+                        "schema": {
+                            "$ref": "#/definitions/models.BudgetProgress"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid month format",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "budget not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get the catalog of transaction categories",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Get all categories",
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved list of categories",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Category"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Add a category to the catalog, its code is what transactions reference",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Create a new category",
+                "parameters": [
+                    {
+                        "description": "Create category object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateCategory"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created category",
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "category already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/report": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get the debits and credits of each category, optionally restricted to an account and a date range. The lines of split transactions count in their own categories, the uncategorized transactions under a null category.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Total the transactions by category",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "account",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day as YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day as YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Totals by category",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CategoryTotal"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/categories/{code}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get details of a category",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Find a category by code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved category",
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
+                    },
+                    "404": {
+                        "description": "category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Rename a category, its code cannot change",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Update a category by code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update category object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateCategory"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated category",
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Remove a category from the catalog. The fees category and the categories used by transactions or their splits, by rules or by budgets cannot be deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Categories"
+                ],
+                "summary": "Delete a category by code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Successfully deleted category",
+                        "schema": {
+                            "$ref": "#/definitions/models.Category"
+                        }
+                    },
+                    "404": {
+                        "description": "category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "category is reserved or used by transactions, rules or budgets",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/emails/{emails}": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Emails"
+                ],
+                "summary": "Send account statement by Email",
+                "responses": {
+                    "200": {
+                        "description": "Email sent successfully",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/fees": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get a list of fee schedules",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fees"
+                ],
+                "summary": "Get all fee schedules with pagination",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit for pagination",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved list of fee schedules",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.FeeSchedule"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Create a fee schedule with a monthly maintenance fee, a fee per debit and a percentage fee, in basis points, on the debits above a threshold. Fees left empty are not charged, and none is charged while the balance is at least the waiver balance.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fees"
+                ],
+                "summary": "Create a fee schedule",
+                "parameters": [
+                    {
+                        "description": "Create fee schedule object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateFeeSchedule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created fee schedule",
+                        "schema": {
+                            "$ref": "#/definitions/models.FeeSchedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/fees/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get details of a fee schedule",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fees"
+                ],
+                "summary": "Find a fee schedule by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Fee schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved fee schedule",
+                        "schema": {
+                            "$ref": "#/definitions/models.FeeSchedule"
+                        }
+                    },
+                    "404": {
+                        "description": "fee schedule not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Replace the fees of a fee schedule. The new fees apply to the accounts it is attached to from the next charge on.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fees"
+                ],
+                "summary": "Update a fee schedule by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Fee schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update fee schedule object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateFeeSchedule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated fee schedule",
+                        "schema": {
+                            "$ref": "#/definitions/models.FeeSchedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "fee schedule not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Delete a fee schedule that is not attached to any account",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fees"
+                ],
+                "summary": "Delete a fee schedule by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Fee schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Successfully deleted fee schedule",
+                        "schema": {
+                            "$ref": "#/definitions/models.FeeSchedule"
+                        }
+                    },
+                    "404": {
+                        "description": "fee schedule not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "fee schedule is attached to accounts",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/files": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get a page of files, oldest first. Pass the next_cursor of a page as cursor to get the next one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Files"
+                ],
+                "summary": "Get all files with cursor pagination",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor with the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include the number of files",
+                        "name": "total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved page of files",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_File"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/process": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Files"
+                ],
+                "summary": "Process a file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Upload file",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ProcessFile"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully processed file",
+                        "schema": {
+                            "$ref": "#/definitions/models.File"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "account status or a closed period rejects a transaction, or request with the same Idempotency-Key in progress",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "a transaction was rejected by the account policy or Idempotency-Key reused with a different request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/upload": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Files"
+                ],
+                "summary": "Upload a new file",
+                "parameters": [
+                    {
+                        "description": "Upload file",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UploadFile"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created file",
+                        "schema": {
+                            "$ref": "#/definitions/models.File"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/files/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get details of a file by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Files"
+                ],
+                "summary": "Find file by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved file",
+                        "schema": {
+                            "$ref": "#/definitions/models.File"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/holds": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get a list of holds, optionally restricted to an account and a status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holds"
+                ],
+                "summary": "Get all holds with pagination",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account number",
+                        "name": "account",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "captured",
+                            "released",
+                            "expired"
+                        ],
+                        "type": "string",
+                        "description": "Hold status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit for pagination",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved list of holds",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Hold"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Reserve an amount of the account. The available balance decreases, the balance does not change until the hold is captured.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holds"
+                ],
+                "summary": "Authorize a hold on an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Create hold object, expires_at is RFC 3339 and defaults to 7 days",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateHold"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created hold",
+                        "schema": {
+                            "$ref": "#/definitions/models.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "account not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "account status rejects debits or request with the same Idempotency-Key in progress",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "rejected by the account policy or Idempotency-Key reused with a different request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/holds/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get details of an authorization hold",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holds"
+                ],
+                "summary": "Find a hold by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved hold",
+                        "schema": {
+                            "$ref": "#/definitions/models.Hold"
+                        }
+                    },
+                    "404": {
+                        "description": "hold not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/holds/{id}/capture": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Debit the account with the held amount, or with a smaller amount which releases the rest of the hold",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holds"
+                ],
+                "summary": "Capture a hold by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Capture object, amount defaults to the held amount and date to today",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CaptureHold"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created transaction",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "hold not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "hold is no longer pending, account status rejects debits or period closed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "capture amount exceeds the held amount",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/holds/{id}/release": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Cancel a pending hold and give its amount back to the available balance",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Holds"
+                ],
+                "summary": "Release a hold by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully released hold",
+                        "schema": {
+                            "$ref": "#/definitions/models.Hold"
+                        }
+                    },
+                    "404": {
+                        "description": "hold not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "hold is no longer pending",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Authenticates a user using username and password, returns a JWT token if successful",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Authenticate a user",
+                "parameters": [
+                    {
+                        "description": "User login object",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT Token",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/periods": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get the days closed by the closing job, newest first, optionally restricted to a date range. The days before the first closed day are closed too but have no record.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Periods"
+                ],
+                "summary": "Get the closed and reopened periods",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day as YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day as YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status, closed or open",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 31,
+                        "description": "Limit for pagination",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Periods",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Period"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/periods/{date}/close": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Lock a reopened day again and snapshot its balances, and those of the closed days following it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Periods"
+                ],
+                "summary": "Close a reopened period again",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Day as YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Closed period",
+                        "schema": {
+                            "$ref": "#/definitions/models.Period"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "period is not closed yet or already closed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/periods/{date}/reopen": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Unlock a closed day so transactions dated on it can be created and changed again. The reason and the user are recorded in the period and in the audit log.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Periods"
+                ],
+                "summary": "Reopen a closed period",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Day as YYYY-MM-DD",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the reopening",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReopenPeriod"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reopened period",
+                        "schema": {
+                            "$ref": "#/definitions/models.Period"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "period is not closed yet or already reopened",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Registers a new user with the given username and password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User registration object",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully registered",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rules": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get every categorization rule in the order they are evaluated",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "Get all rules",
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved list of rules",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Rule"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Create a rule that categorizes and tags the transactions matching all of its conditions when they are created or imported. Rules are evaluated by ascending priority and the first match applies.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "Create a new rule",
+                "parameters": [
+                    {
+                        "description": "Create rule object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created rule",
+                        "schema": {
+                            "$ref": "#/definitions/models.Rule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rules/apply": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Evaluate the enabled rules against every transaction without an explicit category and store the new categories and tags",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "Apply the rules to the existing transactions",
+                "responses": {
+                    "200": {
+                        "description": "Number of updated transactions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rules/dry-run": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "List the existing transactions whose category or tags would change if the rule was saved and enabled, without changing anything. Transactions with an explicit category are never affected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "Preview the effect of a rule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the rule the candidate replaces",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Candidate rule object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Number of affected transactions and the first of them",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/rules/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get details of a categorization rule",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "Find a rule by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved rule",
+                        "schema": {
+                            "$ref": "#/definitions/models.Rule"
+                        }
+                    },
+                    "404": {
+                        "description": "rule not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Replace the conditions and actions of a rule. Transactions it already categorized keep their category until the rules are applied again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "Update a rule by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rule object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated rule",
+                        "schema": {
+                            "$ref": "#/definitions/models.Rule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "rule or category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Delete a rule. Transactions it categorized keep their category, which is then treated as explicit.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rules"
+                ],
+                "summary": "Delete a rule by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Successfully deleted rule",
+                        "schema": {
+                            "$ref": "#/definitions/models.Rule"
+                        }
+                    },
+                    "404": {
+                        "description": "rule not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get a list of schedules, optionally restricted to an account and a status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Get all schedules with pagination",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account number",
+                        "name": "account",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "paused",
+                            "cancelled",
+                            "completed"
+                        ],
+                        "type": "string",
+                        "description": "Schedule status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit for pagination",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved list of schedules",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Schedule"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Create a standing order that posts a transaction daily, weekly or monthly on day_of_month, until end_date or until count transactions were posted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Create a schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Create schedule object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSchedule"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created schedule",
+                        "schema": {
+                            "$ref": "#/definitions/models.Schedule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "account not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get details of a schedule and of all its runs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Find a schedule by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved schedule",
+                        "schema": {
+                            "$ref": "#/definitions/models.Schedule"
+                        }
+                    },
+                    "404": {
+                        "description": "schedule not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Stop a schedule for good, the transactions already posted are kept",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Cancel a schedule by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully cancelled schedule",
+                        "schema": {
+                            "$ref": "#/definitions/models.Schedule"
+                        }
+                    },
+                    "404": {
+                        "description": "schedule not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "schedule already finished",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}/pause": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Stop posting the occurrences of an active schedule until it is resumed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Pause a schedule by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully paused schedule",
+                        "schema": {
+                            "$ref": "#/definitions/models.Schedule"
+                        }
+                    },
+                    "404": {
+                        "description": "schedule not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "schedule is not active",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/schedules/{id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Reactivate a paused schedule. The occurrences missed while it was paused are not posted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Resume a schedule by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully resumed schedule",
+                        "schema": {
+                            "$ref": "#/definitions/models.Schedule"
+                        }
+                    },
+                    "404": {
+                        "description": "schedule not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "schedule is not paused",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get a page of transactions, optionally filtered by account, date range, amount range, sign, category and a text in the description, and sorted. Pass the next_cursor of a page as cursor to get the next one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Get all transactions with cursor pagination",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Account ID",
+                        "name": "account",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day as YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day as YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum amount, signed",
+                        "name": "min_amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum amount, signed",
+                        "name": "max_amount",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "debit",
+                            "credit"
+                        ],
+                        "type": "string",
+                        "description": "Debits or credits only",
+                        "name": "sign",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Category code",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text contained in the description, case insensitive",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "date",
+                            "-date",
+                            "amount",
+                            "-amount"
+                        ],
+                        "type": "string",
+                        "default": "id",
+                        "description": "Sort order, prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor with the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Include the number of transactions matching the filters",
+                        "name": "total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved page of transactions",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Create a new transaction with the given input data",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Create a new transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Create transaction object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTransaction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created transaction",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "account or category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "account status or a closed period rejects the posting or request with the same Idempotency-Key in progress",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "rejected by the account policy or Idempotency-Key reused with a different request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/bulk": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Validate and post up to 1000 transactions in order, each seeing the balances and limits left by the previous ones. In atomic mode, the default, either all of them are posted or none; in best_effort mode the valid ones are posted and the others skipped. The response holds a result per transaction, with its ID or why it failed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Create many transactions at once",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Transactions and mode",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkCreateTransactions"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Every transaction was created",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkTransactions"
+                        }
+                    },
+                    "207": {
+                        "description": "Best effort, some transactions failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkTransactions"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "request with the same Idempotency-Key in progress",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Atomic, some transactions failed and none was created",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkTransactions"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get details of a transaction by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Find a transaction by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved transaction, with its reversal chain if any",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Update the transaction details for the given ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Update a transaction by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update transaction object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateTransaction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated transaction",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "transaction, account or category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "transaction belongs to a transfer or a reversal, or account status or a closed period rejects the change",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "rejected by the account policy",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/reverse": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Post a compensating transaction that cancels the given one. Posted transactions are never deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Reverse a transaction by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created reversal transaction",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "404": {
+                        "description": "transaction not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "transaction already reversed or belongs to a transfer, or account is closed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions/{id}/splits": {
+            "put": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Replace the splits of a transaction. Each split has an amount with the sign of the transaction, a category and a note, and together they must sum to the amount of the transaction. An empty list removes the splits.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Split a transaction across categories",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Splits of the transaction",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateTransactionSplits"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully updated splits",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "transaction or category not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get a list of all transfers with optional pagination",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfers"
+                ],
+                "summary": "Get all transfers with pagination",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset for pagination",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Limit for pagination",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved list of transfers",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Transfer"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Debit the source account and credit the destination account atomically",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfers"
+                ],
+                "summary": "Transfer money between two accounts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Create transfer object",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTransfer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created transfer",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "account not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "account status rejects the transfer, period closed or request with the same Idempotency-Key in progress",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "rejected by the account policy or Idempotency-Key reused with a different request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Get details of a transfer and both of its transactions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfers"
+                ],
+                "summary": "Find a transfer by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successfully retrieved transfer",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transfer"
+                        }
+                    },
+                    "404": {
+                        "description": "Transfer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers/{id}/reverse": {
+            "post": {
+                "security": [
+                    {
+                        "JwtAuth": []
+                    }
+                ],
+                "description": "Move the money of the transfer back to its source account, with a reversal dated today",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Transfers"
+                ],
+                "summary": "Reverse a transfer by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Successfully created reversal transfer",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transfer"
+                        }
+                    },
+                    "404": {
+                        "description": "transfer not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "transfer already reversed, account status rejects the reversal or period closed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.Account": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "available_balance": {
+                    "type": "number"
+                },
+                "balance": {
+                    "type": "number"
+                },
+                "client": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "status_changed_at": {
+                    "type": "string"
+                },
+                "status_reason": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AccountBalance": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "as_of": {
+                    "type": "string"
+                },
+                "balance": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.AccountFee": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "fee_schedule_id": {
+                    "type": "integer"
+                },
+                "next_charge": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AccountPolicy": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "allowed_currencies": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "daily_debit_limit": {
+                    "type": "number"
+                },
+                "max_debit": {
+                    "type": "number"
+                },
+                "monthly_debit_limit": {
+                    "type": "number"
+                },
+                "overdraft_limit": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AccountStatusChange": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AccountTransaction": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "counterparty": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "external_reference": {
+                    "type": "string"
+                },
+                "fee": {
+                    "description": "Fee marks the fees charged by the system, the only transactions in the fees category",
+                    "type": "boolean"
+                },
+                "fee_of": {
+                    "type": "integer"
+                },
+                "fees": {
+                    "description": "Fees lists the fee transactions charged for this transaction when it was posted",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "reversal_chain": {
+                    "description": "ReversalChain lists the transactions of the reversal chain, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
+                },
+                "reversal_of": {
+                    "type": "integer"
+                },
+                "reversed_by": {
+                    "type": "integer"
+                },
+                "rule_id": {
+                    "description": "RuleID is the rule that assigned the category, if it was not set explicitly",
+                    "type": "integer"
+                },
+                "running_balance": {
+                    "type": "number"
+                },
+                "splits": {
+                    "description": "Splits lists the lines the amount is split into across categories, if any",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TransactionSplit"
+                    }
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "transfer_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AccountTransactionSummary": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "closing_balance": {
+                    "type": "number"
+                },
+                "credit_count": {
+                    "type": "integer"
+                },
+                "debit_count": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "net": {
+                    "type": "number"
+                },
+                "opening_balance": {
+                    "type": "number"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "total_credits": {
+                    "type": "number"
+                },
+                "total_debits": {
+                    "type": "number"
+                },
+                "transaction_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "client_ip": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "entity": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BalancePoint": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BalanceSeries": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "balances": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BalancePoint"
+                    }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BalanceSnapshot": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "closing_balance": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "credit_count": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "debit_count": {
+                    "type": "integer"
+                },
+                "opening_balance": {
+                    "type": "number"
+                },
+                "total_credits": {
+                    "type": "number"
+                },
+                "total_debits": {
+                    "type": "number"
+                },
+                "transaction_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Budget": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "client": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "limit": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "thresholds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BudgetAlert": {
+            "type": "object",
+            "properties": {
+                "budget_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "spent": {
+                    "type": "number"
+                },
+                "threshold": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BudgetProgress": {
+            "type": "object",
+            "properties": {
+                "alerts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BudgetAlert"
+                    }
+                },
+                "budget_id": {
+                    "type": "integer"
+                },
+                "crossed": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "limit": {
+                    "type": "number"
+                },
+                "percent": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "remaining": {
+                    "type": "number"
+                },
+                "spent": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.BulkCreateTransactions": {
+            "type": "object",
+            "required": [
+                "transactions"
+            ],
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "default": "atomic",
+                    "enum": [
+                        "atomic",
+                        "best_effort"
+                    ]
+                },
+                "transactions": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.CreateTransaction"
+                    }
+                }
+            }
+        },
+        "models.BulkTransactionResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "failed",
+                        "not_posted"
+                    ]
+                }
+            }
+        },
+        "models.BulkTransactions": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkTransactionResult"
+                    }
+                }
+            }
+        },
+        "models.CaptureHold": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Category": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CategoryTotal": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "net": {
+                    "type": "number"
+                },
+                "total_credits": {
+                    "type": "number"
+                },
+                "total_debits": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CreateAccount": {
+            "type": "object",
+            "required": [
+                "client",
+                "email"
+            ],
+            "properties": {
+                "client": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateAccountTransaction": {
+            "type": "object",
+            "required": [
+                "amount",
+                "date"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "counterparty": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "external_reference": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "splits": {
+                    "description": "Splits optionally splits the amount across categories",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreateTransactionSplit"
+                    }
+                }
+            }
+        },
+        "models.CreateBudget": {
+            "type": "object",
+            "required": [
+                "category",
+                "limit",
+                "name"
+            ],
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "client": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "thresholds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.CreateCategory": {
+            "type": "object",
+            "required": [
+                "code",
+                "name"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateFeeSchedule": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "monthly_fee": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "percentage_fee_bps": {
+                    "type": "integer"
+                },
+                "percentage_threshold": {
+                    "type": "number"
+                },
+                "transaction_fee": {
+                    "type": "number"
+                },
+                "waiver_balance": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CreateHold": {
+            "type": "object",
+            "required": [
+                "account",
+                "amount"
+            ],
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateRule": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "counterparty": {
+                    "type": "string"
+                },
+                "description_contains": {
+                    "type": "string"
+                },
+                "description_pattern": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "max_amount": {
+                    "type": "number"
+                },
+                "min_amount": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.CreateSchedule": {
+            "type": "object",
+            "required": [
+                "account",
+                "amount",
+                "frequency",
+                "start_date"
+            ],
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "day_of_month": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "frequency": {
+                    "type": "string",
+                    "enum": [
+                        "daily",
+                        "weekly",
+                        "monthly"
+                    ]
+                },
+                "start_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateTransaction": {
+            "type": "object",
+            "required": [
+                "account",
+                "amount",
+                "date"
+            ],
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "counterparty": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "external_reference": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "splits": {
+                    "description": "Splits optionally splits the amount across categories",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreateTransactionSplit"
+                    }
+                }
+            }
+        },
+        "models.CreateTransactionSplit": {
+            "type": "object",
+            "required": [
+                "amount",
+                "category"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateTransfer": {
+            "type": "object",
+            "required": [
+                "amount",
+                "date",
+                "from",
+                "to"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "to": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Discrepancy": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "expected": {
+                    "type": "number"
+                },
+                "first_divergent_transaction": {
+                    "type": "integer"
+                },
+                "fixed": {
+                    "type": "boolean"
+                },
+                "ledger": {
+                    "type": "number"
+                },
+                "stored": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.FeeSchedule": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "monthly_fee": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "percentage_fee_bps": {
+                    "type": "integer"
+                },
+                "percentage_threshold": {
+                    "type": "number"
+                },
+                "transaction_fee": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "waiver_balance": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.File": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "output": {
+                    "type": "string"
+                },
+                "processed": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Hold": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "captured": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.InterestAccrual": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "accrued": {
+                    "type": "number"
+                },
+                "annual_rate": {
+                    "type": "number"
+                },
+                "balance": {
+                    "type": "number"
+                },
+                "base": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "day_count": {
+                    "type": "string"
+                },
+                "fraction": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "interest": {
+                    "type": "number"
+                },
+                "posted": {
+                    "type": "number"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.InterestProduct": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "accrued": {
+                    "type": "number"
+                },
+                "accrued_through": {
+                    "type": "string"
+                },
+                "annual_rate": {
+                    "type": "number"
+                },
+                "compounded": {
+                    "type": "number"
+                },
+                "compounding": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "day_count": {
+                    "type": "string"
+                },
+                "posting": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoginUser": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Period": {
+            "type": "object",
+            "properties": {
+                "closed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reopened_at": {
+                    "type": "string"
+                },
+                "reopened_by": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProcessFile": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReconciliationReport": {
+            "type": "object",
+            "properties": {
+                "accounts_checked": {
+                    "type": "integer"
+                },
+                "discrepancies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Discrepancy"
+                    }
+                },
+                "fixed": {
+                    "type": "boolean"
+                },
+                "generated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReopenPeriod": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Rule": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "counterparty": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description_contains": {
+                    "type": "string"
+                },
+                "description_pattern": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_amount": {
+                    "type": "number"
+                },
+                "min_amount": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Schedule": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "day_of_month": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "frequency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "next_run": {
+                    "type": "string"
+                },
+                "occurrences": {
+                    "type": "integer"
+                },
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScheduleRun"
+                    }
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScheduleRun": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "schedule_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Account": {
+        },
+        "models.Transaction": {
             "type": "object",
             "properties": {
                 "account": {
                     "type": "integer"
                 },
-                "balance": {
+                "amount": {
                     "type": "number"
                 },
-                "client": {
+                "category": {
+                    "type": "string"
+                },
+                "counterparty": {
                     "type": "string"
                 },
                 "created_at": {
                     "type": "string"
                 },
-                "email": {
+                "currency": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
                     "type": "string"
                 },
+                "external_reference": {
+                    "type": "string"
+                },
+                "fee": {
+                    "description": "Fee marks the fees charged by the system, the only transactions in the fees category",
+                    "type": "boolean"
+                },
+                "fee_of": {
+                    "type": "integer"
+                },
+                "fees": {
+                    "description": "Fees lists the fee transactions charged for this transaction when it was posted",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
+                },
                 "id": {
                     "type": "integer"
                 },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "reversal_chain": {
+                    "description": "ReversalChain lists the transactions of the reversal chain, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
+                },
+                "reversal_of": {
+                    "type": "integer"
+                },
+                "reversed_by": {
+                    "type": "integer"
+                },
+                "rule_id": {
+                    "description": "RuleID is the rule that assigned the category, if it was not set explicitly",
+                    "type": "integer"
+                },
+                "splits": {
+                    "description": "Splits lists the lines the amount is split into across categories, if any",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TransactionSplit"
+                    }
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "transfer_id": {
+                    "type": "integer"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.CreateAccount": {
+        "models.TransactionSplit": {
             "type": "object",
-            "required": [
-                "client",
-                "email"
-            ],
             "properties": {
-                "client": {
+                "amount": {
+                    "type": "number"
+                },
+                "category": {
                     "type": "string"
                 },
-                "email": {
+                "created_at": {
                     "type": "string"
-                }
-            }
-        },
-        "models.CreateTransaction": {
-            "type": "object",
-            "required": [
-                "account",
-                "amount",
-                "date"
-            ],
-            "properties": {
-                "account": {
+                },
+                "id": {
                     "type": "integer"
                 },
-                "amount": {
-                    "type": "number"
+                "note": {
+                    "type": "string"
                 },
-                "date": {
+                "transaction_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.File": {
+        "models.Transfer": {
             "type": "object",
             "properties": {
+                "amount": {
+                    "type": "number"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "date": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "location": {
-                    "type": "string"
+                "reversal_of": {
+                    "type": "integer"
                 },
-                "name": {
+                "status": {
                     "type": "string"
                 },
-                "output": {
-                    "type": "string"
+                "to": {
+                    "type": "integer"
                 },
-                "processed": {
-                    "type": "boolean"
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
                 },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.LoginUser": {
+        "models.UpdateAccount": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UpdateAccountFee": {
             "type": "object",
             "required": [
-                "password",
-                "username"
+                "fee_schedule_id"
             ],
             "properties": {
-                "password": {
+                "fee_schedule_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.UpdateAccountPolicy": {
+            "type": "object",
+            "properties": {
+                "allowed_currencies": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "daily_debit_limit": {
+                    "type": "number"
+                },
+                "max_debit": {
+                    "type": "number"
+                },
+                "monthly_debit_limit": {
+                    "type": "number"
+                },
+                "overdraft_limit": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.UpdateAccountStatus": {
+            "type": "object",
+            "required": [
+                "reason",
+                "status"
+            ],
+            "properties": {
+                "reason": {
                     "type": "string"
                 },
-                "username": {
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "frozen",
+                        "dormant",
+                        "closed"
+                    ]
+                }
+            }
+        },
+        "models.UpdateBudget": {
+            "type": "object",
+            "required": [
+                "limit",
+                "name"
+            ],
+            "properties": {
+                "limit": {
+                    "type": "number"
+                },
+                "name": {
                     "type": "string"
+                },
+                "thresholds": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 }
             }
         },
-        "models.ProcessFile": {
+        "models.UpdateCategory": {
             "type": "object",
             "required": [
                 "name"
@@ -928,37 +5996,66 @@ const docTemplate = `{
                 }
             }
         },
-        "models.Transaction": {
+        "models.UpdateFeeSchedule": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
-                "account": {
-                    "type": "integer"
-                },
-                "amount": {
+                "monthly_fee": {
                     "type": "number"
                 },
-                "created_at": {
-                    "type": "string"
-                },
-                "date": {
+                "name": {
                     "type": "string"
                 },
-                "id": {
+                "percentage_fee_bps": {
                     "type": "integer"
                 },
-                "updated_at": {
-                    "type": "string"
+                "percentage_threshold": {
+                    "type": "number"
+                },
+                "transaction_fee": {
+                    "type": "number"
+                },
+                "waiver_balance": {
+                    "type": "number"
                 }
             }
         },
-        "models.UpdateAccount": {
+        "models.UpdateInterestProduct": {
             "type": "object",
+            "required": [
+                "compounding",
+                "day_count",
+                "posting"
+            ],
             "properties": {
-                "client": {
-                    "type": "string"
+                "annual_rate": {
+                    "type": "number"
                 },
-                "email": {
-                    "type": "string"
+                "compounding": {
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "daily",
+                        "monthly"
+                    ]
+                },
+                "day_count": {
+                    "type": "string",
+                    "enum": [
+                        "ACT/365",
+                        "ACT/360",
+                        "30/360"
+                    ]
+                },
+                "posting": {
+                    "type": "string",
+                    "enum": [
+                        "monthly",
+                        "quarterly",
+                        "annually"
+                    ]
                 }
             }
         },
@@ -976,8 +6073,44 @@ const docTemplate = `{
                 "amount": {
                     "type": "number"
                 },
+                "category": {
+                    "type": "string"
+                },
+                "counterparty": {
+                    "type": "string"
+                },
                 "date": {
                     "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "external_reference": {
+                    "type": "string"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "splits": {
+                    "description": "Splits replaces the splits of the transaction when set, an empty\nlist removes them",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreateTransactionSplit"
+                    }
+                }
+            }
+        },
+        "models.UpdateTransactionSplits": {
+            "type": "object",
+            "properties": {
+                "splits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreateTransactionSplit"
+                    }
                 }
             }
         },
@@ -991,6 +6124,86 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "pagination.Page-models_Account": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Account"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pagination.Page-models_AccountTransaction": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AccountTransaction"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pagination.Page-models_File": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.File"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pagination.Page-models_Transaction": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Transaction"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
         }
     }
 }`
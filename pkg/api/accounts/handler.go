@@ -2,17 +2,34 @@ package accounts
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/wjoseperez20/zenwallet/pkg/audit"
+	"github.com/wjoseperez20/zenwallet/pkg/balances"
 	"github.com/wjoseperez20/zenwallet/pkg/cache"
 	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/fees"
+	"github.com/wjoseperez20/zenwallet/pkg/interest"
+	"github.com/wjoseperez20/zenwallet/pkg/ledger"
 	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"github.com/wjoseperez20/zenwallet/pkg/pagination"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var errStaleVersion = errors.New("account was modified")
+
+// accountOrder lists the accounts by account number
+var accountOrder = pagination.Order{Key: "account"}
+
 // @BasePath /api/v1
 
 // FindAccount godoc
@@ -22,7 +39,8 @@ import (
 // @Security JwtAuth
 // @Produce json
 // @Param id path string true "Account ID"
-// @Success 200 {object} models.Account "Successfully retrieved account"
+// @Success 200 {object} models.Account "Successfully retrieved account, its version is returned in the ETag header"
+// @Header 200 {string} ETag "Version of the account"
 // @Failure 404 {string} string "Account not found"
 // @Router /accounts/{id} [get]
 func FindAccount(c *gin.Context) {
@@ -33,71 +51,81 @@ func FindAccount(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", etag(account.Version))
 	c.JSON(http.StatusOK, account)
 }
 
 // FindAccounts godoc
-// @Summary Get all accounts with pagination
-// @Description Get a list of all accounts with optional pagination
+// @Summary Get all accounts with cursor pagination
+// @Description Get a page of accounts ordered by account number. Pass the next_cursor of a page as cursor to get the next one.
 // @Tags Accounts
 // @Security JwtAuth
 // @Produce json
-// @Param offset query int false "Offset for pagination" default(0)
-// @Param limit query int false "Limit for pagination" default(10)
-// @Success 200 {array} models.Account "Successfully retrieved list of accounts"
+// @Param status query string false "Account status" Enums(active, frozen, dormant, closed)
+// @Param cursor query string false "Cursor returned as next_cursor with the previous page"
+// @Param limit query int false "Page size, at most 100" default(10)
+// @Param total query bool false "Include the number of accounts matching the filters" default(false)
+// @Success 200 {object} pagination.Page[models.Account] "Successfully retrieved page of accounts"
+// @Failure 400 {string} string "Bad Request"
 // @Router /accounts [get]
 func FindAccounts(c *gin.Context) {
-	var accounts []models.Account
-
-	// Get query params
-	offsetQuery := c.DefaultQuery("offset", "0")
-	limitQuery := c.DefaultQuery("limit", "10")
-
-	// Convert query params to integers
-	offset, err := strconv.Atoi(offsetQuery)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset format"})
-		return
-	}
+	var page pagination.Page[models.Account]
 
-	limit, err := strconv.Atoi(limitQuery)
+	params, err := pagination.Parse(c.Request.URL.Query())
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit format"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Create a cache key based on query params
-	cacheKey := "accounts_offset_" + offsetQuery + "_limit_" + limitQuery
+	cacheKey := "accounts_page_" + params.Key()
+	status := c.Query("status")
+	if status != "" {
+		cacheKey += "_status_" + status
+	}
 
 	// Try fetching the data from Redis first
-	cachedAccounts, err := cache.Rdb.Get(cache.Ctx, cacheKey).Result()
+	cachedPage, err := cache.Rdb.Get(cache.Ctx, cacheKey).Result()
 	if err == nil {
-		err := json.Unmarshal([]byte(cachedAccounts), &accounts)
+		err := json.Unmarshal([]byte(cachedPage), &page)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal cached data"})
 			return
 		}
-		c.JSON(http.StatusOK, accounts)
+		c.JSON(http.StatusOK, page)
 		return
 	}
 
 	// If cache missed, fetch data from the database
-	database.DB.Offset(offset).Limit(limit).Find(&accounts)
+	query := database.DB.Model(&models.Account{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	page, err = pagination.Fetch(query, params, accountOrder, accountCursor)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch accounts"})
+		return
+	}
 
-	// Serialize accounts object and store it in Redis
-	serializedAccounts, err := json.Marshal(accounts)
+	// Serialize the page and store it in Redis
+	serializedPage, err := json.Marshal(page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal data"})
 		return
 	}
-	err = cache.Rdb.Set(cache.Ctx, cacheKey, serializedAccounts, time.Minute).Err() // Here TTL is set to one hour
+	err = cache.Rdb.Set(cache.Ctx, cacheKey, serializedPage, time.Minute).Err() // Here TTL is set to one hour
 	if err != nil {
 		log.Default().Println(err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set cache"})
 		return
 	}
 
-	c.JSON(http.StatusOK, accounts)
+	c.JSON(http.StatusOK, page)
 }
 
 // CreateAccount godoc
@@ -122,37 +150,50 @@ func CreateAccount(c *gin.Context) {
 
 	account := models.Account{Client: input.Client, Email: input.Email}
 
-	database.DB.Create(&account)
-
-	// Invalidate cache
-	keysPattern := "accounts_offset_*"
-	keys, err := cache.Rdb.Keys(cache.Ctx, keysPattern).Result()
-	if err == nil {
-		for _, key := range keys {
-			cache.Rdb.Del(cache.Ctx, key)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&account).Error; err != nil {
+			return err
 		}
+
+		return audit.Record(tx, c, models.AuditCreate, "account", account.Account, nil, account)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+		return
 	}
 
+	invalidateCache("accounts_page_*")
+
 	c.JSON(http.StatusCreated, account)
 }
 
 // UpdateAccount godoc
 // @Summary Update an account by ID
-// @Description Update the account details for the given ID
+// @Description Update the account details for the given ID. The If-Match header must hold the ETag returned by the last read.
 // @Tags Accounts
 // @Security JwtAuth
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Account ID"
+// @Param If-Match header string true "ETag of the account"
 // @Param input body models.UpdateAccount true "Update account object"
 // @Success 200 {object} models.Account "Successfully updated account"
+// @Header 200 {string} ETag "New version of the account"
 // @Failure 400 {string} string "Bad Request"
 // @Failure 404 {string} string "account not found"
+// @Failure 412 {string} string "account was modified"
+// @Failure 428 {string} string "If-Match header required"
 // @Router /accounts/{id} [put]
 func UpdateAccount(c *gin.Context) {
 	var account models.Account
 	var input models.UpdateAccount
 
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
 	if err := database.DB.Where("account = ?", c.Param("account")).First(&account).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
 		return
@@ -163,30 +204,794 @@ func UpdateAccount(c *gin.Context) {
 		return
 	}
 
-	database.DB.Model(&account).Updates(models.Account{Client: input.Client, Email: input.Email})
+	updates := map[string]interface{}{"version": gorm.Expr("version + 1")}
+	if input.Client != "" {
+		updates["client"] = input.Client
+	}
+	if input.Email != "" {
+		updates["email"] = input.Email
+	}
+
+	// Only update the account if nobody changed it since it was read
+	before := account
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&account).Where("version = ?", version).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errStaleVersion
+		}
 
+		if input.Client != "" {
+			account.Client = input.Client
+		}
+		if input.Email != "" {
+			account.Email = input.Email
+		}
+		account.Version = version + 1
+
+		return audit.Record(tx, c, models.AuditUpdate, "account", account.Account, before, account)
+	})
+	if errors.Is(err, errStaleVersion) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "account was modified, fetch it again"})
+		return
+	}
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update account"})
+		return
+	}
+
+	invalidateCache("accounts_page_*")
+
+	c.Header("ETag", etag(account.Version))
 	c.JSON(http.StatusOK, account)
 }
 
 // DeleteAccount godoc
-// @Summary Delete an account by ID
-// @Description Delete the account with the given ID
+// @Summary Close an account by ID
+// @Description Close the account with the given ID, which must have a zero balance and no pending holds. Accounts are never deleted, a closed account rejects every posting. The If-Match header must hold the ETag returned by the last read.
 // @Tags Accounts
 // @Security JwtAuth
 // @Produce json
 // @Param id path string true "Account ID"
-// @Success 202 {object} models.Account "Successfully deleted account"
+// @Param reason query string false "Reason of the closure" default(closed on request)
+// @Param If-Match header string true "ETag of the account"
+// @Success 202 {object} models.Account "Successfully closed account"
 // @Failure 404 {string} string "account not found"
+// @Failure 409 {string} string "account already closed or balance not zero"
+// @Failure 412 {string} string "account was modified"
+// @Failure 428 {string} string "If-Match header required"
 // @Router /accounts/{id} [delete]
 func DeleteAccount(c *gin.Context) {
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	account, ok := changeStatus(c, models.AuditDelete, &version, models.AccountClosed, c.DefaultQuery("reason", "closed on request"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusAccepted, account)
+}
+
+// UpdateAccountStatus godoc
+// @Summary Change the status of an account
+// @Description Move the account to another status. Active accounts can be frozen, marked dormant or closed; frozen and dormant accounts can be reactivated or closed, dormant ones also frozen. Frozen and dormant accounts reject debits, closed accounts reject every posting and are final.
+// @Tags Accounts
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Account ID"
+// @Param input body models.UpdateAccountStatus true "Status and reason"
+// @Success 200 {object} models.Account "Successfully changed status"
+// @Header 200 {string} ETag "New version of the account"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "account not found"
+// @Failure 409 {string} string "transition not allowed or balance not zero"
+// @Router /accounts/{id}/status [post]
+func UpdateAccountStatus(c *gin.Context) {
+	var input models.UpdateAccountStatus
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account, ok := changeStatus(c, models.AuditChangeStatus, nil, input.Status, input.Reason)
+	if !ok {
+		return
+	}
+
+	c.Header("ETag", etag(account.Version))
+	c.JSON(http.StatusOK, account)
+}
+
+// FindAccountStatusHistory godoc
+// @Summary Get the status history of an account
+// @Description Get every status transition of the account, oldest first
+// @Tags Accounts
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {array} models.AccountStatusChange "Successfully retrieved status history"
+// @Failure 404 {string} string "account not found"
+// @Router /accounts/{id}/status/history [get]
+func FindAccountStatusHistory(c *gin.Context) {
 	var account models.Account
+	var changes []models.AccountStatusChange
 
 	if err := database.DB.Where("account = ?", c.Param("account")).First(&account).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
 		return
 	}
 
-	database.DB.Delete(&account)
+	if err := database.DB.Where("account_id = ?", account.Account).Order("created_at, id").Find(&changes).Error; err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch status history"})
+		return
+	}
 
-	c.JSON(http.StatusAccepted, account)
+	c.JSON(http.StatusOK, changes)
+}
+
+// FindAccountBalance godoc
+// @Summary Find the balance of an account at a date
+// @Description Get the balance of the account at the end of a day, computed from the transactions dated on or before it
+// @Tags Accounts
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param as_of query string false "Day as YYYY-MM-DD, today by default"
+// @Success 200 {object} models.AccountBalance "Successfully retrieved balance"
+// @Failure 400 {string} string "Invalid as_of format"
+// @Failure 404 {string} string "account not found"
+// @Router /accounts/{id}/balance [get]
+func FindAccountBalance(c *gin.Context) {
+	var account models.Account
+
+	asOf := time.Now().UTC().Truncate(24 * time.Hour)
+	if value := c.Query("as_of"); value != "" {
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid as_of format"})
+			return
+		}
+		asOf = day
+	}
+
+	if err := database.DB.Where("account = ?", c.Param("account")).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+
+	balance, err := balances.AsOf(database.DB, account.Account, asOf)
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute balance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AccountBalance{Account: account.Account, AsOf: asOf.Format("2006-01-02"), Balance: balance})
+}
+
+// FindAccountBalanceSeries godoc
+// @Summary Get the daily balances of an account
+// @Description Get the balance of the account at the end of every day of a range of at most 366 days, the last 30 days by default
+// @Tags Accounts
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param from query string false "First day as YYYY-MM-DD"
+// @Param to query string false "Last day as YYYY-MM-DD, today by default"
+// @Success 200 {object} models.BalanceSeries "Successfully retrieved balance series"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "account not found"
+// @Router /accounts/{id}/balance/daily [get]
+func FindAccountBalanceSeries(c *gin.Context) {
+	var account models.Account
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if value := c.Query("to"); value != "" {
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to format"})
+			return
+		}
+		to = day
+	}
+
+	from := to.AddDate(0, 0, -29)
+	if value := c.Query("from"); value != "" {
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from format"})
+			return
+		}
+		from = day
+	}
+
+	if err := database.DB.Where("account = ?", c.Param("account")).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+
+	points, err := balances.Daily(database.DB, account.Account, from, to)
+	if errors.Is(err, balances.ErrInvalidRange) || errors.Is(err, balances.ErrRangeTooLarge) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute balances"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.BalanceSeries{
+		Account:  account.Account,
+		From:     from.Format("2006-01-02"),
+		To:       to.Format("2006-01-02"),
+		Balances: points,
+	})
+}
+
+// FindAccountSnapshots godoc
+// @Summary Get the balance snapshots of an account
+// @Description Get the opening and closing balance and the debit and credit totals of the account for every closed day of a range, the last 30 days by default
+// @Tags Accounts
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param from query string false "First day as YYYY-MM-DD"
+// @Param to query string false "Last day as YYYY-MM-DD, today by default"
+// @Success 200 {array} models.BalanceSnapshot "Successfully retrieved snapshots"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "account not found"
+// @Router /accounts/{id}/snapshots [get]
+func FindAccountSnapshots(c *gin.Context) {
+	var account models.Account
+	var snapshots []models.BalanceSnapshot
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if value := c.Query("to"); value != "" {
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to format"})
+			return
+		}
+		to = day
+	}
+
+	from := to.AddDate(0, 0, -29)
+	if value := c.Query("from"); value != "" {
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from format"})
+			return
+		}
+		from = day
+	}
+
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": balances.ErrInvalidRange.Error()})
+		return
+	}
+	if to.Sub(from) >= balances.MaxDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": balances.ErrRangeTooLarge.Error()})
+		return
+	}
+
+	if err := database.DB.Where("account = ?", c.Param("account")).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+
+	err := database.DB.Where("account_id = ? AND date >= ? AND date <= ?", account.Account, from, to).
+		Order("date").
+		Find(&snapshots).Error
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch snapshots"})
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshots)
+}
+
+// FindAccountPolicy godoc
+// @Summary Find the policy of an account
+// @Description Get the overdraft limit, debit limits and allowed currencies of the account. Accounts without a policy get the default one, which forbids overdrafts.
+// @Tags Accounts
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} models.AccountPolicy "Successfully retrieved policy"
+// @Failure 404 {string} string "account not found"
+// @Router /accounts/{id}/policy [get]
+func FindAccountPolicy(c *gin.Context) {
+	var account models.Account
+
+	if err := database.DB.Where("account = ?", c.Param("account")).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+
+	policy, err := ledger.Policy(database.DB, account.Account)
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// UpdateAccountPolicy godoc
+// @Summary Update the policy of an account
+// @Description Replace the policy of the account. Limits left empty are not enforced and an empty list of currencies accepts any currency.
+// @Tags Accounts
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Account ID"
+// @Param input body models.UpdateAccountPolicy true "Account policy object"
+// @Success 200 {object} models.AccountPolicy "Successfully updated policy"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "account not found"
+// @Router /accounts/{id}/policy [put]
+func UpdateAccountPolicy(c *gin.Context) {
+	var account models.Account
+	var input models.UpdateAccountPolicy
+
+	if err := database.DB.Where("account = ?", c.Param("account")).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, limit := range []*money.Amount{&input.OverdraftLimit, input.MaxDebit, input.DailyDebitLimit, input.MonthlyDebitLimit} {
+		if limit != nil && *limit < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limits must not be negative"})
+			return
+		}
+	}
+
+	currencies := make([]string, 0, len(input.AllowedCurrencies))
+	for _, currency := range input.AllowedCurrencies {
+		currencies = append(currencies, strings.ToUpper(currency))
+	}
+
+	policy := models.AccountPolicy{
+		Account:           account.Account,
+		OverdraftLimit:    input.OverdraftLimit,
+		MaxDebit:          input.MaxDebit,
+		DailyDebitLimit:   input.DailyDebitLimit,
+		MonthlyDebitLimit: input.MonthlyDebitLimit,
+		AllowedCurrencies: currencies,
+	}
+
+	// Insert the policy, or replace every limit of the existing one
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		before, err := ledger.Policy(tx, account.Account)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "account_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"overdraft_limit", "max_debit", "daily_debit_limit", "monthly_debit_limit", "allowed_currencies", "updated_at",
+			}),
+		}).Create(&policy).Error
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditUpdate, "account_policy", account.Account, before, policy)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// FindAccountInterest godoc
+// @Summary Find the interest product of an account
+// @Description Get the interest terms of the account and the interest accrued and not posted yet
+// @Tags Accounts
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} models.InterestProduct "Successfully retrieved interest product"
+// @Failure 404 {string} string "account not found or account has no interest product"
+// @Router /accounts/{id}/interest [get]
+func FindAccountInterest(c *gin.Context) {
+	var product models.InterestProduct
+
+	if err := database.DB.Where("account_id = ?", c.Param("account")).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "interest product not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// UpdateAccountInterest godoc
+// @Summary Update the interest product of an account
+// @Description Set the annual rate (a percentage), day count convention, compounding and posting frequency of the account. A new product accrues from today, changed terms apply from the next day accrued and keep the interest accrued so far.
+// @Tags Accounts
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Account ID"
+// @Param input body models.UpdateInterestProduct true "Interest product object"
+// @Success 200 {object} models.InterestProduct "Successfully updated interest product"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "account not found"
+// @Failure 409 {string} string "account is closed"
+// @Router /accounts/{id}/interest [put]
+func UpdateAccountInterest(c *gin.Context) {
+	var account models.Account
+	var input models.UpdateInterestProduct
+
+	if err := database.DB.Where("account = ?", c.Param("account")).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if account.Status == models.AccountClosed {
+		c.JSON(http.StatusConflict, gin.H{"error": ledger.ErrAccountClosed.Error()})
+		return
+	}
+
+	product := models.InterestProduct{
+		Account:     account.Account,
+		AnnualRate:  input.AnnualRate,
+		DayCount:    strings.ToUpper(input.DayCount),
+		Compounding: input.Compounding,
+		Posting:     input.Posting,
+		StartDate:   time.Now().UTC().Truncate(24 * time.Hour),
+	}
+	if err := interest.Validate(product); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Insert the product, or replace the terms of the existing one
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var products []models.InterestProduct
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("account_id = ?", account.Account).Limit(1).Find(&products).Error
+		if err != nil {
+			return err
+		}
+
+		if len(products) == 0 {
+			if err := tx.Create(&product).Error; err != nil {
+				return err
+			}
+
+			return audit.Record(tx, c, models.AuditCreate, "interest_product", account.Account, nil, product)
+		}
+
+		before := products[0]
+		product.StartDate = before.StartDate
+		product.AccruedThrough = before.AccruedThrough
+		product.Accrued = before.Accrued
+		product.Compounded = before.Compounded
+		product.CreatedAt = before.CreatedAt
+
+		err = tx.Model(&product).Select("annual_rate", "day_count", "compounding", "posting", "updated_at").Updates(product).Error
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditUpdate, "interest_product", account.Account, before, product)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update interest product"})
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// FindAccountInterestAccruals godoc
+// @Summary Get the interest accrual history of an account
+// @Description Get the daily interest accruals of the account, oldest first, with the balance, rate and day fraction each one was calculated with and the interest posted
+// @Tags Accounts
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD"
+// @Param offset query int false "Offset for pagination" default(0)
+// @Param limit query int false "Limit for pagination" default(100)
+// @Success 200 {array} models.InterestAccrual "Successfully retrieved interest accruals"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "account not found"
+// @Router /accounts/{id}/interest/accruals [get]
+func FindAccountInterestAccruals(c *gin.Context) {
+	var account models.Account
+	var accruals []models.InterestAccrual
+
+	if err := database.DB.Where("account = ?", c.Param("account")).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+
+	// Convert query params to integers
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset format"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit format"})
+		return
+	}
+
+	query := database.DB.Where("account_id = ?", account.Account).Order("date").Offset(offset).Limit(limit)
+	if from := c.Query("from"); from != "" {
+		day, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from format"})
+			return
+		}
+		query = query.Where("date >= ?", day)
+	}
+	if to := c.Query("to"); to != "" {
+		day, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to format"})
+			return
+		}
+		query = query.Where("date <= ?", day)
+	}
+
+	if err := query.Find(&accruals).Error; err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch interest accruals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, accruals)
+}
+
+// FindAccountFees godoc
+// @Summary Find the fee schedule attached to an account
+// @Description Get the fee schedule attached to the account and the end of the next month its monthly fee is charged for
+// @Tags Accounts
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} models.AccountFee "Successfully retrieved account fees"
+// @Failure 404 {string} string "account has no fee schedule"
+// @Router /accounts/{id}/fees [get]
+func FindAccountFees(c *gin.Context) {
+	var assignment models.AccountFee
+
+	if err := database.DB.Where("account_id = ?", c.Param("account")).First(&assignment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account has no fee schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// UpdateAccountFees godoc
+// @Summary Attach a fee schedule to an account
+// @Description Attach a fee schedule to the account, replacing the previous one. The monthly fee is first charged at the end of the current month.
+// @Tags Accounts
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Account ID"
+// @Param input body models.UpdateAccountFee true "Account fee object"
+// @Success 200 {object} models.AccountFee "Successfully attached fee schedule"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "account or fee schedule not found"
+// @Router /accounts/{id}/fees [put]
+func UpdateAccountFees(c *gin.Context) {
+	var account models.Account
+	var input models.UpdateAccountFee
+
+	if err := database.DB.Where("account = ?", c.Param("account")).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var schedules int64
+	database.DB.Model(&models.FeeSchedule{}).Where("id = ?", input.FeeScheduleID).Count(&schedules)
+	if schedules == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "fee schedule not found"})
+		return
+	}
+
+	assignment := models.AccountFee{
+		Account:       account.Account,
+		FeeScheduleID: input.FeeScheduleID,
+		NextCharge:    fees.MonthEnd(time.Now().UTC().Truncate(24 * time.Hour)),
+	}
+
+	// Insert the assignment, or replace the schedule of the existing one
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var assignments []models.AccountFee
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("account_id = ?", account.Account).Limit(1).Find(&assignments).Error
+		if err != nil {
+			return err
+		}
+
+		if len(assignments) == 0 {
+			if err := tx.Create(&assignment).Error; err != nil {
+				return err
+			}
+
+			return audit.Record(tx, c, models.AuditCreate, "account_fee", account.Account, nil, assignment)
+		}
+
+		before := assignments[0]
+		assignment.NextCharge = before.NextCharge
+		assignment.CreatedAt = before.CreatedAt
+
+		if err := tx.Model(&assignment).Select("fee_schedule_id", "updated_at").Updates(assignment).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditUpdate, "account_fee", account.Account, before, assignment)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to attach fee schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// DeleteAccountFees godoc
+// @Summary Detach the fee schedule of an account
+// @Description Stop charging fees to the account. The monthly fee of the current month is not charged.
+// @Tags Accounts
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 202 {object} models.AccountFee "Successfully detached fee schedule"
+// @Failure 404 {string} string "account has no fee schedule"
+// @Router /accounts/{id}/fees [delete]
+func DeleteAccountFees(c *gin.Context) {
+	var assignment models.AccountFee
+
+	if err := database.DB.Where("account_id = ?", c.Param("account")).First(&assignment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account has no fee schedule"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&assignment).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditDelete, "account_fee", assignment.Account, assignment, nil)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to detach fee schedule"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, assignment)
+}
+
+// changeStatus locks the account of the request, moves it to status and
+// audits the change as action. When version is set the account must not
+// have changed since the client read it. When the transition fails the
+// response is written and false is returned.
+// Private function, not exposed to the API
+func changeStatus(c *gin.Context, action string, version *int, status string, reason string) (*models.Account, bool) {
+	var account models.Account
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("account = ?", c.Param("account")).First(&account).Error; err != nil {
+			return err
+		}
+
+		if version != nil && account.Version != *version {
+			return errStaleVersion
+		}
+
+		before := account
+		if _, err := ledger.Transition(tx, &account, status, reason, c.GetString("username")); err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, action, "account", account.Account, before, account)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		case errors.Is(err, errStaleVersion):
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "account was modified, fetch it again"})
+		case errors.Is(err, ledger.ErrInvalidTransition), errors.Is(err, ledger.ErrBalanceNotZero):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Default().Println(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update account status"})
+		}
+		return nil, false
+	}
+
+	invalidateCache("accounts_page_*")
+
+	return &account, true
+}
+
+// invalidateCache removes the cached pages matching the given patterns
+// Private function, not exposed to the API
+func invalidateCache(keysPatterns ...string) {
+	for _, keysPattern := range keysPatterns {
+		keys, err := cache.Rdb.Keys(cache.Ctx, keysPattern).Result()
+		if err == nil {
+			for _, key := range keys {
+				cache.Rdb.Del(cache.Ctx, key)
+			}
+		}
+	}
+}
+
+// etag formats the version of an account as an entity tag
+// Private function, not exposed to the API
+func etag(version int) string {
+	return fmt.Sprintf("%q", strconv.Itoa(version))
+}
+
+// ifMatch reads the version expected by the client from the If-Match header.
+// When the header is missing or invalid the response is written and false
+// is returned.
+// Private function, not exposed to the API
+func ifMatch(c *gin.Context) (int, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header required"})
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, "W/"), `"`))
+	if err != nil {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Invalid If-Match header"})
+		return 0, false
+	}
+
+	return version, true
+}
+
+// accountCursor returns the position of an account in accountOrder
+// Private function, not exposed to the API
+func accountCursor(account models.Account) pagination.Cursor {
+	return pagination.Cursor{ID: account.Account}
 }
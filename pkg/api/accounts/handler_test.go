@@ -2,17 +2,23 @@ package accounts
 
 import (
 	"bytes"
+	"context"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/cache"
 	"github.com/wjoseperez20/zenwallet/pkg/database"
 	"github.com/wjoseperez20/zenwallet/pkg/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -34,15 +40,16 @@ func TestFindAccount_SuccessfulRequest(t *testing.T) {
 
 	dbMock, gormDB := setupTestDatabase(t)
 	database.DB = gormDB
-	mockAccount := models.Account{ID: 10001, Client: "test", Email: "test@emails.com", Account: 10001, Balance: 1.0, CreatedAt: parseTime, UpdatedAt: parseTime}
+	mockAccount := models.Account{ID: 10001, Client: "test", Email: "test@emails.com", Account: 10001, Balance: 1.0, Version: 3, CreatedAt: parseTime, UpdatedAt: parseTime}
 	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
 		WithArgs("10001").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "client", "emails", "account", "balance", "created_at", "updated_at"}).
-			AddRow(mockAccount.ID, mockAccount.Client, mockAccount.Email, mockAccount.Account, mockAccount.Balance, mockAccount.CreatedAt, mockAccount.UpdatedAt))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "client", "emails", "account", "balance", "version", "created_at", "updated_at"}).
+			AddRow(mockAccount.ID, mockAccount.Client, mockAccount.Email, mockAccount.Account, mockAccount.Balance, mockAccount.Version, mockAccount.CreatedAt, mockAccount.UpdatedAt))
 
 	// When
 	w := performRequest(r, "GET", "/accounts/10001")
 	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `"3"`, w.Header().Get("ETag"))
 
 	var expected models.Account
 	err = json.Unmarshal(w.Body.Bytes(), &expected)
@@ -81,10 +88,25 @@ func TestFindAccount_NotFound(t *testing.T) {
 	}
 }
 
+func TestFindAccounts_InvalidLimit(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/accounts", FindAccounts)
+
+	// When
+	w := performRequest(r, "GET", "/accounts?limit=500")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"limit must be between 1 and 100"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
 func TestUpdateAccount_SuccessfulRequest(t *testing.T) {
 	// Given
 	r := gin.Default()
 	r.PUT("/accounts/:account", UpdateAccount)
+	setupUnavailableCache(t)
 
 	parseTime, err := time.Parse(time.RFC3339Nano, "2023-11-25T15:30:45.123456Z")
 	require.NoError(t, err)
@@ -105,14 +127,16 @@ func TestUpdateAccount_SuccessfulRequest(t *testing.T) {
 			AddRow(mockAccount.ID, mockAccount.Client, mockAccount.Email, mockAccount.Account, mockAccount.Balance, parseTime, parseTime))
 
 	dbMock.ExpectBegin()
-	dbMock.ExpectExec(`UPDATE "accounts"`).
-		WithArgs(incomingAccount.Client, incomingAccount.Email, AnyTime{}, 10001).
+	dbMock.ExpectExec(`UPDATE "accounts" SET "client"=(.+),"email"=(.+),"version"=version \+ 1,"updated_at"=(.+) WHERE version = (.+) AND "account" = (.+)`).
+		WithArgs(incomingAccount.Client, incomingAccount.Email, AnyTime{}, 1, 10001).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(dbMock, models.AuditUpdate, "account", "10001")
 	dbMock.ExpectCommit()
 
 	// When
-	w := performRequest(r, "PUT", "/accounts/10001", toJSON(incomingAccount))
+	w := performRequestWithHeaders(r, "PUT", "/accounts/10001", map[string]string{"If-Match": `"1"`}, toJSON(incomingAccount))
 	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `"2"`, w.Header().Get("ETag"))
 
 	var expected models.Account
 	err = json.Unmarshal(w.Body.Bytes(), &expected)
@@ -145,7 +169,7 @@ func TestUpdateAccount_NotFound(t *testing.T) {
 		WillReturnError(gorm.ErrRecordNotFound)
 
 	// When
-	w := performRequest(r, "PUT", "/accounts/999", toJSON(incomingAccount))
+	w := performRequestWithHeaders(r, "PUT", "/accounts/999", map[string]string{"If-Match": `"1"`}, toJSON(incomingAccount))
 	require.Equal(t, http.StatusNotFound, w.Code)
 
 	expected := `{"error":"account not found"}`
@@ -161,26 +185,30 @@ func TestDeleteAccount_SuccessfulRequest(t *testing.T) {
 	// Given
 	r := gin.Default()
 	r.DELETE("/accounts/:account", DeleteAccount)
+	setupUnavailableCache(t)
 
 	parseTime, err := time.Parse(time.RFC3339Nano, "2023-11-25T15:30:45.123456Z")
 	require.NoError(t, err)
 
 	dbMock, gormDB := setupTestDatabase(t)
 	database.DB = gormDB
-	mockAccount := models.Account{ID: 1, Client: "test", Email: "test@emails.com", Account: 10001, Balance: 1.0, CreatedAt: parseTime, UpdatedAt: parseTime}
-
-	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
-		WithArgs("10001").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "client", "emails", "account", "balance", "created_at", "updated_at"}).
-			AddRow(mockAccount.ID, mockAccount.Client, mockAccount.Email, mockAccount.Account, mockAccount.Balance, mockAccount.CreatedAt, mockAccount.UpdatedAt))
+	mockAccount := models.Account{ID: 1, Client: "test", Email: "test@emails.com", Account: 10001, Version: 1, Status: models.AccountActive, CreatedAt: parseTime, UpdatedAt: parseTime}
 
 	dbMock.ExpectBegin()
-	dbMock.ExpectExec(`DELETE FROM "accounts" WHERE (.+)`).
-		WithArgs(10001).WillReturnResult(sqlmock.NewResult(1, 1))
+	expectLockedAccount(dbMock, mockAccount, "0.00")
+	dbMock.ExpectExec(`UPDATE "schedules" SET "status"=(.+),"updated_at"=(.+) WHERE account_id = (.+) AND status IN \((.+)\)`).
+		WithArgs(models.ScheduleCancelled, AnyTime{}, 10001, models.ScheduleActive, models.SchedulePaused).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectQuery(`INSERT INTO "account_status_changes" (.+) RETURNING "id"`).
+		WithArgs(10001, models.AccountActive, models.AccountClosed, "closed on request", "", AnyTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	dbMock.ExpectExec(`UPDATE "accounts" SET (.+) WHERE "account" = (.+)`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(dbMock, models.AuditDelete, "account", "10001")
 	dbMock.ExpectCommit()
 
 	// When
-	w := performRequest(r, "DELETE", "/accounts/10001")
+	w := performRequestWithHeaders(r, "DELETE", "/accounts/10001", map[string]string{"If-Match": `"1"`})
 	require.Equal(t, http.StatusAccepted, w.Code)
 
 	var expected models.Account
@@ -189,6 +217,9 @@ func TestDeleteAccount_SuccessfulRequest(t *testing.T) {
 	// Then
 	require.NoError(t, err)
 	require.Equal(t, mockAccount.ID, expected.ID)
+	require.Equal(t, models.AccountClosed, expected.Status)
+	require.Equal(t, "closed on request", expected.StatusReason)
+	require.Equal(t, 2, expected.Version)
 
 	// Verify all expectations were met
 	if err := dbMock.ExpectationsWereMet(); err != nil {
@@ -196,6 +227,28 @@ func TestDeleteAccount_SuccessfulRequest(t *testing.T) {
 	}
 }
 
+func TestDeleteAccount_BalanceNotZero(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.DELETE("/accounts/:account", DeleteAccount)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+
+	dbMock.ExpectBegin()
+	expectLockedAccount(dbMock, models.Account{ID: 1, Account: 10001, Version: 1, Status: models.AccountActive}, "12.50")
+	dbMock.ExpectRollback()
+
+	// When
+	w := performRequestWithHeaders(r, "DELETE", "/accounts/10001", map[string]string{"If-Match": `"1"`})
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// Then
+	expected := `{"error":"account must have a zero balance and no pending holds to be closed"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
 func TestDeleteAccount_NotFound(t *testing.T) {
 	// Given
 	r := gin.Default()
@@ -203,12 +256,14 @@ func TestDeleteAccount_NotFound(t *testing.T) {
 
 	dbMock, gormDB := setupTestDatabase(t)
 	database.DB = gormDB
-	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1 FOR UPDATE`).
 		WithArgs("999").
 		WillReturnError(gorm.ErrRecordNotFound)
+	dbMock.ExpectRollback()
 
 	// When
-	w := performRequest(r, "DELETE", "/accounts/999")
+	w := performRequestWithHeaders(r, "DELETE", "/accounts/999", map[string]string{"If-Match": `"1"`})
 	require.Equal(t, http.StatusNotFound, w.Code)
 
 	expected := `{"error":"account not found"}`
@@ -220,6 +275,244 @@ func TestDeleteAccount_NotFound(t *testing.T) {
 	}
 }
 
+func TestUpdateAccountStatus_ClosedIsFinal(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/accounts/:account/status", UpdateAccountStatus)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	expectLockedAccount(dbMock, models.Account{ID: 1, Account: 10001, Version: 3, Status: models.AccountClosed}, "0.00")
+	dbMock.ExpectRollback()
+
+	// When
+	incomingStatus := models.UpdateAccountStatus{Status: models.AccountActive, Reason: "client request"}
+	w := performRequest(r, "POST", "/accounts/10001/status", toJSON(incomingStatus))
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// Then
+	expected := `{"error":"account status transition not allowed"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUpdateAccount_MissingIfMatch(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.PUT("/accounts/:account", UpdateAccount)
+
+	incomingAccount := models.Account{Client: "test_update"}
+
+	// When
+	w := performRequest(r, "PUT", "/accounts/10001", toJSON(incomingAccount))
+	require.Equal(t, http.StatusPreconditionRequired, w.Code)
+
+	// Then
+	expected := `{"error":"If-Match header required"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+func TestUpdateAccount_StaleVersion(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.PUT("/accounts/:account", UpdateAccount)
+
+	incomingAccount := models.Account{Client: "test_update"}
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs("10001").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account", "version"}).AddRow(1, 10001, 2))
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(`UPDATE "accounts" SET (.+) WHERE version = (.+) AND "account" = (.+)`).
+		WithArgs(incomingAccount.Client, AnyTime{}, 1, 10001).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectRollback()
+
+	// When
+	w := performRequestWithHeaders(r, "PUT", "/accounts/10001", map[string]string{"If-Match": `"1"`}, toJSON(incomingAccount))
+	require.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	// Then
+	expected := `{"error":"account was modified, fetch it again"}`
+	require.Equal(t, expected, w.Body.String())
+
+	// Verify all expectations were met
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindAccountPolicy_Default(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/accounts/:account/policy", FindAccountPolicy)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs("10001").
+		WillReturnRows(sqlmock.NewRows([]string{"account"}).AddRow(10001))
+	dbMock.ExpectQuery(`SELECT \* FROM "account_policies" WHERE account_id = (.+) LIMIT 1`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "overdraft_limit"}))
+
+	// When
+	w := performRequest(r, "GET", "/accounts/10001/policy")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var policy models.AccountPolicy
+	err := json.Unmarshal(w.Body.Bytes(), &policy)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, 10001, policy.Account)
+	require.Zero(t, policy.OverdraftLimit)
+	require.Nil(t, policy.MaxDebit)
+	require.Empty(t, policy.AllowedCurrencies)
+
+	// Verify all expectations were met
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateAccountPolicy_NegativeLimit(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.PUT("/accounts/:account/policy", UpdateAccountPolicy)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs("10001").
+		WillReturnRows(sqlmock.NewRows([]string{"account"}).AddRow(10001))
+
+	// When
+	w := performRequest(r, "PUT", "/accounts/10001/policy", []byte(`{"overdraft_limit": -10}`))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"limits must not be negative"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+// expectLockedAccount expects the account to be loaded with a row lock.
+func TestFindAccountBalance_AsOf(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/accounts/:account/balance", FindAccountBalance)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs("10023").
+		WillReturnRows(sqlmock.NewRows([]string{"account"}).AddRow(10023))
+	dbMock.ExpectQuery(`SELECT \* FROM "balance_snapshots" WHERE (.+) ORDER BY date DESC LIMIT 1`).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "transactions" WHERE account_id = (.+) AND date <= (.+)`).
+		WithArgs(10023, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow("1250.75"))
+
+	// When
+	w := performRequest(r, "GET", "/accounts/10023/balance?as_of=2024-03-31")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Then
+	expected := `{"account":10023,"as_of":"2024-03-31","balance":1250.75}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestFindAccountBalanceSeries_RangeTooLarge(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/accounts/:account/balance/daily", FindAccountBalanceSeries)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs("10023").
+		WillReturnRows(sqlmock.NewRows([]string{"account"}).AddRow(10023))
+
+	// When
+	w := performRequest(r, "GET", "/accounts/10023/balance/daily?from=2022-01-01&to=2024-03-31")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"the range must not exceed 366 days"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUpdateAccountInterest_Created(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.PUT("/accounts/:account/interest", UpdateAccountInterest)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs("10001").
+		WillReturnRows(sqlmock.NewRows([]string{"account", "status"}).AddRow(10001, models.AccountActive))
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`SELECT \* FROM "interest_products" WHERE account_id = (.+) LIMIT 1 FOR UPDATE`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}))
+	dbMock.ExpectQuery(`INSERT INTO "interest_products"`).
+		WithArgs(3.5, models.DayCount30360, models.CompoundingMonthly, models.PostingQuarterly, AnyTime{}, nil, "0.00000000", "0.00000000", AnyTime{}, AnyTime{}, 10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}).AddRow(10001))
+	expectAudit(dbMock, models.AuditCreate, "interest_product", "10001")
+	dbMock.ExpectCommit()
+
+	// When
+	body := []byte(`{"annual_rate": 3.5, "day_count": "30/360", "compounding": "monthly", "posting": "quarterly"}`)
+	w := performRequest(r, "PUT", "/accounts/10001/interest", body)
+
+	// Then
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"annual_rate":3.5,"day_count":"30/360","compounding":"monthly","posting":"quarterly"`)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUpdateAccountInterest_InvalidDayCount(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.PUT("/accounts/:account/interest", UpdateAccountInterest)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs("10001").
+		WillReturnRows(sqlmock.NewRows([]string{"account", "status"}).AddRow(10001, models.AccountActive))
+
+	// When
+	body := []byte(`{"annual_rate": 3.5, "day_count": "ACT/ACT", "compounding": "daily", "posting": "monthly"}`)
+	w := performRequest(r, "PUT", "/accounts/10001/interest", body)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"day_count must be ACT/365, ACT/360 or 30/360"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func expectLockedAccount(dbMock sqlmock.Sqlmock, account models.Account, balance string) {
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1 FOR UPDATE`).
+		WithArgs(strconv.Itoa(account.Account)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "client", "email", "account", "balance", "available_balance", "version", "status", "created_at", "updated_at"}).
+			AddRow(account.ID, account.Client, account.Email, account.Account, balance, balance, account.Version, account.Status, account.CreatedAt, account.UpdatedAt))
+}
+
+// expectAudit expects an audit entry to be recorded for the entity.
+func expectAudit(dbMock sqlmock.Sqlmock, action string, entity string, id string) {
+	dbMock.ExpectQuery(`INSERT INTO "audit_entries"`).
+		WithArgs("", action, entity, id, sqlmock.AnyArg(), sqlmock.AnyArg(), "", sqlmock.AnyArg(), AnyTime{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+}
+
 // setupTestDatabase sets up a mock database for testing.
 func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
 	// Create a mock database for testing
@@ -237,6 +530,11 @@ func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
 
 // performRequest performs an HTTP request and returns the response recorder.
 func performRequest(router *gin.Engine, method, path string, requestBody ...[]byte) *httptest.ResponseRecorder {
+	return performRequestWithHeaders(router, method, path, nil, requestBody...)
+}
+
+// performRequestWithHeaders performs an HTTP request with extra headers and returns the response recorder.
+func performRequestWithHeaders(router *gin.Engine, method, path string, headers map[string]string, requestBody ...[]byte) *httptest.ResponseRecorder {
 	var reqBody []byte
 	if len(requestBody) > 0 {
 		reqBody = requestBody[0]
@@ -245,6 +543,9 @@ func performRequest(router *gin.Engine, method, path string, requestBody ...[]by
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest(method, path, bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
 	router.ServeHTTP(w, req)
 	return w
 }
@@ -253,3 +554,18 @@ func toJSON(v interface{}) []byte {
 	result, _ := json.Marshal(v)
 	return result
 }
+
+// setupUnavailableCache points the cache to a Redis that cannot be reached,
+// so the handlers work without their cache as they do when Redis is down
+func setupUnavailableCache(t *testing.T) {
+	cache.Rdb = redis.NewClient(&redis.Options{
+		MaxRetries: -1,
+		Dialer: func(ctx context.Context, network string, addr string) (net.Conn, error) {
+			return nil, errors.New("cache unavailable")
+		},
+	})
+	t.Cleanup(func() {
+		cache.Rdb.Close()
+		cache.Rdb = nil
+	})
+}
@@ -0,0 +1,130 @@
+package admin
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/audit"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/reconciliation"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @BasePath /api/v1
+
+// FindReconciliation godoc
+// @Summary Reconcile account balances
+// @Description Recompute the balance of every account from its transactions and report the accounts whose stored balance differs
+// @Tags Admin
+// @Security ApiKeyAuth
+// @Security JwtAuth
+// @Produce json
+// @Success 200 {object} models.ReconciliationReport "Discrepancy report"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /admin/reconciliation [get]
+func FindReconciliation(c *gin.Context) {
+	report, err := reconciliation.Run(database.DB, false, nil)
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile balances"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// FixReconciliation godoc
+// @Summary Reconcile and fix account balances
+// @Description Same as the reconciliation report, but every divergent account gets an adjusting journal entry so its balance matches its transactions
+// @Tags Admin
+// @Security ApiKeyAuth
+// @Security JwtAuth
+// @Produce json
+// @Success 200 {object} models.ReconciliationReport "Discrepancy report with the fixed accounts"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /admin/reconciliation [post]
+func FixReconciliation(c *gin.Context) {
+	// Each account is adjusted in its own transaction, recorded with it
+	report, err := reconciliation.Run(database.DB, true, func(tx *gorm.DB, discrepancy models.Discrepancy) error {
+		return audit.Record(tx, c, models.AuditReconcile, "account", discrepancy.Account, nil, discrepancy)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile balances"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// FindAuditEntries godoc
+// @Summary Search the audit log
+// @Description Get the writes made through the API, newest first, optionally restricted to an entity, an actor, an action and a time range
+// @Tags Admin
+// @Security ApiKeyAuth
+// @Security JwtAuth
+// @Produce json
+// @Param entity query string false "Entity type, e.g. account or transaction"
+// @Param entity_id query string false "Entity ID"
+// @Param actor query string false "Username of the actor"
+// @Param action query string false "Action, e.g. create, update or delete"
+// @Param from query string false "Oldest entry as RFC 3339"
+// @Param to query string false "Newest entry as RFC 3339"
+// @Param offset query int false "Offset for pagination" default(0)
+// @Param limit query int false "Limit for pagination" default(50)
+// @Success 200 {array} models.AuditEntry "Audit entries"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /admin/audit [get]
+func FindAuditEntries(c *gin.Context) {
+	var entries []models.AuditEntry
+
+	// Convert query params to integers
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset format"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit format"})
+		return
+	}
+
+	query := database.DB.Order("id DESC").Offset(offset).Limit(limit)
+	for _, filter := range []string{"entity", "entity_id", "actor", "action"} {
+		if value := c.Query(filter); value != "" {
+			query = query.Where(filter+" = ?", value)
+		}
+	}
+
+	if from := c.Query("from"); from != "" {
+		date, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from format"})
+			return
+		}
+		query = query.Where("created_at >= ?", date)
+	}
+	if to := c.Query("to"); to != "" {
+		date, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to format"})
+			return
+		}
+		query = query.Where("created_at <= ?", date)
+	}
+
+	if err := query.Find(&entries).Error; err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit entries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
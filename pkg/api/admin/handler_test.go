@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"bytes"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFindAuditEntries(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/admin/audit", FindAuditEntries)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	createdAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	dbMock.ExpectQuery(`SELECT \* FROM "audit_entries" WHERE entity = (.+) AND actor = (.+) ORDER BY id DESC LIMIT 50`).
+		WithArgs("account", "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor", "action", "entity", "entity_id", "before", "after", "request_id", "client_ip", "created_at"}).
+			AddRow(7, "alice", "update", "account", "1", `{"name":"Main"}`, `{"name":"Savings"}`, "abc", "10.0.0.1", createdAt))
+
+	// When
+	w := performRequest(r, "GET", "/admin/audit?entity=account&actor=alice")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Then
+	expected := `[{"id":7,"actor":"alice","action":"update","entity":"account","entity_id":"1","before":{"name":"Main"},"after":{"name":"Savings"},"request_id":"abc","client_ip":"10.0.0.1","created_at":"2026-10-17T09:30:00Z"}]`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestFindAuditEntries_InvalidFrom(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/admin/audit", FindAuditEntries)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+
+	// When
+	w := performRequest(r, "GET", "/admin/audit?from=yesterday")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"Invalid from format"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	// Create a mock database for testing
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// Replace the actual database with the mock database for testing
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
+
+// performRequest performs an HTTP request and returns the response recorder.
+func performRequest(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(nil))
+	router.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,320 @@
+package budgets
+
+import (
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/audit"
+	"github.com/wjoseperez20/zenwallet/pkg/budgeting"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @BasePath /api/v1
+
+// FindBudgets godoc
+// @Summary Get all budgets with pagination
+// @Description Get a list of budgets, optionally restricted to an account or a client
+// @Tags Budgets
+// @Security JwtAuth
+// @Produce json
+// @Param account query int false "Account number"
+// @Param client query string false "Client name"
+// @Param offset query int false "Offset for pagination" default(0)
+// @Param limit query int false "Limit for pagination" default(10)
+// @Success 200 {array} models.Budget "Successfully retrieved list of budgets"
+// @Router /budgets [get]
+func FindBudgets(c *gin.Context) {
+	var budgets []models.Budget
+
+	// Convert query params to integers
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset format"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit format"})
+		return
+	}
+
+	query := database.DB.Order("id").Offset(offset).Limit(limit)
+	if account := c.Query("account"); account != "" {
+		query = query.Where("account_id = ?", account)
+	}
+	if client := c.Query("client"); client != "" {
+		query = query.Where("client = ?", client)
+	}
+
+	if err := query.Find(&budgets).Error; err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch budgets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, budgets)
+}
+
+// FindBudget godoc
+// @Summary Find a budget by ID
+// @Description Get details of a budget
+// @Tags Budgets
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Budget ID"
+// @Success 200 {object} models.Budget "Successfully retrieved budget"
+// @Failure 404 {string} string "budget not found"
+// @Router /budgets/{id} [get]
+func FindBudget(c *gin.Context) {
+	var budget models.Budget
+
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&budget).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "budget not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// FindBudgetProgress godoc
+// @Summary Get the consumption of a budget
+// @Description Get the amount spent and remaining of a budget during a month, the thresholds reached and the alerts sent
+// @Tags Budgets
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Budget ID"
+// @Param month query string false "Month as YYYY-MM, the current month by default"
+// @Success 200 {object} models.BudgetProgress "Successfully retrieved budget progress"
+// @Failure 400 {string} string "Invalid month format"
+// @Failure 404 {string} string "budget not found"
+// @Router /budgets/{id}/progress [get]
+func FindBudgetProgress(c *gin.Context) {
+	var budget models.Budget
+
+	period := budgeting.Period(time.Now().UTC())
+	if month := c.Query("month"); month != "" {
+		parsed, err := time.Parse("2006-01", month)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month format"})
+			return
+		}
+		period = parsed
+	}
+
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&budget).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "budget not found"})
+		return
+	}
+
+	progress, err := budgeting.Progress(database.DB, budget, period)
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute budget progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// CreateBudget godoc
+// @Summary Create a budget
+// @Description Create a monthly budget for the debits of a category, on an account or on every account of a client. Alerts are emailed when the spending reaches each threshold, 80% and 100% of the limit by default.
+// @Tags Budgets
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   input     body   models.CreateBudget   true   "Create budget object"
+// @Success 201 {object} models.Budget "Successfully created budget"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "account, client or category not found"
+// @Router /budgets [post]
+func CreateBudget(c *gin.Context) {
+	var input models.CreateBudget
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if (input.Account == nil) == (input.Client == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a budget applies to either an account or a client"})
+		return
+	}
+
+	thresholds, err := validateLimits(input.Limit, input.Thresholds)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The budget must cover at least one account
+	var accounts int64
+	query := database.DB.Model(&models.Account{})
+	if input.Account != nil {
+		query = query.Where("account = ?", *input.Account)
+	} else {
+		query = query.Where("client = ?", input.Client)
+	}
+	query.Count(&accounts)
+	if accounts == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+
+	category := strings.ToLower(strings.TrimSpace(input.Category))
+	var categories int64
+	database.DB.Model(&models.Category{}).Where("code = ?", category).Count(&categories)
+	if categories == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+		return
+	}
+
+	budget := models.Budget{
+		Name:       input.Name,
+		Account:    input.Account,
+		Client:     input.Client,
+		Category:   category,
+		Limit:      input.Limit,
+		Thresholds: thresholds,
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&budget).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditCreate, "budget", budget.ID, nil, budget)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create budget"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, budget)
+}
+
+// UpdateBudget godoc
+// @Summary Update a budget by ID
+// @Description Update the name, limit and thresholds of a budget. Thresholds already alerted this month are not alerted again.
+// @Tags Budgets
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Budget ID"
+// @Param input body models.UpdateBudget true "Update budget object"
+// @Success 200 {object} models.Budget "Successfully updated budget"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "budget not found"
+// @Router /budgets/{id} [put]
+func UpdateBudget(c *gin.Context) {
+	var budget models.Budget
+	var input models.UpdateBudget
+
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&budget).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "budget not found"})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	thresholds, err := validateLimits(input.Limit, input.Thresholds)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	before := budget
+	budget.Name = input.Name
+	budget.Limit = input.Limit
+	budget.Thresholds = thresholds
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&budget).Select("name", "limit_amount", "thresholds").Updates(budget).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditUpdate, "budget", budget.ID, before, budget)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update budget"})
+		return
+	}
+
+	c.JSON(http.StatusOK, budget)
+}
+
+// DeleteBudget godoc
+// @Summary Delete a budget by ID
+// @Description Delete a budget and the record of its alerts
+// @Tags Budgets
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Budget ID"
+// @Success 202 {object} models.Budget "Successfully deleted budget"
+// @Failure 404 {string} string "budget not found"
+// @Router /budgets/{id} [delete]
+func DeleteBudget(c *gin.Context) {
+	var budget models.Budget
+
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&budget).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "budget not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&budget).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditDelete, "budget", budget.ID, budget, nil)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete budget"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, budget)
+}
+
+// validateLimits checks the limit and returns the thresholds sorted and
+// without duplicates, or the default thresholds when none is given
+// Private function, not exposed to the API
+func validateLimits(limit money.Amount, thresholds []int) ([]int, error) {
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+	if len(thresholds) == 0 {
+		return append([]int(nil), budgeting.DefaultThresholds...), nil
+	}
+
+	sorted := append([]int(nil), thresholds...)
+	sort.Ints(sorted)
+
+	unique := []int{}
+	for i, threshold := range sorted {
+		if threshold <= 0 {
+			return nil, errors.New("thresholds must be positive percentages")
+		}
+		if i == 0 || threshold != sorted[i-1] {
+			unique = append(unique, threshold)
+		}
+	}
+
+	return unique, nil
+}
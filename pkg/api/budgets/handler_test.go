@@ -0,0 +1,106 @@
+package budgets
+
+import (
+	"bytes"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateBudget_AccountAndClient(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/budgets", CreateBudget)
+
+	// When
+	w := performRequest(r, "POST", "/budgets", `{"name":"Groceries","account":10001,"client":"John Doe","category":"groceries","limit":500}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"a budget applies to either an account or a client"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+func TestCreateBudget_InvalidThreshold(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/budgets", CreateBudget)
+
+	// When
+	w := performRequest(r, "POST", "/budgets", `{"name":"Groceries","account":10001,"category":"groceries","limit":500,"thresholds":[50,0]}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"thresholds must be positive percentages"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+func TestFindBudgetProgress_InvalidMonth(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/budgets/:id/progress", FindBudgetProgress)
+
+	// When
+	w := performRequest(r, "GET", "/budgets/1/progress?month=11-2023", "")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"Invalid month format"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+func TestFindBudgetProgress_SuccessfulRequest(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/budgets/:id/progress", FindBudgetProgress)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "budgets" WHERE id = (.+) ORDER BY "budgets"."id" LIMIT 1`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "account_id", "category", "limit_amount", "thresholds"}).
+			AddRow(1, "Groceries", 10001, "groceries", "500.00", "[80,100]"))
+	dbMock.ExpectQuery(`SELECT COALESCE\(-SUM\(amount\), 0\) FROM \(SELECT (.+) LEFT JOIN transaction_splits (.+)\) AS transactions WHERE (.+)`).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow("420.00"))
+	dbMock.ExpectQuery(`SELECT \* FROM "budget_alerts" WHERE budget_id = (.+) AND period = (.+) ORDER BY threshold`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "budget_id", "period", "threshold", "spent"}))
+
+	// When
+	w := performRequest(r, "GET", "/budgets/1/progress?month=2023-11", "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// Then
+	expected := `{"budget_id":1,"period":"2023-11","limit":500.00,"spent":420.00,"remaining":80.00,"percent":84,"crossed":[80],"alerts":[]}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	// Create a mock database for testing
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// Replace the actual database with the mock database for testing
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
+
+// performRequest performs an HTTP request and returns the response recorder.
+func performRequest(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,263 @@
+package categories
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/audit"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/splits"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @BasePath /api/v1
+
+// FindCategories godoc
+// @Summary Get all categories
+// @Description Get the catalog of transaction categories
+// @Tags Categories
+// @Security JwtAuth
+// @Produce json
+// @Success 200 {array} models.Category "Successfully retrieved list of categories"
+// @Router /categories [get]
+func FindCategories(c *gin.Context) {
+	var categories []models.Category
+
+	if err := database.DB.Order("code").Find(&categories).Error; err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// FindCategoryReport godoc
+// @Summary Total the transactions by category
+// @Description Get the debits and credits of each category, optionally restricted to an account and a date range. The lines of split transactions count in their own categories, the uncategorized transactions under a null category.
+// @Tags Categories
+// @Security JwtAuth
+// @Produce json
+// @Param account query int false "Account ID"
+// @Param from query string false "First day as YYYY-MM-DD"
+// @Param to query string false "Last day as YYYY-MM-DD"
+// @Success 200 {array} models.CategoryTotal "Totals by category"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /categories/report [get]
+func FindCategoryReport(c *gin.Context) {
+	var account *int
+	var from, to *time.Time
+
+	if value := c.Query("account"); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid account format"})
+			return
+		}
+		account = &number
+	}
+	if value := c.Query("from"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from format"})
+			return
+		}
+		from = &date
+	}
+	if value := c.Query("to"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to format"})
+			return
+		}
+		to = &date
+	}
+
+	totals, err := splits.Report(database.DB, account, from, to)
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute category report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, totals)
+}
+
+// FindCategory godoc
+// @Summary Find a category by code
+// @Description Get details of a category
+// @Tags Categories
+// @Security JwtAuth
+// @Produce json
+// @Param code path string true "Category code"
+// @Success 200 {object} models.Category "Successfully retrieved category"
+// @Failure 404 {string} string "category not found"
+// @Router /categories/{code} [get]
+func FindCategory(c *gin.Context) {
+	var category models.Category
+
+	if err := database.DB.Where("code = ?", strings.ToLower(strings.TrimSpace(c.Param("code")))).First(&category).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// CreateCategory godoc
+// @Summary Create a new category
+// @Description Add a category to the catalog, its code is what transactions reference
+// @Tags Categories
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   input     body   models.CreateCategory   true   "Create category object"
+// @Success 201 {object} models.Category "Successfully created category"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 409 {string} string "category already exists"
+// @Router /categories [post]
+func CreateCategory(c *gin.Context) {
+	var input models.CreateCategory
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category := models.Category{Code: strings.ToLower(strings.TrimSpace(input.Code)), Name: input.Name}
+
+	var count int64
+	database.DB.Model(&models.Category{}).Where("code = ?", category.Code).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "category already exists"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditCreate, "category", category.Code, nil, category)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory godoc
+// @Summary Update a category by code
+// @Description Rename a category, its code cannot change
+// @Tags Categories
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param code path string true "Category code"
+// @Param input body models.UpdateCategory true "Update category object"
+// @Success 200 {object} models.Category "Successfully updated category"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "category not found"
+// @Router /categories/{code} [put]
+func UpdateCategory(c *gin.Context) {
+	var category models.Category
+	var input models.UpdateCategory
+
+	if err := database.DB.Where("code = ?", strings.ToLower(strings.TrimSpace(c.Param("code")))).First(&category).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	before := category
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&category).Update("name", input.Name).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditUpdate, "category", category.Code, before, category)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory godoc
+// @Summary Delete a category by code
+// @Description Remove a category from the catalog. The fees category and the categories used by transactions or their splits, by rules or by budgets cannot be deleted.
+// @Tags Categories
+// @Security JwtAuth
+// @Produce json
+// @Param code path string true "Category code"
+// @Success 202 {object} models.Category "Successfully deleted category"
+// @Failure 404 {string} string "category not found"
+// @Failure 409 {string} string "category is reserved or used by transactions, rules or budgets"
+// @Router /categories/{code} [delete]
+func DeleteCategory(c *gin.Context) {
+	var category models.Category
+
+	if err := database.DB.Where("code = ?", strings.ToLower(strings.TrimSpace(c.Param("code")))).First(&category).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+		return
+	}
+
+	// The fees are posted in their category, whether or not any was charged yet
+	if category.Code == models.FeeCategory {
+		c.JSON(http.StatusConflict, gin.H{"error": "category is reserved for the fees"})
+		return
+	}
+
+	var count int64
+	database.DB.Model(&models.Transaction{}).Where("category = ?", category.Code).Count(&count)
+	if count == 0 {
+		database.DB.Model(&models.TransactionSplit{}).Where("category = ?", category.Code).Count(&count)
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "category is used by transactions"})
+		return
+	}
+
+	database.DB.Model(&models.Rule{}).Where("category = ?", category.Code).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "category is used by rules"})
+		return
+	}
+
+	database.DB.Model(&models.Budget{}).Where("category = ?", category.Code).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "category is used by budgets"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&category).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditDelete, "category", category.Code, category, nil)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, category)
+}
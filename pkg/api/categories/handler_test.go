@@ -0,0 +1,159 @@
+package categories
+
+import (
+	"bytes"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFindCategory_NotFound(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/categories/:code", FindCategory)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "categories" WHERE code = (.+) ORDER BY "categories"."code" LIMIT 1`).
+		WithArgs("travel").
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	// When
+	w := performRequest(r, "GET", "/categories/travel")
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	// Then
+	expected := `{"error":"category not found"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+func TestDeleteCategory_InUse(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.DELETE("/categories/:code", DeleteCategory)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "categories" WHERE code = (.+) ORDER BY "categories"."code" LIMIT 1`).
+		WithArgs("groceries").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "name"}).AddRow(2, "groceries", "Groceries"))
+	dbMock.ExpectQuery(`SELECT count\(\*\) FROM "transactions" WHERE category = (.+)`).
+		WithArgs("groceries").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	// When
+	w := performRequest(r, "DELETE", "/categories/groceries")
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// Then
+	expected := `{"error":"category is used by transactions"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestDeleteCategory_UsedByRules(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.DELETE("/categories/:code", DeleteCategory)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "categories" WHERE code = (.+) ORDER BY "categories"."code" LIMIT 1`).
+		WithArgs("groceries").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "name"}).AddRow(2, "groceries", "Groceries"))
+	expectCount(dbMock, "transactions", 0)
+	expectCount(dbMock, "transaction_splits", 0)
+	expectCount(dbMock, "rules", 1)
+
+	// When
+	w := performRequest(r, "DELETE", "/categories/groceries")
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// Then
+	expected := `{"error":"category is used by rules"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestDeleteCategory_UsedByBudgets(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.DELETE("/categories/:code", DeleteCategory)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "categories" WHERE code = (.+) ORDER BY "categories"."code" LIMIT 1`).
+		WithArgs("groceries").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "name"}).AddRow(2, "groceries", "Groceries"))
+	expectCount(dbMock, "transactions", 0)
+	expectCount(dbMock, "transaction_splits", 0)
+	expectCount(dbMock, "rules", 0)
+	expectCount(dbMock, "budgets", 2)
+
+	// When
+	w := performRequest(r, "DELETE", "/categories/groceries")
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// Then
+	expected := `{"error":"category is used by budgets"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestDeleteCategory_Fees(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.DELETE("/categories/:code", DeleteCategory)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "categories" WHERE code = (.+) ORDER BY "categories"."code" LIMIT 1`).
+		WithArgs(models.FeeCategory).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "name"}).AddRow(9, models.FeeCategory, "Fees"))
+
+	// When
+	w := performRequest(r, "DELETE", "/categories/fees")
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// Then
+	expected := `{"error":"category is reserved for the fees"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// expectCount expects the rows of the table using the groceries category to be counted
+func expectCount(dbMock sqlmock.Sqlmock, table string, count int) {
+	dbMock.ExpectQuery(`SELECT count\(\*\) FROM "` + table + `" WHERE category = (.+)`).
+		WithArgs("groceries").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	// Create a mock database for testing
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// Replace the actual database with the mock database for testing
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
+
+// performRequest performs an HTTP request and returns the response recorder.
+func performRequest(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(nil))
+	router.ServeHTTP(w, req)
+	return w
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/wjoseperez20/zenwallet/pkg/database"
 	"github.com/wjoseperez20/zenwallet/pkg/gmail"
 	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"github.com/wjoseperez20/zenwallet/pkg/splits"
 	"gopkg.in/gomail.v2"
 	"html/template"
 	"log"
@@ -46,6 +48,12 @@ func SendAccountStatementEmail(c *gin.Context) {
 		return
 	}
 
+	// Split transactions are reported by the categories of their splits
+	if err := splits.Load(database.DB, transactions); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load splits"})
+		return
+	}
+
 	// Send the email
 	if err := sendEmail(account, transactions); err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Failed to send email"})
@@ -65,17 +73,32 @@ func sendEmail(account models.Account, transactions []models.Transaction) error
 
 	// Calculate Statement Account
 	transactionsByMonth := make(map[string]int)
-	var totalDebit float32
+	var totalDebit money.Amount
 	var countDebit int
-	var totalCredit float32
+	var totalCredit money.Amount
 	var countCredit int
+	var totalFees money.Amount
+	var countFees int
+	spendingByCategory := make(map[string]money.Amount)
 	for _, transaction := range transactions {
 		month := transaction.Date.Format("January 2006")
 		transactionsByMonth[month]++
 
-		if transaction.Amount < 0 {
+		// Fees are reported apart from the debits of the client
+		if transaction.Fee {
+			totalFees += transaction.Amount
+			countFees++
+		} else if transaction.Amount < 0 {
 			totalDebit += transaction.Amount
 			countDebit++
+
+			for _, line := range splits.LinesOf(transaction) {
+				category := line.Category
+				if category == "" {
+					category = "uncategorized"
+				}
+				spendingByCategory[category] -= line.Amount
+			}
 		} else {
 			totalCredit += transaction.Amount
 			countCredit++
@@ -94,11 +117,14 @@ func sendEmail(account models.Account, transactions []models.Transaction) error
 	accountStatementData := map[string]interface{}{
 		"Username":                account.Client,
 		"TotalBalance":            account.Balance,
-		"AverageDebit":            totalDebit / float32(countDebit),
+		"AverageDebit":            totalDebit.Div(int64(countDebit)),
 		"DebitCount":              countDebit,
-		"AverageCredit":           totalCredit / float32(countCredit),
+		"AverageCredit":           totalCredit.Div(int64(countCredit)),
 		"CreditCount":             countCredit,
+		"TotalFees":               totalFees.Abs(),
+		"FeeCount":                countFees,
 		"TransactionCountByMonth": transactionsByMonth,
+		"SpendingByCategory":      spendingByCategory,
 	}
 
 	m := gomail.NewMessage()
@@ -0,0 +1,220 @@
+package fees
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/audit"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/fees"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @BasePath /api/v1
+
+// FindFeeSchedules godoc
+// @Summary Get all fee schedules with pagination
+// @Description Get a list of fee schedules
+// @Tags Fees
+// @Security JwtAuth
+// @Produce json
+// @Param offset query int false "Offset for pagination" default(0)
+// @Param limit query int false "Limit for pagination" default(10)
+// @Success 200 {array} models.FeeSchedule "Successfully retrieved list of fee schedules"
+// @Router /fees [get]
+func FindFeeSchedules(c *gin.Context) {
+	var schedules []models.FeeSchedule
+
+	// Convert query params to integers
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset format"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit format"})
+		return
+	}
+
+	if err := database.DB.Order("id").Offset(offset).Limit(limit).Find(&schedules).Error; err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fee schedules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// FindFeeSchedule godoc
+// @Summary Find a fee schedule by ID
+// @Description Get details of a fee schedule
+// @Tags Fees
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Fee schedule ID"
+// @Success 200 {object} models.FeeSchedule "Successfully retrieved fee schedule"
+// @Failure 404 {string} string "fee schedule not found"
+// @Router /fees/{id} [get]
+func FindFeeSchedule(c *gin.Context) {
+	var schedule models.FeeSchedule
+
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&schedule).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "fee schedule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// CreateFeeSchedule godoc
+// @Summary Create a fee schedule
+// @Description Create a fee schedule with a monthly maintenance fee, a fee per debit and a percentage fee, in basis points, on the debits above a threshold. Fees left empty are not charged, and none is charged while the balance is at least the waiver balance.
+// @Tags Fees
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   input     body   models.CreateFeeSchedule   true   "Create fee schedule object"
+// @Success 201 {object} models.FeeSchedule "Successfully created fee schedule"
+// @Failure 400 {string} string "Bad Request"
+// @Router /fees [post]
+func CreateFeeSchedule(c *gin.Context) {
+	var input models.CreateFeeSchedule
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule := models.FeeSchedule{
+		Name:                input.Name,
+		MonthlyFee:          input.MonthlyFee,
+		TransactionFee:      input.TransactionFee,
+		PercentageFeeBps:    input.PercentageFeeBps,
+		PercentageThreshold: input.PercentageThreshold,
+		WaiverBalance:       input.WaiverBalance,
+	}
+	if err := fees.Validate(schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&schedule).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditCreate, "fee_schedule", schedule.ID, nil, schedule)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create fee schedule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// UpdateFeeSchedule godoc
+// @Summary Update a fee schedule by ID
+// @Description Replace the fees of a fee schedule. The new fees apply to the accounts it is attached to from the next charge on.
+// @Tags Fees
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Fee schedule ID"
+// @Param input body models.UpdateFeeSchedule true "Update fee schedule object"
+// @Success 200 {object} models.FeeSchedule "Successfully updated fee schedule"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "fee schedule not found"
+// @Router /fees/{id} [put]
+func UpdateFeeSchedule(c *gin.Context) {
+	var schedule models.FeeSchedule
+	var input models.UpdateFeeSchedule
+
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&schedule).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "fee schedule not found"})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	before := schedule
+	schedule.Name = input.Name
+	schedule.MonthlyFee = input.MonthlyFee
+	schedule.TransactionFee = input.TransactionFee
+	schedule.PercentageFeeBps = input.PercentageFeeBps
+	schedule.PercentageThreshold = input.PercentageThreshold
+	schedule.WaiverBalance = input.WaiverBalance
+	if err := fees.Validate(schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&schedule).
+			Select("name", "monthly_fee", "transaction_fee", "percentage_fee_bps", "percentage_threshold", "waiver_balance").
+			Updates(schedule).Error
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditUpdate, "fee_schedule", schedule.ID, before, schedule)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update fee schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteFeeSchedule godoc
+// @Summary Delete a fee schedule by ID
+// @Description Delete a fee schedule that is not attached to any account
+// @Tags Fees
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Fee schedule ID"
+// @Success 202 {object} models.FeeSchedule "Successfully deleted fee schedule"
+// @Failure 404 {string} string "fee schedule not found"
+// @Failure 409 {string} string "fee schedule is attached to accounts"
+// @Router /fees/{id} [delete]
+func DeleteFeeSchedule(c *gin.Context) {
+	var schedule models.FeeSchedule
+
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&schedule).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "fee schedule not found"})
+		return
+	}
+
+	var attached int64
+	database.DB.Model(&models.AccountFee{}).Where("fee_schedule_id = ?", schedule.ID).Count(&attached)
+	if attached > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "fee schedule is attached to accounts"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&schedule).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditDelete, "fee_schedule", schedule.ID, schedule, nil)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete fee schedule"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, schedule)
+}
@@ -0,0 +1,84 @@
+package fees
+
+import (
+	"bytes"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateFeeSchedule_InvalidPercentage(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/fees", CreateFeeSchedule)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+
+	// When
+	w := performRequest(r, "POST", "/fees", []byte(`{"name": "premium", "percentage_fee_bps": 12000}`))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"percentage_fee_bps must be between 1 and 10000"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestDeleteFeeSchedule_Attached(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.DELETE("/fees/:id", DeleteFeeSchedule)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "fee_schedules" WHERE id = (.+) ORDER BY "fee_schedules"."id" LIMIT 1`).
+		WithArgs("3").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "basic"))
+	dbMock.ExpectQuery(`SELECT count\(\*\) FROM "account_fees" WHERE fee_schedule_id = (.+)`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	// When
+	w := performRequest(r, "DELETE", "/fees/3")
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// Then
+	expected := `{"error":"fee schedule is attached to accounts"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	// Create a mock database for testing
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// Replace the actual database with the mock database for testing
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
+
+// performRequest performs an HTTP request and returns the response recorder.
+func performRequest(router *gin.Engine, method, path string, requestBody ...[]byte) *httptest.ResponseRecorder {
+	var body []byte
+	if len(requestBody) > 0 {
+		body = requestBody[0]
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	return w
+}
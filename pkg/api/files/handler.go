@@ -3,20 +3,30 @@ package files
 import (
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/gin-gonic/gin"
 	"github.com/wjoseperez20/zenwallet/pkg/amazon"
+	"github.com/wjoseperez20/zenwallet/pkg/audit"
 	"github.com/wjoseperez20/zenwallet/pkg/cache"
+	"github.com/wjoseperez20/zenwallet/pkg/classifier"
+	"github.com/wjoseperez20/zenwallet/pkg/closing"
 	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/ledger"
 	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"github.com/wjoseperez20/zenwallet/pkg/pagination"
+	"github.com/wjoseperez20/zenwallet/pkg/posting"
+	"gorm.io/gorm"
 	"io"
 	"log"
 	"mime/multipart"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -25,71 +35,74 @@ const (
 	s3BucketFolder = "files"
 )
 
+// fileOrder lists the files in upload order
+var fileOrder = pagination.Order{Key: "id"}
+
 // @BasePath /api/v1
 
 // FindFiles godoc
-// @Summary Get all files with pagination
-// @Description Get a list of all files with optional pagination
+// @Summary Get all files with cursor pagination
+// @Description Get a page of files, oldest first. Pass the next_cursor of a page as cursor to get the next one.
 // @Tags Files
 // @Security JwtAuth
 // @Accept json
 // @Produce json
-// @Param offset query int false "Offset for pagination" default(0)
-// @Param limit query int false "Limit for pagination" default(10)
-// @Success 200 {array} models.File "Successfully retrieved list of files"
+// @Param cursor query string false "Cursor returned as next_cursor with the previous page"
+// @Param limit query int false "Page size, at most 100" default(10)
+// @Param total query bool false "Include the number of files" default(false)
+// @Success 200 {object} pagination.Page[models.File] "Successfully retrieved page of files"
+// @Failure 400 {string} string "Bad Request"
 // @Router /files [get]
 func FindFiles(c *gin.Context) {
-	var files []models.File
-
-	// Get query params
-	offsetQuery := c.DefaultQuery("offset", "0")
-	limitQuery := c.DefaultQuery("limit", "10")
-
-	// Convert query params to integers
-	offset, err := strconv.Atoi(offsetQuery)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset format"})
-		return
-	}
+	var page pagination.Page[models.File]
 
-	limit, err := strconv.Atoi(limitQuery)
+	params, err := pagination.Parse(c.Request.URL.Query())
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit format"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Create a cache key based on query params
-	cacheKey := "files_offset_" + offsetQuery + "_limit_" + limitQuery
+	cacheKey := "files_page_" + params.Key()
 
 	// Try fetching the data from Redis first
-	cachedFiles, err := cache.Rdb.Get(cache.Ctx, cacheKey).Result()
+	cachedPage, err := cache.Rdb.Get(cache.Ctx, cacheKey).Result()
 	if err == nil {
-		err := json.Unmarshal([]byte(cachedFiles), &files)
+		err := json.Unmarshal([]byte(cachedPage), &page)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal cached data"})
 			return
 		}
-		c.JSON(http.StatusOK, files)
+		c.JSON(http.StatusOK, page)
 		return
 	}
 
 	// If cache missed, fetch data from the database
-	database.DB.Offset(offset).Limit(limit).Find(&files)
+	page, err = pagination.Fetch(database.DB.Model(&models.File{}), params, fileOrder, fileCursor)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch files"})
+		return
+	}
 
-	// Serialize files object and store it in Redis
-	serializedFiles, err := json.Marshal(files)
+	// Serialize the page and store it in Redis
+	serializedPage, err := json.Marshal(page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal data"})
 		return
 	}
-	err = cache.Rdb.Set(cache.Ctx, cacheKey, serializedFiles, time.Minute).Err() // Here TTL is set to one hour
+	err = cache.Rdb.Set(cache.Ctx, cacheKey, serializedPage, time.Minute).Err() // Here TTL is set to one hour
 	if err != nil {
 		log.Default().Println(err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set cache"})
 		return
 	}
 
-	c.JSON(http.StatusOK, files)
+	c.JSON(http.StatusOK, page)
 }
 
 // FindFile godoc
@@ -152,7 +165,17 @@ func UploadFile(c *gin.Context) {
 	fileObj := models.File{Name: fileName, Location: "S3"}
 
 	// Save the file object to the database
-	database.DB.Create(&fileObj)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&fileObj).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditCreate, "file", fileObj.ID, nil, fileObj)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "File uploaded successfully"})
 }
@@ -164,10 +187,13 @@ func UploadFile(c *gin.Context) {
 // @Security JwtAuth
 // @Accept  json
 // @Produce  json
+// @Param   Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Param   input     body   models.ProcessFile   true   "Upload file"
 // @Success 201 {object} models.File "Successfully processed file"
 // @Failure 400 {string} string "Bad Request"
 // @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "account status or a closed period rejects a transaction, or request with the same Idempotency-Key in progress"
+// @Failure 422 {string} string "a transaction was rejected by the account policy or Idempotency-Key reused with a different request"
 // @Router /files/process [post]
 func ProcessFile(c *gin.Context) {
 	var input models.ProcessFile
@@ -203,14 +229,28 @@ func ProcessFile(c *gin.Context) {
 	}
 
 	// Process the file
-	err = insertTransactions(file.Name)
+	err = insertTransactions(c, file.Name)
 	if err != nil {
 		database.DB.Model(&file).Updates(models.File{Output: err.Error()})
 
+		var violation *ledger.PolicyViolation
+		if errors.As(err, &violation) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "reason": violation})
+			return
+		}
+		if errors.Is(err, ledger.ErrAccountFrozen) || errors.Is(err, ledger.ErrAccountDormant) || errors.Is(err, ledger.ErrAccountClosed) ||
+			errors.Is(err, closing.ErrPeriodClosed) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// The transactions are posted, invalidate the cached lists they appear in
+	invalidateCache("transactions_page_*", "account_transactions_page_*", "accounts_page_*")
+
 	// Delete the file from the server
 	err = os.Remove(file.Name)
 	if err != nil {
@@ -219,11 +259,38 @@ func ProcessFile(c *gin.Context) {
 	}
 
 	// Update the file object in the database
-	database.DB.Model(&file).Updates(models.File{Processed: true})
+	before := file
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&file).Updates(models.File{Processed: true}).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditProcess, "file", file.ID, before, file)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Invalidate cache
+	invalidateCache("files_page_*")
 
 	c.JSON(http.StatusOK, gin.H{"message": "File processed successfully"})
 }
 
+// invalidateCache removes the cached lists matching the given patterns
+// Private function, not exposed to the API
+func invalidateCache(keysPatterns ...string) {
+	for _, keysPattern := range keysPatterns {
+		keys, err := cache.Rdb.Keys(cache.Ctx, keysPattern).Result()
+		if err == nil {
+			for _, key := range keys {
+				cache.Rdb.Del(cache.Ctx, key)
+			}
+		}
+	}
+}
+
 // uploadToS3 godoc
 // @Summary Upload a file
 // Private function to upload a file to S3
@@ -279,33 +346,40 @@ func downloadFromS3(svc *s3.S3, fileName string) error {
 // processFile godoc
 // @Summary Process a file
 // Private function to process a file
-func insertTransactions(fileName string) error {
+func insertTransactions(c *gin.Context, fileName string) error {
 	// process csv file
 	csvTransactions, err := readCSV(fileName)
 	if err != nil {
 		return err
 	}
 
-	// save transactions to database
-	for _, input := range csvTransactions {
-
-		// check if account exists
-		var account models.Account
-		if err := database.DB.Where("account = ?", input.Account).First(&account).Error; err != nil {
-			return fmt.Errorf("account %d not found", input.Account)
+	// save transactions to database, either all of them or none
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		engine, err := classifier.Load(tx)
+		if err != nil {
+			return err
 		}
 
-		// Create a transaction object
-		transaction := models.Transaction{Account: input.Account, Date: input.Date, Amount: input.Amount}
-
-		// Save the transaction object to the database
-		database.DB.Create(&transaction)
-
-		// Update account balance
-		updateAccountBalance(uint(transaction.Account), transaction.Amount)
-	}
+		for i, input := range csvTransactions {
+			// Copy the transaction read from the file, categorize and post it with its fees
+			transaction := input
+			err := posting.PostWith(tx, engine, &transaction, func(tx *gorm.DB, created models.Transaction) error {
+				return audit.Record(tx, c, models.AuditCreate, "transaction", created.ID, nil, created)
+			})
+			if err != nil {
+				if errors.Is(err, ledger.ErrAccountNotFound) {
+					return fmt.Errorf("account %d not found", input.Account)
+				}
+				if errors.Is(err, ledger.ErrCategoryNotFound) {
+					return fmt.Errorf("category %s not found", *transaction.Category)
+				}
+				// Lines are counted from the header
+				return fmt.Errorf("line %d: %w", i+2, err)
+			}
+		}
 
-	return nil
+		return nil
+	})
 }
 
 // readCSV godoc
@@ -344,15 +418,21 @@ func readCSV(filename string) ([]models.Transaction, error) {
 			return nil, err
 		}
 
-		amount, err := strconv.ParseFloat(record[3], 64)
+		amount, err := money.Parse(record[3])
 		if err != nil {
 			return nil, err
 		}
 
 		transaction := models.Transaction{
-			Account: int(account),
-			Date:    date,
-			Amount:  float32(amount),
+			Account:  int(account),
+			Date:     date,
+			Amount:   amount,
+			Currency: models.DefaultCurrency,
+		}
+
+		// The columns after the amount are optional
+		if err := readDetails(record, &transaction); err != nil {
+			return nil, err
 		}
 
 		transactions = append(transactions, transaction)
@@ -362,13 +442,39 @@ func readCSV(filename string) ([]models.Transaction, error) {
 	return transactions, nil
 }
 
-// updateAccountBalance updates the balance of the given account
-// by adding the given amount to the current balance
-// Private function, not exposed to the API
-func updateAccountBalance(account uint, amount float32) {
-	var accountToUpdate models.Account
+// readDetails godoc
+// @Summary Read the optional columns of a CSV record
+// Private function to read the currency, description, counterparty,
+// category, external reference and JSON metadata of a transaction
+func readDetails(record []string, transaction *models.Transaction) error {
+	column := func(i int) string {
+		if i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	if currency := column(4); currency != "" {
+		transaction.Currency = strings.ToUpper(currency)
+	}
+	transaction.Description = column(5)
+	transaction.Counterparty = column(6)
+	if category := strings.ToLower(column(7)); category != "" {
+		transaction.Category = &category
+	}
+	transaction.ExternalReference = column(8)
 
-	database.DB.Where("account = ?", account).First(&accountToUpdate)
-	accountToUpdate.Balance = accountToUpdate.Balance + amount
-	database.DB.Save(&accountToUpdate)
+	if metadata := column(9); metadata != "" {
+		if err := json.Unmarshal([]byte(metadata), &transaction.Metadata); err != nil {
+			return fmt.Errorf("invalid metadata %q: %w", metadata, err)
+		}
+	}
+
+	return nil
+}
+
+// fileCursor returns the position of a file in fileOrder
+// Private function, not exposed to the API
+func fileCursor(file models.File) pagination.Cursor {
+	return pagination.Cursor{ID: int(file.ID)}
 }
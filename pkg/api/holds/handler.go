@@ -0,0 +1,303 @@
+package holds
+
+import (
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/audit"
+	"github.com/wjoseperez20/zenwallet/pkg/cache"
+	"github.com/wjoseperez20/zenwallet/pkg/closing"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/ledger"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultHoldDuration is used when a hold is created without expiration
+const defaultHoldDuration = 7 * 24 * time.Hour
+
+// @BasePath /api/v1
+
+// FindHold godoc
+// @Summary Find a hold by ID
+// @Description Get details of an authorization hold
+// @Tags Holds
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Success 200 {object} models.Hold "Successfully retrieved hold"
+// @Failure 404 {string} string "hold not found"
+// @Router /holds/{id} [get]
+func FindHold(c *gin.Context) {
+	var hold models.Hold
+
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&hold).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "hold not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+// FindHolds godoc
+// @Summary Get all holds with pagination
+// @Description Get a list of holds, optionally restricted to an account and a status
+// @Tags Holds
+// @Security JwtAuth
+// @Produce json
+// @Param account query int false "Account number"
+// @Param status query string false "Hold status" Enums(pending, captured, released, expired)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Param limit query int false "Limit for pagination" default(10)
+// @Success 200 {array} models.Hold "Successfully retrieved list of holds"
+// @Router /holds [get]
+func FindHolds(c *gin.Context) {
+	var holds []models.Hold
+
+	// Convert query params to integers
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset format"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit format"})
+		return
+	}
+
+	// Holds change status on their own when they expire, they are not cached
+	query := database.DB.Order("id").Offset(offset).Limit(limit)
+	if account := c.Query("account"); account != "" {
+		query = query.Where("account_id = ?", account)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Find(&holds).Error; err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch holds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, holds)
+}
+
+// CreateHold godoc
+// @Summary Authorize a hold on an account
+// @Description Reserve an amount of the account. The available balance decreases, the balance does not change until the hold is captured.
+// @Tags Holds
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Param   input     body   models.CreateHold   true   "Create hold object, expires_at is RFC 3339 and defaults to 7 days"
+// @Success 201 {object} models.Hold "Successfully created hold"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "account not found"
+// @Failure 409 {string} string "account status rejects debits or request with the same Idempotency-Key in progress"
+// @Failure 422 {string} string "rejected by the account policy or Idempotency-Key reused with a different request"
+// @Router /holds [post]
+func CreateHold(c *gin.Context) {
+	var input models.CreateHold
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be positive"})
+		return
+	}
+
+	expiresAt := time.Now().UTC().Add(defaultHoldDuration)
+	if input.ExpiresAt != "" {
+		var err error
+		expiresAt, err = time.Parse(time.RFC3339, input.ExpiresAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid expires_at format"})
+			return
+		}
+		if !expiresAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+			return
+		}
+	}
+
+	hold := models.Hold{Account: input.Account, Amount: input.Amount, Description: input.Description, ExpiresAt: expiresAt}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := ledger.Authorize(tx, &hold); err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditCreate, "hold", hold.ID, nil, hold)
+	})
+	if err != nil {
+		respondHoldError(c, err)
+		return
+	}
+
+	invalidateCache("accounts_page_*")
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// CaptureHold godoc
+// @Summary Capture a hold by ID
+// @Description Debit the account with the held amount, or with a smaller amount which releases the rest of the hold
+// @Tags Holds
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Param id path string true "Hold ID"
+// @Param   input     body   models.CaptureHold   false   "Capture object, amount defaults to the held amount and date to today"
+// @Success 201 {object} models.Transaction "Successfully created transaction"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "hold not found"
+// @Failure 409 {string} string "hold is no longer pending, account status rejects debits or period closed"
+// @Failure 422 {string} string "capture amount exceeds the held amount"
+// @Router /holds/{id}/capture [post]
+func CaptureHold(c *gin.Context) {
+	var input models.CaptureHold
+
+	// The body is optional, an empty one captures the whole hold today
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	if input.Date != "" {
+		var err error
+		date, err = time.Parse("2006-01-02", input.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+			return
+		}
+	}
+
+	var transaction *models.Transaction
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		hold, err := lockHold(tx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+
+		before := *hold
+		if transaction, err = ledger.Capture(tx, hold, input.Amount, date); err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditCapture, "hold", hold.ID, before, hold)
+	})
+	if err != nil {
+		respondHoldError(c, err)
+		return
+	}
+
+	invalidateCache("accounts_page_*", "transactions_page_*", "account_transactions_page_*")
+
+	c.JSON(http.StatusCreated, transaction)
+}
+
+// ReleaseHold godoc
+// @Summary Release a hold by ID
+// @Description Cancel a pending hold and give its amount back to the available balance
+// @Tags Holds
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Hold ID"
+// @Success 200 {object} models.Hold "Successfully released hold"
+// @Failure 404 {string} string "hold not found"
+// @Failure 409 {string} string "hold is no longer pending"
+// @Router /holds/{id}/release [post]
+func ReleaseHold(c *gin.Context) {
+	var hold *models.Hold
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		hold, err = lockHold(tx, c.Param("id"))
+		if err != nil {
+			return err
+		}
+
+		before := *hold
+		if err := ledger.Release(tx, hold); err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditRelease, "hold", hold.ID, before, hold)
+	})
+	if err != nil {
+		respondHoldError(c, err)
+		return
+	}
+
+	invalidateCache("accounts_page_*")
+
+	c.JSON(http.StatusOK, hold)
+}
+
+// lockHold loads a hold with a row lock
+// Private function, not exposed to the API
+func lockHold(tx *gorm.DB, id string) (*models.Hold, error) {
+	var hold models.Hold
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&hold).Error; err != nil {
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+// respondHoldError maps an error returned by the ledger to an HTTP response
+// Private function, not exposed to the API
+func respondHoldError(c *gin.Context, err error) {
+	var violation *ledger.PolicyViolation
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "hold not found"})
+	case errors.Is(err, ledger.ErrAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+	case errors.Is(err, ledger.ErrAccountFrozen), errors.Is(err, ledger.ErrAccountDormant), errors.Is(err, ledger.ErrAccountClosed),
+		errors.Is(err, closing.ErrPeriodClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &violation):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": violation.Error(), "reason": violation})
+	case errors.Is(err, ledger.ErrCaptureExceedsHold):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, ledger.ErrHoldNotPending), errors.Is(err, ledger.ErrConcurrentUpdate):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update hold"})
+	}
+}
+
+// invalidateCache removes the cached lists matching the given patterns
+// Private function, not exposed to the API
+func invalidateCache(keysPatterns ...string) {
+	for _, keysPattern := range keysPatterns {
+		keys, err := cache.Rdb.Keys(cache.Ctx, keysPattern).Result()
+		if err == nil {
+			for _, key := range keys {
+				cache.Rdb.Del(cache.Ctx, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package holds
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFindHold_NotFound(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/holds/:id", FindHold)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "holds" WHERE id = (.+) ORDER BY "holds"."id" LIMIT 1`).
+		WithArgs("999").
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	// When
+	w := performRequest(r, "GET", "/holds/999")
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	// Then
+	expected := `{"error":"hold not found"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+func TestCreateHold_InsufficientFunds(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/holds", CreateHold)
+
+	incomingHold := models.CreateHold{Account: 10001, Amount: money.MustParse("50")}
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1 FOR UPDATE`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account", "balance", "available_balance"}).AddRow(10001, "80.00", "49.99"))
+	dbMock.ExpectQuery(`SELECT \* FROM "account_policies" WHERE account_id = (.+) LIMIT 1`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "overdraft_limit"}))
+	dbMock.ExpectRollback()
+
+	// When
+	w := performRequest(r, "POST", "/holds", toJSON(incomingHold))
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// Then
+	expected := `{"error":"insufficient funds","reason":{"account":10001,"rule":"overdraft_limit","limit":0.00,"attempted":0.01}}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCreateHold_ExpiredInThePast(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/holds", CreateHold)
+
+	incomingHold := models.CreateHold{Account: 10001, Amount: money.MustParse("50"), ExpiresAt: "2023-11-25T00:00:00Z"}
+
+	// When
+	w := performRequest(r, "POST", "/holds", toJSON(incomingHold))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"expires_at must be in the future"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+func TestCaptureHold_ExceedsHold(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/holds/:id/capture", CaptureHold)
+
+	incomingCapture := models.CaptureHold{Amount: money.MustParse("60")}
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	expectLockedHold(dbMock, models.HoldPending)
+	dbMock.ExpectRollback()
+
+	// When
+	w := performRequest(r, "POST", "/holds/5/capture", toJSON(incomingCapture))
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// Then
+	expected := `{"error":"capture amount exceeds the held amount"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReleaseHold_NotPending(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/holds/:id/release", ReleaseHold)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	expectLockedHold(dbMock, models.HoldExpired)
+	dbMock.ExpectRollback()
+
+	// When
+	w := performRequest(r, "POST", "/holds/5/release")
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// Then
+	expected := `{"error":"hold is no longer pending"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// expectLockedHold expects hold 5 of 50.00 on account 10001 to be locked
+func expectLockedHold(dbMock sqlmock.Sqlmock, status string) {
+	dbMock.ExpectQuery(`SELECT \* FROM "holds" WHERE id = (.+) ORDER BY "holds"."id" LIMIT 1 FOR UPDATE`).
+		WithArgs("5").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "amount", "status", "expires_at"}).
+			AddRow(5, 10001, "50.00", status, time.Now().Add(time.Hour)))
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	// Create a mock database for testing
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// Replace the actual database with the mock database for testing
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
+
+// performRequest performs an HTTP request and returns the response recorder.
+func performRequest(router *gin.Engine, method, path string, requestBody ...[]byte) *httptest.ResponseRecorder {
+	var reqBody []byte
+	if len(requestBody) > 0 {
+		reqBody = requestBody[0]
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func toJSON(v interface{}) []byte {
+	result, _ := json.Marshal(v)
+	return result
+}
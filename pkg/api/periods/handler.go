@@ -0,0 +1,189 @@
+package periods
+
+import (
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/audit"
+	"github.com/wjoseperez20/zenwallet/pkg/closing"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @BasePath /api/v1
+
+// FindPeriods godoc
+// @Summary Get the closed and reopened periods
+// @Description Get the days closed by the closing job, newest first, optionally restricted to a date range. The days before the first closed day are closed too but have no record.
+// @Tags Periods
+// @Security JwtAuth
+// @Produce json
+// @Param from query string false "First day as YYYY-MM-DD"
+// @Param to query string false "Last day as YYYY-MM-DD"
+// @Param status query string false "Status, closed or open"
+// @Param offset query int false "Offset for pagination" default(0)
+// @Param limit query int false "Limit for pagination" default(31)
+// @Success 200 {array} models.Period "Periods"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Router /periods [get]
+func FindPeriods(c *gin.Context) {
+	var periods []models.Period
+
+	// Convert query params to integers
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset format"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "31"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit format"})
+		return
+	}
+
+	query := database.DB.Order("date DESC").Offset(offset).Limit(limit)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if from := c.Query("from"); from != "" {
+		date, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from format"})
+			return
+		}
+		query = query.Where("date >= ?", date)
+	}
+	if to := c.Query("to"); to != "" {
+		date, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to format"})
+			return
+		}
+		query = query.Where("date <= ?", date)
+	}
+
+	if err := query.Find(&periods).Error; err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch periods"})
+		return
+	}
+
+	c.JSON(http.StatusOK, periods)
+}
+
+// ReopenPeriod godoc
+// @Summary Reopen a closed period
+// @Description Unlock a closed day so transactions dated on it can be created and changed again. The reason and the user are recorded in the period and in the audit log.
+// @Tags Periods
+// @Security JwtAuth
+// @Accept json
+// @Produce json
+// @Param date path string true "Day as YYYY-MM-DD"
+// @Param input body models.ReopenPeriod true "Reason for the reopening"
+// @Success 200 {object} models.Period "Reopened period"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "period is not closed yet or already reopened"
+// @Router /periods/{date}/reopen [post]
+func ReopenPeriod(c *gin.Context) {
+	var input models.ReopenPeriod
+	var period models.Period
+
+	day, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		found, err := closing.Find(tx, day)
+		if err != nil {
+			return err
+		}
+		before := found
+		period = found
+
+		if err := closing.Reopen(tx, &period, input.Reason, c.GetString("username")); err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditReopen, "period", day.Format("2006-01-02"), before, period)
+	})
+	if err != nil {
+		respondPeriodError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, period)
+}
+
+// ClosePeriod godoc
+// @Summary Close a reopened period again
+// @Description Lock a reopened day again and snapshot its balances, and those of the closed days following it
+// @Tags Periods
+// @Security JwtAuth
+// @Produce json
+// @Param date path string true "Day as YYYY-MM-DD"
+// @Success 200 {object} models.Period "Closed period"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "period is not closed yet or already closed"
+// @Router /periods/{date}/close [post]
+func ClosePeriod(c *gin.Context) {
+	var period models.Period
+
+	day, err := time.Parse("2006-01-02", c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		before, err := closing.Find(tx, day)
+		if err != nil {
+			return err
+		}
+		if before.Status == models.PeriodClosed {
+			return closing.ErrAlreadyClosed
+		}
+
+		if err := closing.Close(tx, day); err != nil {
+			return err
+		}
+		if period, err = closing.Find(tx, day); err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditClose, "period", day.Format("2006-01-02"), before, period)
+	})
+	if err != nil {
+		respondPeriodError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, period)
+}
+
+// respondPeriodError maps the errors of the closing package to HTTP responses
+// Private function, not exposed to the API
+func respondPeriodError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, closing.ErrNotClosed), errors.Is(err, closing.ErrAlreadyOpen), errors.Is(err, closing.ErrAlreadyClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update period"})
+	}
+}
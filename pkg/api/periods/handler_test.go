@@ -0,0 +1,81 @@
+package periods
+
+import (
+	"bytes"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReopenPeriod_NotClosed(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/periods/:date/reopen", ReopenPeriod)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`SELECT MAX\(date\) FROM "periods"`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC)))
+	dbMock.ExpectRollback()
+
+	// When
+	w := performRequest(r, "POST", "/periods/2024-03-31/reopen", []byte(`{"reason": "late card settlement"}`))
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// Then
+	expected := `{"error":"period is not closed yet"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReopenPeriod_MissingReason(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/periods/:date/reopen", ReopenPeriod)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+
+	// When
+	w := performRequest(r, "POST", "/periods/2024-03-12/reopen", []byte(`{}`))
+
+	// Then
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	// Create a mock database for testing
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// Replace the actual database with the mock database for testing
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
+
+// performRequest performs an HTTP request and returns the response recorder.
+func performRequest(router *gin.Engine, method, path string, requestBody ...[]byte) *httptest.ResponseRecorder {
+	var body []byte
+	if len(requestBody) > 0 {
+		body = requestBody[0]
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	return w
+}
@@ -3,10 +3,19 @@ package api
 import (
 	"github.com/wjoseperez20/zenwallet/docs"
 	"github.com/wjoseperez20/zenwallet/pkg/api/accounts"
+	"github.com/wjoseperez20/zenwallet/pkg/api/admin"
+	"github.com/wjoseperez20/zenwallet/pkg/api/budgets"
+	"github.com/wjoseperez20/zenwallet/pkg/api/categories"
 	"github.com/wjoseperez20/zenwallet/pkg/api/emails"
+	"github.com/wjoseperez20/zenwallet/pkg/api/fees"
 	"github.com/wjoseperez20/zenwallet/pkg/api/files"
 	"github.com/wjoseperez20/zenwallet/pkg/api/healtcheck"
+	"github.com/wjoseperez20/zenwallet/pkg/api/holds"
+	"github.com/wjoseperez20/zenwallet/pkg/api/periods"
+	"github.com/wjoseperez20/zenwallet/pkg/api/rules"
+	"github.com/wjoseperez20/zenwallet/pkg/api/schedules"
 	"github.com/wjoseperez20/zenwallet/pkg/api/transactions"
+	"github.com/wjoseperez20/zenwallet/pkg/api/transfers"
 	"github.com/wjoseperez20/zenwallet/pkg/api/users"
 	"github.com/wjoseperez20/zenwallet/pkg/middleware"
 	"time"
@@ -21,6 +30,7 @@ func InitRouter() *gin.Engine {
 	r := gin.Default()
 
 	r.Use(gin.Logger())
+	r.Use(middleware.RequestID())
 	if gin.Mode() == gin.ReleaseMode {
 		r.Use(middleware.Security())
 		r.Use(middleware.Xss())
@@ -42,15 +52,107 @@ func InitRouter() *gin.Engine {
 			account.POST("/", middleware.JWTAuth(), accounts.CreateAccount)
 			account.PUT("/:account", middleware.JWTAuth(), accounts.UpdateAccount)
 			account.DELETE("/:account", middleware.JWTAuth(), accounts.DeleteAccount)
+			account.GET("/:account/balance", middleware.JWTAuth(), accounts.FindAccountBalance)
+			account.GET("/:account/balance/daily", middleware.JWTAuth(), accounts.FindAccountBalanceSeries)
+			account.GET("/:account/snapshots", middleware.JWTAuth(), accounts.FindAccountSnapshots)
+			account.GET("/:account/transactions", middleware.JWTAuth(), transactions.FindAccountTransactions)
+			account.POST("/:account/transactions", middleware.JWTAuth(), middleware.Idempotency(), transactions.CreateAccountTransaction)
+			account.GET("/:account/transactions/summary", middleware.JWTAuth(), transactions.FindAccountTransactionSummary)
+			account.GET("/:account/policy", middleware.JWTAuth(), accounts.FindAccountPolicy)
+			account.PUT("/:account/policy", middleware.JWTAuth(), accounts.UpdateAccountPolicy)
+			account.GET("/:account/interest", middleware.JWTAuth(), accounts.FindAccountInterest)
+			account.PUT("/:account/interest", middleware.JWTAuth(), accounts.UpdateAccountInterest)
+			account.GET("/:account/interest/accruals", middleware.JWTAuth(), accounts.FindAccountInterestAccruals)
+			account.GET("/:account/fees", middleware.JWTAuth(), accounts.FindAccountFees)
+			account.PUT("/:account/fees", middleware.JWTAuth(), accounts.UpdateAccountFees)
+			account.DELETE("/:account/fees", middleware.JWTAuth(), accounts.DeleteAccountFees)
+			account.POST("/:account/status", middleware.JWTAuth(), accounts.UpdateAccountStatus)
+			account.GET("/:account/status/history", middleware.JWTAuth(), accounts.FindAccountStatusHistory)
 		}
 
 		transaction := v1.Group("/transactions")
 		{
 			transaction.GET("/", middleware.JWTAuth(), transactions.FindTransactions)
 			transaction.GET("/:id", middleware.JWTAuth(), transactions.FindTransaction)
-			transaction.POST("/", middleware.JWTAuth(), transactions.CreateTransaction)
+			transaction.POST("/bulk", middleware.JWTAuth(), middleware.Idempotency(), transactions.BulkCreateTransactions)
+			transaction.POST("/", middleware.JWTAuth(), middleware.Idempotency(), transactions.CreateTransaction)
 			transaction.PUT("/:id", middleware.JWTAuth(), transactions.UpdateTransaction)
-			transaction.DELETE("/:id", middleware.JWTAuth(), transactions.DeleteTransaction)
+			transaction.PUT("/:id/splits", middleware.JWTAuth(), transactions.UpdateTransactionSplits)
+			transaction.POST("/:id/reverse", middleware.JWTAuth(), transactions.ReverseTransaction)
+		}
+
+		category := v1.Group("/categories")
+		{
+			category.GET("/", middleware.JWTAuth(), categories.FindCategories)
+			category.GET("/report", middleware.JWTAuth(), categories.FindCategoryReport)
+			category.GET("/:code", middleware.JWTAuth(), categories.FindCategory)
+			category.POST("/", middleware.JWTAuth(), categories.CreateCategory)
+			category.PUT("/:code", middleware.JWTAuth(), categories.UpdateCategory)
+			category.DELETE("/:code", middleware.JWTAuth(), categories.DeleteCategory)
+		}
+
+		rule := v1.Group("/rules")
+		{
+			rule.GET("/", middleware.JWTAuth(), rules.FindRules)
+			rule.GET("/:id", middleware.JWTAuth(), rules.FindRule)
+			rule.POST("/", middleware.JWTAuth(), rules.CreateRule)
+			rule.PUT("/:id", middleware.JWTAuth(), rules.UpdateRule)
+			rule.DELETE("/:id", middleware.JWTAuth(), rules.DeleteRule)
+			rule.POST("/dry-run", middleware.JWTAuth(), rules.DryRunRule)
+			rule.POST("/apply", middleware.JWTAuth(), rules.ApplyRules)
+		}
+
+		budget := v1.Group("/budgets")
+		{
+			budget.GET("/", middleware.JWTAuth(), budgets.FindBudgets)
+			budget.GET("/:id", middleware.JWTAuth(), budgets.FindBudget)
+			budget.GET("/:id/progress", middleware.JWTAuth(), budgets.FindBudgetProgress)
+			budget.POST("/", middleware.JWTAuth(), budgets.CreateBudget)
+			budget.PUT("/:id", middleware.JWTAuth(), budgets.UpdateBudget)
+			budget.DELETE("/:id", middleware.JWTAuth(), budgets.DeleteBudget)
+		}
+
+		fee := v1.Group("/fees")
+		{
+			fee.GET("/", middleware.JWTAuth(), fees.FindFeeSchedules)
+			fee.GET("/:id", middleware.JWTAuth(), fees.FindFeeSchedule)
+			fee.POST("/", middleware.JWTAuth(), fees.CreateFeeSchedule)
+			fee.PUT("/:id", middleware.JWTAuth(), fees.UpdateFeeSchedule)
+			fee.DELETE("/:id", middleware.JWTAuth(), fees.DeleteFeeSchedule)
+		}
+
+		period := v1.Group("/periods")
+		{
+			period.GET("/", middleware.JWTAuth(), periods.FindPeriods)
+			period.POST("/:date/reopen", middleware.JWTAuth(), periods.ReopenPeriod)
+			period.POST("/:date/close", middleware.JWTAuth(), periods.ClosePeriod)
+		}
+
+		transfer := v1.Group("/transfers")
+		{
+			transfer.GET("/", middleware.JWTAuth(), transfers.FindTransfers)
+			transfer.GET("/:id", middleware.JWTAuth(), transfers.FindTransfer)
+			transfer.POST("/", middleware.JWTAuth(), middleware.Idempotency(), transfers.CreateTransfer)
+			transfer.POST("/:id/reverse", middleware.JWTAuth(), transfers.ReverseTransfer)
+		}
+
+		hold := v1.Group("/holds")
+		{
+			hold.GET("/", middleware.JWTAuth(), holds.FindHolds)
+			hold.GET("/:id", middleware.JWTAuth(), holds.FindHold)
+			hold.POST("/", middleware.JWTAuth(), middleware.Idempotency(), holds.CreateHold)
+			hold.POST("/:id/capture", middleware.JWTAuth(), middleware.Idempotency(), holds.CaptureHold)
+			hold.POST("/:id/release", middleware.JWTAuth(), holds.ReleaseHold)
+		}
+
+		schedule := v1.Group("/schedules")
+		{
+			schedule.GET("/", middleware.JWTAuth(), schedules.FindSchedules)
+			schedule.GET("/:id", middleware.JWTAuth(), schedules.FindSchedule)
+			schedule.POST("/", middleware.JWTAuth(), middleware.Idempotency(), schedules.CreateSchedule)
+			schedule.POST("/:id/pause", middleware.JWTAuth(), schedules.PauseSchedule)
+			schedule.POST("/:id/resume", middleware.JWTAuth(), schedules.ResumeSchedule)
+			schedule.POST("/:id/cancel", middleware.JWTAuth(), schedules.CancelSchedule)
 		}
 
 		file := v1.Group("/files")
@@ -58,13 +160,20 @@ func InitRouter() *gin.Engine {
 			file.GET("/:id", middleware.JWTAuth(), files.FindFile)
 			file.GET("/", middleware.JWTAuth(), files.FindFiles)
 			file.POST("/upload", middleware.JWTAuth(), files.UploadFile)
-			file.POST("/process", middleware.JWTAuth(), files.ProcessFile)
+			file.POST("/process", middleware.JWTAuth(), middleware.Idempotency(), files.ProcessFile)
 		}
 
 		email := v1.Group("/emails")
 		{
 			email.POST("/", middleware.JWTAuth(), emails.SendAccountStatementEmail)
 		}
+
+		administration := v1.Group("/admin", middleware.APIKeyAuth(), middleware.JWTAuth())
+		{
+			administration.GET("/reconciliation", admin.FindReconciliation)
+			administration.POST("/reconciliation", admin.FixReconciliation)
+			administration.GET("/audit", admin.FindAuditEntries)
+		}
 	}
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
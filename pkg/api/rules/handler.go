@@ -0,0 +1,344 @@
+package rules
+
+import (
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/audit"
+	"github.com/wjoseperez20/zenwallet/pkg/cache"
+	"github.com/wjoseperez20/zenwallet/pkg/classifier"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxDryRunMatches bounds the transactions listed by a dry run
+const maxDryRunMatches = 100
+
+// @BasePath /api/v1
+
+// FindRules godoc
+// @Summary Get all rules
+// @Description Get every categorization rule in the order they are evaluated
+// @Tags Rules
+// @Security JwtAuth
+// @Produce json
+// @Success 200 {array} models.Rule "Successfully retrieved list of rules"
+// @Router /rules [get]
+func FindRules(c *gin.Context) {
+	var rules []models.Rule
+
+	if err := database.DB.Order("priority, id").Find(&rules).Error; err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// FindRule godoc
+// @Summary Find a rule by ID
+// @Description Get details of a categorization rule
+// @Tags Rules
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 200 {object} models.Rule "Successfully retrieved rule"
+// @Failure 404 {string} string "rule not found"
+// @Router /rules/{id} [get]
+func FindRule(c *gin.Context) {
+	var rule models.Rule
+
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&rule).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// CreateRule godoc
+// @Summary Create a new rule
+// @Description Create a rule that categorizes and tags the transactions matching all of its conditions when they are created or imported. Rules are evaluated by ascending priority and the first match applies.
+// @Tags Rules
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   input     body   models.CreateRule   true   "Create rule object"
+// @Success 201 {object} models.Rule "Successfully created rule"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "category not found"
+// @Router /rules [post]
+func CreateRule(c *gin.Context) {
+	var input models.CreateRule
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, ok := newRule(c, input)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rule).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditCreate, "rule", rule.ID, nil, rule)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create rule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule godoc
+// @Summary Update a rule by ID
+// @Description Replace the conditions and actions of a rule. Transactions it already categorized keep their category until the rules are applied again.
+// @Tags Rules
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Rule ID"
+// @Param input body models.CreateRule true "Rule object"
+// @Success 200 {object} models.Rule "Successfully updated rule"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "rule or category not found"
+// @Router /rules/{id} [put]
+func UpdateRule(c *gin.Context) {
+	var existing models.Rule
+	var input models.CreateRule
+
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&existing).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, ok := newRule(c, input)
+	if !ok {
+		return
+	}
+	rule.ID = existing.ID
+	rule.CreatedAt = existing.CreatedAt
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&rule).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditUpdate, "rule", rule.ID, existing, rule)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule godoc
+// @Summary Delete a rule by ID
+// @Description Delete a rule. Transactions it categorized keep their category, which is then treated as explicit.
+// @Tags Rules
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 202 {object} models.Rule "Successfully deleted rule"
+// @Failure 404 {string} string "rule not found"
+// @Router /rules/{id} [delete]
+func DeleteRule(c *gin.Context) {
+	var rule models.Rule
+
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&rule).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "rule not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&rule).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditDelete, "rule", rule.ID, rule, nil)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rule"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, rule)
+}
+
+// DryRunRule godoc
+// @Summary Preview the effect of a rule
+// @Description List the existing transactions whose category or tags would change if the rule was saved and enabled, without changing anything. Transactions with an explicit category are never affected.
+// @Tags Rules
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param id query int false "ID of the rule the candidate replaces"
+// @Param input body models.CreateRule true "Candidate rule object"
+// @Success 200 {object} map[string]interface{} "Number of affected transactions and the first of them"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "category not found"
+// @Router /rules/dry-run [post]
+func DryRunRule(c *gin.Context) {
+	var input models.CreateRule
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, ok := newRule(c, input)
+	if !ok {
+		return
+	}
+	rule.Enabled = true
+
+	if id := c.Query("id"); id != "" {
+		var err error
+		if rule.ID, err = strconv.Atoi(id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id format"})
+			return
+		}
+	}
+
+	matches, total, err := classifier.DryRun(database.DB, rule, maxDryRunMatches)
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to evaluate rule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"affected": total, "transactions": matches})
+}
+
+// ApplyRules godoc
+// @Summary Apply the rules to the existing transactions
+// @Description Evaluate the enabled rules against every transaction without an explicit category and store the new categories and tags
+// @Tags Rules
+// @Security JwtAuth
+// @Produce json
+// @Success 200 {object} map[string]int "Number of updated transactions"
+// @Router /rules/apply [post]
+func ApplyRules(c *gin.Context) {
+	updated := 0
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		engine, err := classifier.Load(tx)
+		if err != nil {
+			return err
+		}
+
+		err = engine.Reclassify(tx, func(before models.Transaction, after models.Transaction) error {
+			updated++
+			if err := tx.Model(&after).Select("category", "rule_id", "tags").Updates(after).Error; err != nil {
+				return err
+			}
+
+			return audit.Record(tx, c, models.AuditApplyRules, "transaction", after.ID, before, after)
+		})
+		return err
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply rules"})
+		return
+	}
+
+	// Invalidate cache
+	for _, keysPattern := range []string{"transactions_page_*", "account_transactions_page_*"} {
+		keys, err := cache.Rdb.Keys(cache.Ctx, keysPattern).Result()
+		if err == nil {
+			for _, key := range keys {
+				cache.Rdb.Del(cache.Ctx, key)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// newRule validates the input and builds the rule. When it is invalid the
+// response is written and false is returned.
+// Private function, not exposed to the API
+func newRule(c *gin.Context, input models.CreateRule) (models.Rule, bool) {
+	rule := models.Rule{
+		Name:                input.Name,
+		Priority:            input.Priority,
+		Enabled:             input.Enabled == nil || *input.Enabled,
+		DescriptionContains: input.DescriptionContains,
+		DescriptionPattern:  input.DescriptionPattern,
+		Counterparty:        input.Counterparty,
+		Account:             input.Account,
+		MinAmount:           input.MinAmount,
+		MaxAmount:           input.MaxAmount,
+		Tags:                input.Tags,
+	}
+
+	if err := validateRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return rule, false
+	}
+
+	if category := strings.ToLower(strings.TrimSpace(input.Category)); category != "" {
+		if category == models.FeeCategory {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "category fees is reserved for the fees"})
+			return rule, false
+		}
+
+		var count int64
+		database.DB.Model(&models.Category{}).Where("code = ?", category).Count(&count)
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+			return rule, false
+		}
+		rule.Category = &category
+	} else if len(rule.Tags) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a rule must set a category or tags"})
+		return rule, false
+	}
+
+	return rule, true
+}
+
+// validateRule checks the conditions of a rule
+// Private function, not exposed to the API
+func validateRule(rule models.Rule) error {
+	if rule.DescriptionContains == "" && rule.DescriptionPattern == "" && rule.Counterparty == "" &&
+		rule.Account == nil && rule.MinAmount == nil && rule.MaxAmount == nil {
+		return errors.New("a rule needs at least one condition")
+	}
+
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return errors.New("min_amount must not be greater than max_amount")
+	}
+
+	if rule.DescriptionPattern != "" {
+		if _, err := regexp.Compile(rule.DescriptionPattern); err != nil {
+			return errors.New("invalid description_pattern: " + err.Error())
+		}
+	}
+
+	return nil
+}
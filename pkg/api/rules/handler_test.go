@@ -0,0 +1,125 @@
+package rules
+
+import (
+	"bytes"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateRule_InvalidPattern(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/rules", CreateRule)
+
+	// When
+	w := performRequest(r, "POST", "/rules", `{"name":"coffee","description_pattern":"(starbucks","category":"dining"}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"invalid description_pattern: error parsing regexp: missing closing ): ` + "`(starbucks`" + `"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+func TestCreateRule_WithoutCondition(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/rules", CreateRule)
+
+	// When
+	w := performRequest(r, "POST", "/rules", `{"name":"everything","category":"dining"}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"a rule needs at least one condition"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+func TestCreateRule_CategoryNotFound(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/rules", CreateRule)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT count\(\*\) FROM "categories" WHERE code = (.+)`).
+		WithArgs("coffee").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// When
+	w := performRequest(r, "POST", "/rules", `{"name":"coffee","description_contains":"starbucks","category":"coffee"}`)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	// Then
+	expected := `{"error":"category not found"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCreateRule_FeeCategory(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/rules", CreateRule)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+
+	// When
+	w := performRequest(r, "POST", "/rules", `{"name":"bank","description_contains":"bank","category":"Fees"}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"category fees is reserved for the fees"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestFindRule_NotFound(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/rules/:id", FindRule)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "rules" WHERE id = (.+) ORDER BY "rules"."id" LIMIT 1`).
+		WithArgs("7").
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	// When
+	w := performRequest(r, "GET", "/rules/7", "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	// Then
+	expected := `{"error":"rule not found"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	// Create a mock database for testing
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// Replace the actual database with the mock database for testing
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
+
+// performRequest performs an HTTP request and returns the response recorder.
+func performRequest(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,318 @@
+package schedules
+
+import (
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/audit"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/scheduler"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errInvalidStatus = errors.New("invalid status transition")
+
+// @BasePath /api/v1
+
+// FindSchedule godoc
+// @Summary Find a schedule by ID
+// @Description Get details of a schedule and of all its runs
+// @Tags Schedules
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} models.Schedule "Successfully retrieved schedule"
+// @Failure 404 {string} string "schedule not found"
+// @Router /schedules/{id} [get]
+func FindSchedule(c *gin.Context) {
+	var schedule models.Schedule
+
+	err := database.DB.
+		Preload("Runs", func(db *gorm.DB) *gorm.DB { return db.Order("date, id") }).
+		Where("id = ?", c.Param("id")).
+		First(&schedule).Error
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// FindSchedules godoc
+// @Summary Get all schedules with pagination
+// @Description Get a list of schedules, optionally restricted to an account and a status
+// @Tags Schedules
+// @Security JwtAuth
+// @Produce json
+// @Param account query int false "Account number"
+// @Param status query string false "Schedule status" Enums(active, paused, cancelled, completed)
+// @Param offset query int false "Offset for pagination" default(0)
+// @Param limit query int false "Limit for pagination" default(10)
+// @Success 200 {array} models.Schedule "Successfully retrieved list of schedules"
+// @Router /schedules [get]
+func FindSchedules(c *gin.Context) {
+	var schedules []models.Schedule
+
+	// Convert query params to integers
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset format"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit format"})
+		return
+	}
+
+	// Schedules change on their own when they run, they are not cached
+	query := database.DB.Order("id").Offset(offset).Limit(limit)
+	if account := c.Query("account"); account != "" {
+		query = query.Where("account_id = ?", account)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Find(&schedules).Error; err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch schedules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+// CreateSchedule godoc
+// @Summary Create a schedule
+// @Description Create a standing order that posts a transaction daily, weekly or monthly on day_of_month, until end_date or until count transactions were posted
+// @Tags Schedules
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Param   input     body   models.CreateSchedule   true   "Create schedule object"
+// @Success 201 {object} models.Schedule "Successfully created schedule"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "account not found"
+// @Router /schedules [post]
+func CreateSchedule(c *gin.Context) {
+	var input models.CreateSchedule
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule, err := newSchedule(input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var account models.Account
+	if err := database.DB.Where("account = ?", schedule.Account).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(schedule).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditCreate, "schedule", schedule.ID, nil, schedule)
+	})
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, schedule)
+}
+
+// PauseSchedule godoc
+// @Summary Pause a schedule by ID
+// @Description Stop posting the occurrences of an active schedule until it is resumed
+// @Tags Schedules
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} models.Schedule "Successfully paused schedule"
+// @Failure 404 {string} string "schedule not found"
+// @Failure 409 {string} string "schedule is not active"
+// @Router /schedules/{id}/pause [post]
+func PauseSchedule(c *gin.Context) {
+	changeStatus(c, models.AuditPause, "schedule is not active", func(schedule *models.Schedule) error {
+		if schedule.Status != models.ScheduleActive {
+			return errInvalidStatus
+		}
+
+		schedule.Status = models.SchedulePaused
+		return nil
+	})
+}
+
+// ResumeSchedule godoc
+// @Summary Resume a schedule by ID
+// @Description Reactivate a paused schedule. The occurrences missed while it was paused are not posted.
+// @Tags Schedules
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} models.Schedule "Successfully resumed schedule"
+// @Failure 404 {string} string "schedule not found"
+// @Failure 409 {string} string "schedule is not paused"
+// @Router /schedules/{id}/resume [post]
+func ResumeSchedule(c *gin.Context) {
+	changeStatus(c, models.AuditResume, "schedule is not paused", func(schedule *models.Schedule) error {
+		if schedule.Status != models.SchedulePaused {
+			return errInvalidStatus
+		}
+
+		scheduler.Resume(schedule, today())
+		return nil
+	})
+}
+
+// CancelSchedule godoc
+// @Summary Cancel a schedule by ID
+// @Description Stop a schedule for good, the transactions already posted are kept
+// @Tags Schedules
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Schedule ID"
+// @Success 200 {object} models.Schedule "Successfully cancelled schedule"
+// @Failure 404 {string} string "schedule not found"
+// @Failure 409 {string} string "schedule already finished"
+// @Router /schedules/{id}/cancel [post]
+func CancelSchedule(c *gin.Context) {
+	changeStatus(c, models.AuditCancel, "schedule already finished", func(schedule *models.Schedule) error {
+		if schedule.Status != models.ScheduleActive && schedule.Status != models.SchedulePaused {
+			return errInvalidStatus
+		}
+
+		schedule.Status = models.ScheduleCancelled
+		return nil
+	})
+}
+
+// changeStatus locks the schedule of the request, applies change, saves
+// its status and next run and audits the change as action. The scheduler
+// cannot post an occurrence meanwhile.
+// Private function, not exposed to the API
+func changeStatus(c *gin.Context, action string, conflict string, change func(schedule *models.Schedule) error) {
+	var schedule models.Schedule
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", c.Param("id")).First(&schedule).Error; err != nil {
+			return err
+		}
+
+		before := schedule
+		if err := change(&schedule); err != nil {
+			return err
+		}
+
+		err := tx.Model(&schedule).Updates(map[string]interface{}{"status": schedule.Status, "next_run": schedule.NextRun}).Error
+		if err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, action, "schedule", schedule.ID, before, schedule)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+		case errors.Is(err, errInvalidStatus):
+			c.JSON(http.StatusConflict, gin.H{"error": conflict})
+		default:
+			log.Default().Println(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// newSchedule validates the input and builds the schedule with its first run
+// Private function, not exposed to the API
+func newSchedule(input models.CreateSchedule) (*models.Schedule, error) {
+	if input.Amount == 0 {
+		return nil, errors.New("amount must not be zero")
+	}
+	if input.Count < 0 {
+		return nil, errors.New("count must not be negative")
+	}
+
+	startDate, err := time.Parse("2006-01-02", input.StartDate)
+	if err != nil {
+		return nil, errors.New("Invalid start_date format")
+	}
+	if startDate.Before(today()) {
+		return nil, errors.New("start_date must not be in the past")
+	}
+
+	schedule := models.Schedule{
+		Account:     input.Account,
+		Amount:      input.Amount,
+		Currency:    strings.ToUpper(input.Currency),
+		Description: input.Description,
+		Frequency:   input.Frequency,
+		StartDate:   startDate,
+		Status:      models.ScheduleActive,
+	}
+	if schedule.Currency == "" {
+		schedule.Currency = models.DefaultCurrency
+	}
+
+	if input.Frequency == models.ScheduleMonthly {
+		schedule.DayOfMonth = input.DayOfMonth
+		if schedule.DayOfMonth == 0 {
+			schedule.DayOfMonth = startDate.Day()
+		}
+		if schedule.DayOfMonth < 1 || schedule.DayOfMonth > 31 {
+			return nil, errors.New("day_of_month must be between 1 and 31")
+		}
+	}
+
+	if input.EndDate != "" {
+		endDate, err := time.Parse("2006-01-02", input.EndDate)
+		if err != nil {
+			return nil, errors.New("Invalid end_date format")
+		}
+		schedule.EndDate = &endDate
+	}
+	if input.Count > 0 {
+		schedule.Count = &input.Count
+	}
+
+	schedule.NextRun, err = scheduler.FirstOccurrence(schedule)
+	if err != nil {
+		return nil, err
+	}
+	if scheduler.Finished(schedule) {
+		return nil, errors.New("schedule has no occurrence before its end_date")
+	}
+
+	return &schedule, nil
+}
+
+// today returns the current date at midnight UTC
+// Private function, not exposed to the API
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,106 @@
+package schedules
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCreateSchedule_StartDateInThePast(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/schedules", CreateSchedule)
+
+	incomingSchedule := models.CreateSchedule{Account: 10001, Amount: money.MustParse("-25"), Frequency: models.ScheduleMonthly, StartDate: "2023-11-25"}
+
+	// When
+	w := performRequest(r, "POST", "/schedules", toJSON(incomingSchedule))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"start_date must not be in the past"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+func TestCreateSchedule_InvalidFrequency(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/schedules", CreateSchedule)
+
+	incomingSchedule := models.CreateSchedule{Account: 10001, Amount: money.MustParse("-25"), Frequency: "yearly", StartDate: today().AddDate(0, 0, 1).Format("2006-01-02")}
+
+	// When
+	w := performRequest(r, "POST", "/schedules", toJSON(incomingSchedule))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"frequency must be daily, weekly or monthly"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+func TestPauseSchedule_NotActive(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/schedules/:id/pause", PauseSchedule)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`SELECT \* FROM "schedules" WHERE id = (.+) ORDER BY "schedules"."id" LIMIT 1 FOR UPDATE`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, models.ScheduleCancelled))
+	dbMock.ExpectRollback()
+
+	// When
+	w := performRequest(r, "POST", "/schedules/1/pause")
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// Then
+	expected := `{"error":"schedule is not active"}`
+	require.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	// Create a mock database for testing
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// Replace the actual database with the mock database for testing
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
+
+// performRequest performs an HTTP request and returns the response recorder.
+func performRequest(router *gin.Engine, method, path string, requestBody ...[]byte) *httptest.ResponseRecorder {
+	var reqBody []byte
+	if len(requestBody) > 0 {
+		reqBody = requestBody[0]
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func toJSON(v interface{}) []byte {
+	result, _ := json.Marshal(v)
+	return result
+}
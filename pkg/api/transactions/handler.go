@@ -2,17 +2,35 @@ package transactions
 
 import (
 	"encoding/json"
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/audit"
+	"github.com/wjoseperez20/zenwallet/pkg/balances"
 	"github.com/wjoseperez20/zenwallet/pkg/cache"
+	"github.com/wjoseperez20/zenwallet/pkg/classifier"
+	"github.com/wjoseperez20/zenwallet/pkg/closing"
 	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/fees"
+	"github.com/wjoseperez20/zenwallet/pkg/filters"
+	"github.com/wjoseperez20/zenwallet/pkg/ledger"
 	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"github.com/wjoseperez20/zenwallet/pkg/pagination"
+	"github.com/wjoseperez20/zenwallet/pkg/posting"
+	"github.com/wjoseperez20/zenwallet/pkg/splits"
 	"log"
 	"net/http"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// errBulkRejected rolls back an atomic bulk request with a failed transaction
+var errBulkRejected = errors.New("bulk request rejected")
+
 // @BasePath /api/v1
 
 // FindTransaction godoc
@@ -22,7 +40,7 @@ import (
 // @Security JwtAuth
 // @Produce json
 // @Param id path string true "Transaction ID"
-// @Success 200 {object} models.Transaction "Successfully retrieved transaction"
+// @Success 200 {object} models.Transaction "Successfully retrieved transaction, with its reversal chain if any"
 // @Failure 404 {string} string "Transaction not found"
 // @Router /transactions/{id} [get]
 func FindTransaction(c *gin.Context) {
@@ -33,71 +51,108 @@ func FindTransaction(c *gin.Context) {
 		return
 	}
 
+	found := []models.Transaction{transaction}
+	if err := splits.Load(database.DB, found); err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load splits"})
+		return
+	}
+	transaction = found[0]
+
+	if transaction.ReversalOf != nil || transaction.ReversedBy != nil {
+		chain, err := reversalChain(transaction)
+		if err != nil {
+			log.Default().Println(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load reversal chain"})
+			return
+		}
+		transaction.ReversalChain = chain
+	}
+
 	c.JSON(http.StatusOK, transaction)
 }
 
 // FindTransactions godoc
-// @Summary Get all transactions with pagination
-// @Description Get a list of all transactions with optional pagination
+// @Summary Get all transactions with cursor pagination
+// @Description Get a page of transactions, optionally filtered by account, date range, amount range, sign, category and a text in the description, and sorted. Pass the next_cursor of a page as cursor to get the next one.
 // @Tags Transactions
 // @Security JwtAuth
 // @Produce json
-// @Param offset query int false "Offset for pagination" default(0)
-// @Param limit query int false "Limit for pagination" default(10)
-// @Success 200 {array} models.Transaction "Successfully retrieved list of transactions"
+// @Param account query int false "Account ID"
+// @Param from query string false "First day as YYYY-MM-DD"
+// @Param to query string false "Last day as YYYY-MM-DD"
+// @Param min_amount query number false "Minimum amount, signed"
+// @Param max_amount query number false "Maximum amount, signed"
+// @Param sign query string false "Debits or credits only" Enums(debit, credit)
+// @Param category query string false "Category code"
+// @Param q query string false "Text contained in the description, case insensitive"
+// @Param sort query string false "Sort order, prefix with - for descending" Enums(id, -id, date, -date, amount, -amount) default(id)
+// @Param cursor query string false "Cursor returned as next_cursor with the previous page"
+// @Param limit query int false "Page size, at most 100" default(10)
+// @Param total query bool false "Include the number of transactions matching the filters" default(false)
+// @Success 200 {object} pagination.Page[models.Transaction] "Successfully retrieved page of transactions"
+// @Failure 400 {string} string "Bad Request"
 // @Router /transactions [get]
 func FindTransactions(c *gin.Context) {
-	var transactions []models.Transaction
-
-	// Get query params
-	offsetQuery := c.DefaultQuery("offset", "0")
-	limitQuery := c.DefaultQuery("limit", "10")
+	var page pagination.Page[models.Transaction]
 
-	// Convert query params to integers
-	offset, err := strconv.Atoi(offsetQuery)
+	filter, err := filters.ParseTransactions(c.Request.URL.Query())
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset format"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	limit, err := strconv.Atoi(limitQuery)
+	params, err := pagination.Parse(c.Request.URL.Query())
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit format"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Create a cache key based on query params
-	cacheKey := "transactions_offset_" + offsetQuery + "_limit_" + limitQuery
+	cacheKey := "transactions_page_" + params.Key() + "_" + filter.Key()
 
 	// Try fetching the data from Redis first
-	cachedTransactions, err := cache.Rdb.Get(cache.Ctx, cacheKey).Result()
+	cachedPage, err := cache.Rdb.Get(cache.Ctx, cacheKey).Result()
 	if err == nil {
-		err := json.Unmarshal([]byte(cachedTransactions), &transactions)
+		err := json.Unmarshal([]byte(cachedPage), &page)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal cached data"})
 			return
 		}
-		c.JSON(http.StatusOK, transactions)
+		c.JSON(http.StatusOK, page)
 		return
 	}
 
 	// If cache missed, fetch data from the database
-	database.DB.Offset(offset).Limit(limit).Find(&transactions)
+	query := filter.Apply(database.DB.Model(&models.Transaction{}))
+	page, err = pagination.Fetch(query, params, filter.Order(), filter.Cursor)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err == nil {
+		err = splits.Load(database.DB, page.Data)
+	}
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
+		return
+	}
 
-	// Serialize transactions object and store it in Redis
-	serializedTransactions, err := json.Marshal(transactions)
+	// Serialize the page and store it in Redis
+	serializedPage, err := json.Marshal(page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal data"})
 		return
 	}
-	err = cache.Rdb.Set(cache.Ctx, cacheKey, serializedTransactions, time.Minute).Err() // Here TTL is set to one hour
+	err = cache.Rdb.Set(cache.Ctx, cacheKey, serializedPage, time.Minute).Err() // Here TTL is set to one hour
 	if err != nil {
 		log.Default().Println(err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set cache"})
 		return
 	}
 
-	c.JSON(http.StatusOK, transactions)
+	c.JSON(http.StatusOK, page)
 }
 
 // CreateTransaction godoc
@@ -107,10 +162,14 @@ func FindTransactions(c *gin.Context) {
 // @Security JwtAuth
 // @Accept  json
 // @Produce  json
+// @Param   Idempotency-Key header string false "Key that makes retries of this request safe"
 // @Param   input     body   models.CreateTransaction   true   "Create transaction object"
 // @Success 201 {object} models.Transaction "Successfully created transaction"
 // @Failure 400 {string} string "Bad Request"
 // @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "account or category not found"
+// @Failure 409 {string} string "account status or a closed period rejects the posting or request with the same Idempotency-Key in progress"
+// @Failure 422 {string} string "rejected by the account policy or Idempotency-Key reused with a different request"
 // @Router /transactions [post]
 func CreateTransaction(c *gin.Context) {
 	var input models.CreateTransaction
@@ -120,25 +179,207 @@ func CreateTransaction(c *gin.Context) {
 		return
 	}
 
-	date, _ := time.Parse("2006-01-02", input.Date)
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
 
-	transaction := models.Transaction{Account: input.Account, Date: date, Amount: input.Amount}
+	transaction := models.Transaction{
+		Account:           input.Account,
+		Date:              date,
+		Amount:            input.Amount,
+		Currency:          strings.ToUpper(input.Currency),
+		Description:       input.Description,
+		Counterparty:      input.Counterparty,
+		Category:          categoryCode(input.Category),
+		ExternalReference: input.ExternalReference,
+		Metadata:          input.Metadata,
+		Splits:            splits.FromInput(input.Splits),
+	}
 
-	database.DB.Create(&transaction)
+	postTransaction(c, &transaction)
+}
 
-	// Invalidate cache
-	keysPattern := "transactions_offset_*"
-	keys, err := cache.Rdb.Keys(cache.Ctx, keysPattern).Result()
-	if err == nil {
-		for _, key := range keys {
-			cache.Rdb.Del(cache.Ctx, key)
+// BulkCreateTransactions godoc
+// @Summary Create many transactions at once
+// @Description Validate and post up to 1000 transactions in order, each seeing the balances and limits left by the previous ones. In atomic mode, the default, either all of them are posted or none; in best_effort mode the valid ones are posted and the others skipped. The response holds a result per transaction, with its ID or why it failed.
+// @Tags Transactions
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Param   input     body   models.BulkCreateTransactions   true   "Transactions and mode"
+// @Success 201 {object} models.BulkTransactions "Every transaction was created"
+// @Success 207 {object} models.BulkTransactions "Best effort, some transactions failed"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 409 {string} string "request with the same Idempotency-Key in progress"
+// @Failure 422 {object} models.BulkTransactions "Atomic, some transactions failed and none was created"
+// @Router /transactions/bulk [post]
+func BulkCreateTransactions(c *gin.Context) {
+	var input models.BulkCreateTransactions
+	var pending []*models.Transaction
+	var positions []int
+	var posted []*models.Transaction
+	var postedPositions []int
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Mode == "" {
+		input.Mode = models.BulkAtomic
+	}
+	atomic := input.Mode == models.BulkAtomic
+
+	bulk := models.BulkTransactions{Mode: input.Mode, Results: make([]models.BulkTransactionResult, len(input.Transactions))}
+	fail := func(i int, err error) {
+		bulk.Results[i].Status = models.BulkItemFailed
+		bulk.Results[i].Error = err.Error()
+		bulk.Failed++
+	}
+
+	// Validate every transaction before posting any
+	for i, item := range input.Transactions {
+		bulk.Results[i] = models.BulkTransactionResult{Index: i, Status: models.BulkItemNotPosted}
+
+		if err := binding.Validator.ValidateStruct(item); err != nil {
+			fail(i, err)
+			continue
+		}
+		date, err := time.Parse("2006-01-02", item.Date)
+		if err != nil {
+			fail(i, errors.New("Invalid date format"))
+			continue
 		}
+		lines := splits.FromInput(item.Splits)
+		if err := splits.Validate(item.Amount, lines); err != nil {
+			fail(i, err)
+			continue
+		}
+
+		pending = append(pending, &models.Transaction{
+			Account:           item.Account,
+			Date:              date,
+			Amount:            item.Amount,
+			Currency:          strings.ToUpper(item.Currency),
+			Description:       item.Description,
+			Counterparty:      item.Counterparty,
+			Category:          categoryCode(item.Category),
+			ExternalReference: item.ExternalReference,
+			Metadata:          item.Metadata,
+			Splits:            lines,
+		})
+		positions = append(positions, i)
 	}
 
-	// Update account balance
-	updateAccountBalance(uint(input.Account), input.Amount)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if atomic && bulk.Failed > 0 {
+			return errBulkRejected
+		}
 
-	c.JSON(http.StatusCreated, transaction)
+		engine, err := classifier.Load(tx)
+		if err != nil {
+			return err
+		}
+
+		var codes []string
+		for _, transaction := range pending {
+			engine.Apply(transaction)
+			for _, line := range transaction.Splits {
+				codes = append(codes, line.Category)
+			}
+		}
+
+		known, err := splits.KnownCategories(tx, codes)
+		if err != nil {
+			return err
+		}
+
+		var postable []*models.Transaction
+		var postablePositions []int
+		for j, transaction := range pending {
+			if !knownSplits(transaction, known) {
+				fail(positions[j], ledger.ErrCategoryNotFound)
+				continue
+			}
+			postable = append(postable, transaction)
+			postablePositions = append(postablePositions, positions[j])
+		}
+		if atomic && bulk.Failed > 0 {
+			return errBulkRejected
+		}
+
+		failed, err := ledger.PostBatch(tx, postable, atomic)
+		if err != nil {
+			return err
+		}
+		for j, err := range failed {
+			if err != nil {
+				fail(postablePositions[j], err)
+				continue
+			}
+			posted = append(posted, postable[j])
+			postedPositions = append(postedPositions, postablePositions[j])
+		}
+		if atomic && bulk.Failed > 0 {
+			return errBulkRejected
+		}
+		if err := splits.CreateAll(tx, posted); err != nil {
+			return err
+		}
+
+		// Charge the fees of the accounts, linked to their transactions
+		charged, err := fees.ChargeBatch(tx, posted)
+		if err != nil {
+			return err
+		}
+
+		byID := make(map[int]*models.Transaction)
+		for _, transaction := range posted {
+			byID[transaction.ID] = transaction
+		}
+		changes := make([]audit.Change, 0, len(charged)+len(posted))
+		for _, fee := range charged {
+			byID[*fee.FeeOf].Fees = append(byID[*fee.FeeOf].Fees, fee)
+			changes = append(changes, audit.Change{ID: fee.ID, After: fee})
+		}
+		for _, transaction := range posted {
+			changes = append(changes, audit.Change{ID: transaction.ID, After: *transaction})
+		}
+
+		return audit.RecordAll(tx, c, models.AuditCreate, "transaction", changes)
+	})
+	if errors.Is(err, errBulkRejected) {
+		c.JSON(http.StatusUnprocessableEntity, bulk)
+		return
+	}
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to post transactions"})
+		return
+	}
+
+	for j, transaction := range posted {
+		id := transaction.ID
+		bulk.Results[postedPositions[j]].Status = models.BulkItemCreated
+		bulk.Results[postedPositions[j]].ID = &id
+		bulk.Created++
+	}
+
+	if bulk.Created > 0 {
+		// Invalidate cache
+		invalidateCache("transactions_page_*", "account_transactions_page_*", "accounts_page_*")
+	}
+
+	if bulk.Failed > 0 {
+		c.JSON(http.StatusMultiStatus, bulk)
+		return
+	}
+
+	c.JSON(http.StatusCreated, bulk)
 }
 
 // UpdateTransaction godoc
@@ -152,62 +393,576 @@ func CreateTransaction(c *gin.Context) {
 // @Param input body models.UpdateTransaction true "Update transaction object"
 // @Success 200 {object} models.Transaction "Successfully updated transaction"
 // @Failure 400 {string} string "Bad Request"
-// @Failure 404 {string} string "transaction not found"
+// @Failure 404 {string} string "transaction, account or category not found"
+// @Failure 409 {string} string "transaction belongs to a transfer or a reversal, or account status or a closed period rejects the change"
+// @Failure 422 {string} string "rejected by the account policy"
 // @Router /transactions/{id} [put]
 func UpdateTransaction(c *gin.Context) {
 	var transaction models.Transaction
 	var input models.UpdateTransaction
 
-	if err := database.DB.Where("id = ?", c.Param("id")).First(&transaction).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+
+	// Lock the transaction and post the delta against its previous state atomically
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", c.Param("id")).First(&transaction).Error; err != nil {
+			return err
+		}
+		found := []models.Transaction{transaction}
+		if err := splits.Load(tx, found); err != nil {
+			return err
+		}
+		transaction = found[0]
+		before := transaction
+
+		// The splits are kept unless replaced, they must still sum to the amount
+		if input.Splits == nil {
+			if err := splits.Validate(input.Amount, transaction.Splits); err != nil {
+				return err
+			}
+		}
+
+		err := ledger.Amend(tx, &transaction, models.Transaction{
+			Account:           input.Account,
+			Date:              date,
+			Amount:            input.Amount,
+			Description:       input.Description,
+			Counterparty:      input.Counterparty,
+			Category:          categoryCode(input.Category),
+			ExternalReference: input.ExternalReference,
+			Metadata:          input.Metadata,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The category is now explicit, even when cleared, and never overwritten by the rules
+		if transaction.RuleID != nil {
+			transaction.RuleID = nil
+			if err := tx.Model(&transaction).Update("rule_id", nil).Error; err != nil {
+				return err
+			}
+		}
+
+		if input.Splits != nil {
+			if err := splits.Replace(tx, &transaction, splits.FromInput(input.Splits)); err != nil {
+				return err
+			}
+		}
+
+		return audit.Record(tx, c, models.AuditUpdate, "transaction", transaction.ID, before, transaction)
+	})
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	// Invalidate cache
+	invalidateCache("transactions_page_*", "account_transactions_page_*", "accounts_page_*")
+
+	c.JSON(http.StatusOK, transaction)
+}
+
+// UpdateTransactionSplits godoc
+// @Summary Split a transaction across categories
+// @Description Replace the splits of a transaction. Each split has an amount with the sign of the transaction, a category and a note, and together they must sum to the amount of the transaction. An empty list removes the splits.
+// @Tags Transactions
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   id        path   string                          true   "Transaction ID"
+// @Param   input     body   models.UpdateTransactionSplits  true   "Splits of the transaction"
+// @Success 200 {object} models.Transaction "Successfully updated splits"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "transaction or category not found"
+// @Router /transactions/{id}/splits [put]
+func UpdateTransactionSplits(c *gin.Context) {
+	var transaction models.Transaction
+	var input models.UpdateTransactionSplits
+
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	date, _ := time.Parse("2006-01-02", input.Date)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", c.Param("id")).First(&transaction).Error; err != nil {
+			return err
+		}
+		found := []models.Transaction{transaction}
+		if err := splits.Load(tx, found); err != nil {
+			return err
+		}
+		transaction = found[0]
+		before := transaction
 
-	database.DB.Model(&transaction).Updates(models.Transaction{Account: input.Account, Date: date, Amount: input.Amount})
+		if err := splits.Replace(tx, &transaction, splits.FromInput(input.Splits)); err != nil {
+			return err
+		}
 
-	// Update account balance
-	updateAccountBalance(uint(input.Account), input.Amount)
+		return audit.Record(tx, c, models.AuditUpdate, "transaction", transaction.ID, before, transaction)
+	})
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	// Invalidate cache
+	invalidateCache("transactions_page_*", "account_transactions_page_*")
 
 	c.JSON(http.StatusOK, transaction)
 }
 
-// DeleteTransaction godoc
-// @Summary Delete a transaction by ID
-// @Description Delete the transaction with the given ID
+// ReverseTransaction godoc
+// @Summary Reverse a transaction by ID
+// @Description Post a compensating transaction that cancels the given one. Posted transactions are never deleted.
 // @Tags Transactions
 // @Security JwtAuth
 // @Produce json
 // @Param id path string true "Transaction ID"
-// @Success 202 {object} models.Transaction "Successfully deleted transaction"
+// @Success 201 {object} models.Transaction "Successfully created reversal transaction"
 // @Failure 404 {string} string "transaction not found"
-// @Router /transactions/{id} [delete]
-func DeleteTransaction(c *gin.Context) {
-	var transaction models.Transaction
+// @Failure 409 {string} string "transaction already reversed or belongs to a transfer, or account is closed"
+// @Router /transactions/{id}/reverse [post]
+func ReverseTransaction(c *gin.Context) {
+	var reversal *models.Transaction
 
-	if err := database.DB.Where("id = ?", c.Param("id")).First(&transaction).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var transaction models.Transaction
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", c.Param("id")).First(&transaction).Error; err != nil {
+			return err
+		}
+
+		before := transaction
+
+		var err error
+		if reversal, err = ledger.Reverse(tx, &transaction, today()); err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditReverse, "transaction", transaction.ID, before, reversal)
+	})
+	if err != nil {
+		respondLedgerError(c, err)
 		return
 	}
 
-	database.DB.Delete(&transaction)
+	// Invalidate cache
+	invalidateCache("transactions_page_*", "account_transactions_page_*", "accounts_page_*")
 
-	c.JSON(http.StatusAccepted, transaction)
+	c.JSON(http.StatusCreated, reversal)
 }
 
-// updateAccountBalance updates the balance of the given account
-// by adding the given amount to the current balance
+// FindAccountTransactions godoc
+// @Summary Get the transactions of an account with cursor pagination
+// @Description Get a page of the transactions of an account, with the balance of the account right after each of them. Accepts the filters and sort orders of the transactions list, the account being the one of the path.
+// @Tags Accounts
+// @Security JwtAuth
+// @Produce json
+// @Param account path int true "Account ID"
+// @Param from query string false "First day as YYYY-MM-DD"
+// @Param to query string false "Last day as YYYY-MM-DD"
+// @Param min_amount query number false "Minimum amount, signed"
+// @Param max_amount query number false "Maximum amount, signed"
+// @Param sign query string false "Debits or credits only" Enums(debit, credit)
+// @Param category query string false "Category code"
+// @Param q query string false "Text contained in the description, case insensitive"
+// @Param sort query string false "Sort order, prefix with - for descending" Enums(id, -id, date, -date, amount, -amount) default(id)
+// @Param cursor query string false "Cursor returned as next_cursor with the previous page"
+// @Param limit query int false "Page size, at most 100" default(10)
+// @Param total query bool false "Include the number of transactions matching the filters" default(false)
+// @Success 200 {object} pagination.Page[models.AccountTransaction] "Successfully retrieved page of transactions"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "account not found"
+// @Router /accounts/{account}/transactions [get]
+func FindAccountTransactions(c *gin.Context) {
+	var account models.Account
+	var page pagination.Page[models.AccountTransaction]
+
+	filter, err := filters.ParseTransactions(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	params, err := pagination.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Where("account = ?", c.Param("account")).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+	filter.Account = &account.Account
+
+	// Create a cache key based on query params
+	cacheKey := "account_transactions_page_" + params.Key() + "_" + filter.Key()
+
+	// Try fetching the data from Redis first
+	cachedPage, err := cache.Rdb.Get(cache.Ctx, cacheKey).Result()
+	if err == nil {
+		err := json.Unmarshal([]byte(cachedPage), &page)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal cached data"})
+			return
+		}
+		c.JSON(http.StatusOK, page)
+		return
+	}
+
+	page, err = pagination.Fetch(runningBalances(database.DB, account.Account, filter), params, filter.Order(), func(transaction models.AccountTransaction) pagination.Cursor {
+		return filter.Cursor(transaction.Transaction)
+	})
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err == nil {
+		err = loadAccountSplits(page.Data)
+	}
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transactions"})
+		return
+	}
+
+	// Serialize the page and store it in Redis
+	serializedPage, err := json.Marshal(page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal data"})
+		return
+	}
+	err = cache.Rdb.Set(cache.Ctx, cacheKey, serializedPage, time.Minute).Err() // Here TTL is set to one hour
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set cache"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// CreateAccountTransaction godoc
+// @Summary Create a transaction on an account
+// @Description Create a new transaction on the account of the path, as POST /transactions does
+// @Tags Accounts
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Param   account path int true "Account ID"
+// @Param   input body models.CreateAccountTransaction true "Create transaction object"
+// @Success 201 {object} models.Transaction "Successfully created transaction"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "account or category not found"
+// @Failure 409 {string} string "account status or a closed period rejects the posting or request with the same Idempotency-Key in progress"
+// @Failure 422 {string} string "rejected by the account policy or Idempotency-Key reused with a different request"
+// @Router /accounts/{account}/transactions [post]
+func CreateAccountTransaction(c *gin.Context) {
+	var account models.Account
+	var input models.CreateAccountTransaction
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+
+	if err := database.DB.Where("account = ?", c.Param("account")).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+
+	transaction := models.Transaction{
+		Account:           account.Account,
+		Date:              date,
+		Amount:            input.Amount,
+		Currency:          strings.ToUpper(input.Currency),
+		Description:       input.Description,
+		Counterparty:      input.Counterparty,
+		Category:          categoryCode(input.Category),
+		ExternalReference: input.ExternalReference,
+		Metadata:          input.Metadata,
+		Splits:            splits.FromInput(input.Splits),
+	}
+
+	postTransaction(c, &transaction)
+}
+
+// FindAccountTransactionSummary godoc
+// @Summary Summarize the transactions of an account
+// @Description Count and total the debits and credits of the account matching the filters of the transactions list, with the balance of the account before from and at the end of to
+// @Tags Accounts
+// @Security JwtAuth
+// @Produce json
+// @Param account path int true "Account ID"
+// @Param from query string false "First day as YYYY-MM-DD"
+// @Param to query string false "Last day as YYYY-MM-DD, today by default"
+// @Param min_amount query number false "Minimum amount, signed"
+// @Param max_amount query number false "Maximum amount, signed"
+// @Param sign query string false "Debits or credits only" Enums(debit, credit)
+// @Param category query string false "Category code"
+// @Param q query string false "Text contained in the description, case insensitive"
+// @Success 200 {object} models.AccountTransactionSummary "Successfully summarized transactions"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 404 {string} string "account not found"
+// @Router /accounts/{account}/transactions/summary [get]
+func FindAccountTransactionSummary(c *gin.Context) {
+	var account models.Account
+	var totals struct {
+		DebitCount   int
+		CreditCount  int
+		TotalDebits  money.Amount
+		TotalCredits money.Amount
+	}
+
+	filter, err := filters.ParseTransactions(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Where("account = ?", c.Param("account")).First(&account).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+		return
+	}
+	filter.Account = &account.Account
+
+	to := today()
+	if filter.To != nil {
+		to = *filter.To
+	} else {
+		filter.To = &to
+	}
+
+	err = filter.Apply(database.DB.Model(&models.Transaction{})).
+		Select(`COUNT(CASE WHEN amount < 0 THEN 1 END) AS debit_count,
+			COUNT(CASE WHEN amount >= 0 THEN 1 END) AS credit_count,
+			COALESCE(SUM(CASE WHEN amount < 0 THEN -amount ELSE 0 END), 0) AS total_debits,
+			COALESCE(SUM(CASE WHEN amount >= 0 THEN amount ELSE 0 END), 0) AS total_credits`).
+		Scan(&totals).Error
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to summarize transactions"})
+		return
+	}
+
+	summary := models.AccountTransactionSummary{
+		Account:          account.Account,
+		To:               to.Format("2006-01-02"),
+		TransactionCount: totals.DebitCount + totals.CreditCount,
+		DebitCount:       totals.DebitCount,
+		CreditCount:      totals.CreditCount,
+		TotalDebits:      totals.TotalDebits,
+		TotalCredits:     totals.TotalCredits,
+		Net:              totals.TotalCredits - totals.TotalDebits,
+	}
+
+	if filter.From != nil {
+		summary.From = filter.From.Format("2006-01-02")
+		if summary.OpeningBalance, err = balances.AsOf(database.DB, account.Account, filter.From.AddDate(0, 0, -1)); err != nil {
+			log.Default().Println(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute balance"})
+			return
+		}
+	}
+
+	if summary.ClosingBalance, err = balances.AsOf(database.DB, account.Account, to); err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute balance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// runningBalances returns the query of the transactions of the account
+// matching the filter, with the balance of the account after each of them.
+// The balances are computed from the ledger postings of the account, before
+// the filter restricts the rows, so the reconciliation adjustments count on
+// their day, before its transactions, and the last balance is the balance
+// of the account.
 // Private function, not exposed to the API
-func updateAccountBalance(account uint, amount float32) {
-	var accountToUpdate models.Account
+func runningBalances(db *gorm.DB, account int, filter filters.Transactions) *gorm.DB {
+	// Every posting is dated as its transaction, adjustments have none
+	movements := db.Table("postings").
+		Select("COALESCE(transactions.date, journal_entries.date) AS date, journal_entries.transaction_id, postings.amount").
+		Joins("JOIN journal_entries ON journal_entries.id = postings.journal_entry_id").
+		Joins("LEFT JOIN transactions ON transactions.id = journal_entries.transaction_id").
+		Where("postings.account_id = ?", account)
+
+	balances := db.Table("(?) AS movements", movements).
+		Select("transaction_id, SUM(SUM(amount)) OVER (ORDER BY date, transaction_id) AS running_balance").
+		Group("date, transaction_id")
+
+	running := db.Model(&models.Transaction{}).
+		Select("transactions.*, balances.running_balance").
+		Joins("JOIN (?) AS balances ON balances.transaction_id = transactions.id", balances).
+		Where("transactions.account_id = ?", account)
+
+	return filter.Apply(db.Table("(?) AS transactions", running))
+}
+
+// knownSplits reports whether the categories of the splits of the
+// transaction are among the known ones
+// Private function, not exposed to the API
+func knownSplits(transaction *models.Transaction, known map[string]bool) bool {
+	for _, line := range transaction.Splits {
+		if !known[line.Category] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// loadAccountSplits sets the splits of the transactions of an account page
+// Private function, not exposed to the API
+func loadAccountSplits(rows []models.AccountTransaction) error {
+	transactions := make([]models.Transaction, 0, len(rows))
+	for _, row := range rows {
+		transactions = append(transactions, row.Transaction)
+	}
 
-	database.DB.Where("account = ?", account).First(&accountToUpdate)
-	accountToUpdate.Balance = accountToUpdate.Balance + amount
-	database.DB.Save(&accountToUpdate)
+	if err := splits.Load(database.DB, transactions); err != nil {
+		return err
+	}
+	for i := range rows {
+		rows[i].Splits = transactions[i].Splits
+	}
+
+	return nil
+}
+
+// postTransaction categorizes a new transaction, then posts it with its
+// journal entry and fees atomically and writes the response
+// Private function, not exposed to the API
+func postTransaction(c *gin.Context, transaction *models.Transaction) {
+	if err := splits.Validate(transaction.Amount, transaction.Splits); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Categorize the transaction, then post it, its journal entry and its fees atomically
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return posting.Post(tx, transaction, func(tx *gorm.DB, created models.Transaction) error {
+			return audit.Record(tx, c, models.AuditCreate, "transaction", created.ID, nil, created)
+		})
+	})
+	if err != nil {
+		respondLedgerError(c, err)
+		return
+	}
+
+	// Invalidate cache
+	invalidateCache("transactions_page_*", "account_transactions_page_*", "accounts_page_*")
+
+	c.JSON(http.StatusCreated, transaction)
+}
+
+// invalidateCache removes the cached lists matching the given patterns
+// Private function, not exposed to the API
+func invalidateCache(keysPatterns ...string) {
+	for _, keysPattern := range keysPatterns {
+		keys, err := cache.Rdb.Keys(cache.Ctx, keysPattern).Result()
+		if err == nil {
+			for _, key := range keys {
+				cache.Rdb.Del(cache.Ctx, key)
+			}
+		}
+	}
+}
+
+// respondLedgerError maps an error returned by the ledger to an HTTP response
+// Private function, not exposed to the API
+func respondLedgerError(c *gin.Context, err error) {
+	var violation *ledger.PolicyViolation
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "transaction not found"})
+	case errors.Is(err, ledger.ErrAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+	case errors.Is(err, ledger.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "category not found"})
+	case errors.Is(err, splits.ErrSumMismatch), errors.Is(err, splits.ErrInvalidSplit), errors.Is(err, ledger.ErrReservedCategory):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ledger.ErrAccountFrozen), errors.Is(err, ledger.ErrAccountDormant), errors.Is(err, ledger.ErrAccountClosed),
+		errors.Is(err, closing.ErrPeriodClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &violation):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": violation.Error(), "reason": violation})
+	case errors.Is(err, ledger.ErrTransferTransaction):
+		c.JSON(http.StatusConflict, gin.H{"error": "transaction belongs to a transfer, reverse the transfer instead"})
+	case errors.Is(err, ledger.ErrAlreadyReversed), errors.Is(err, ledger.ErrReversedTransaction),
+		errors.Is(err, ledger.ErrConcurrentUpdate):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to post transaction"})
+	}
+}
+
+// reversalChain returns every transaction linked to the given one by
+// reversals, starting with the original transaction
+// Private function, not exposed to the API
+func reversalChain(transaction models.Transaction) ([]models.Transaction, error) {
+	// Walk back to the original transaction
+	for transaction.ReversalOf != nil {
+		var previous models.Transaction
+		if err := database.DB.Where("id = ?", *transaction.ReversalOf).First(&previous).Error; err != nil {
+			return nil, err
+		}
+		transaction = previous
+	}
+
+	// Then follow the reversals forward
+	chain := []models.Transaction{transaction}
+	for transaction.ReversedBy != nil {
+		var next models.Transaction
+		if err := database.DB.Where("id = ?", *transaction.ReversedBy).First(&next).Error; err != nil {
+			return nil, err
+		}
+		transaction = next
+		chain = append(chain, transaction)
+	}
+
+	return chain, nil
+}
+
+// categoryCode returns the category to store for the given code, lowercased
+// as the categories are, none when it is empty
+// Private function, not exposed to the API
+func categoryCode(code string) *string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		return nil
+	}
+
+	return &code
+}
+
+// today returns the current date at midnight UTC
+// Private function, not exposed to the API
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
 }
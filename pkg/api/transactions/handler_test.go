@@ -1,17 +1,30 @@
 package transactions
 
 import (
+	"bytes"
+	"context"
+	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/cache"
 	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/filters"
+	"github.com/wjoseperez20/zenwallet/pkg/ledger"
 	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"github.com/wjoseperez20/zenwallet/pkg/pagination"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -31,6 +44,7 @@ func TestFindTransaction_SuccessfulRequest(t *testing.T) {
 		WithArgs("1").
 		WillReturnRows(sqlmock.NewRows([]string{"id", "amount", "date", "account", "created_at", "updated_at"}).
 			AddRow(mockTransaction.ID, mockTransaction.Amount, mockTransaction.Date, mockTransaction.Account, mockTransaction.CreatedAt, mockTransaction.UpdatedAt))
+	expectSplits(dbMock, 1)
 
 	// When
 	w := performRequest(r, "GET", "/transactions/1")
@@ -69,6 +83,634 @@ func TestFindTransaction_NotFound(t *testing.T) {
 	assert.Equal(t, expected, w.Body.String())
 }
 
+func TestReverseTransaction_NotFound(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/transactions/:id/reverse", ReverseTransaction)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`SELECT \* FROM "transactions" WHERE id = (.+) ORDER BY "transactions"."id" LIMIT 1 FOR UPDATE`).
+		WithArgs("999").
+		WillReturnError(gorm.ErrRecordNotFound)
+	dbMock.ExpectRollback()
+
+	// When
+	w := performRequest(r, "POST", "/transactions/999/reverse")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Then
+	expected := `{"error":"transaction not found"}`
+	assert.Equal(t, expected, w.Body.String())
+}
+
+func TestReverseTransaction_AlreadyReversed(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/transactions/:id/reverse", ReverseTransaction)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`SELECT \* FROM "transactions" WHERE id = (.+) ORDER BY "transactions"."id" LIMIT 1 FOR UPDATE`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "amount", "account_id", "reversed_by_id"}).
+			AddRow(1, "10.00", 10001, 2))
+	dbMock.ExpectRollback()
+
+	// When
+	w := performRequest(r, "POST", "/transactions/1/reverse")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Then
+	expected := `{"error":"transaction already reversed"}`
+	assert.Equal(t, expected, w.Body.String())
+
+	// Verify all expectations were met
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindTransaction_ReversalChain(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/transactions/:id", FindTransaction)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	columns := []string{"id", "amount", "account_id", "reversal_of_id", "reversed_by_id"}
+	dbMock.ExpectQuery(`SELECT \* FROM "transactions" WHERE id = (.+) ORDER BY "transactions"."id" LIMIT 1`).
+		WithArgs("2").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(2, "-10.00", 10001, 1, nil))
+	expectSplits(dbMock, 2)
+	dbMock.ExpectQuery(`SELECT \* FROM "transactions" WHERE id = (.+) ORDER BY "transactions"."id" LIMIT 1`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "10.00", 10001, nil, 2))
+	dbMock.ExpectQuery(`SELECT \* FROM "transactions" WHERE id = (.+) ORDER BY "transactions"."id" LIMIT 1`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(2, "-10.00", 10001, 1, nil))
+
+	// When
+	w := performRequest(r, "GET", "/transactions/2")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var expected models.Transaction
+	err := json.Unmarshal(w.Body.Bytes(), &expected)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, expected.ReversalChain, 2)
+	require.Equal(t, 1, expected.ReversalChain[0].ID)
+	require.Equal(t, 2, expected.ReversalChain[1].ID)
+
+	// Verify all expectations were met
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateTransaction_AmountChange(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.PUT("/transactions/:id", UpdateTransaction)
+
+	incomingTransaction := models.UpdateTransaction{Account: 10001, Date: "2023-11-25", Amount: money.MustParse("150")}
+
+	setupUnavailableCache(t)
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	expectLockedTransaction(dbMock, 1, "100.00", 10001, "2023-11-25")
+	expectSplits(dbMock, 1)
+	expectClosedThrough(dbMock, nil)
+	expectClosedThrough(dbMock, nil)
+	expectAccount(dbMock, 10001)
+	expectLockedAccount(dbMock, 10001, "100.00")
+	dbMock.ExpectExec(`UPDATE "transactions" SET (.+) WHERE "id" = (.+)`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectJournalEntry(dbMock, 1, 5)
+	dbMock.ExpectQuery(`INSERT INTO "postings"`).
+		WithArgs(5, 10001, "50.00", sqlmock.AnyArg(), 5, ledger.CashInAccount, "-50.00", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	expectBalanceRefresh(dbMock, 10001, "150.00")
+	expectAudit(dbMock, models.AuditUpdate, "transaction", "1")
+	dbMock.ExpectCommit()
+
+	// When
+	w := performRequest(r, "PUT", "/transactions/1", toJSON(incomingTransaction))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var expected models.Transaction
+	err := json.Unmarshal(w.Body.Bytes(), &expected)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("150"), expected.Amount)
+	require.Equal(t, 10001, expected.Account)
+
+	// Verify all expectations were met
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateTransaction_AccountChange(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.PUT("/transactions/:id", UpdateTransaction)
+
+	incomingTransaction := models.UpdateTransaction{Account: 10002, Date: "2023-11-25", Amount: money.MustParse("100")}
+
+	setupUnavailableCache(t)
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	expectLockedTransaction(dbMock, 1, "100.00", 10001, "2023-11-25")
+	expectSplits(dbMock, 1)
+	expectClosedThrough(dbMock, nil)
+	expectClosedThrough(dbMock, nil)
+	expectAccount(dbMock, 10002)
+	expectLockedAccount(dbMock, 10001, "100.00")
+	expectLockedAccount(dbMock, 10002, "0.00")
+	expectPolicy(dbMock, 10001)
+	dbMock.ExpectExec(`UPDATE "transactions" SET "amount"=(.+),"date"=(.+),"account_id"=(.+),"description"=(.+),"counterparty"=(.+),"category"=(.+),"external_reference"=(.+),"metadata"=(.+),"updated_at"=(.+) WHERE "id" = (.+)`).
+		WithArgs("100.00", sqlmock.AnyArg(), 10002, "", "", nil, "", nil, sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectJournalEntry(dbMock, 1, 5)
+	dbMock.ExpectQuery(`INSERT INTO "postings"`).
+		WithArgs(5, 10001, "-100.00", sqlmock.AnyArg(), 5, 10002, "100.00", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	expectBalanceRefresh(dbMock, 10001, "0.00")
+	expectBalanceRefresh(dbMock, 10002, "100.00")
+	expectAudit(dbMock, models.AuditUpdate, "transaction", "1")
+	dbMock.ExpectCommit()
+
+	// When
+	w := performRequest(r, "PUT", "/transactions/1", toJSON(incomingTransaction))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var expected models.Transaction
+	err := json.Unmarshal(w.Body.Bytes(), &expected)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, 10002, expected.Account)
+
+	// Verify all expectations were met
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateTransaction_DateChange(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.PUT("/transactions/:id", UpdateTransaction)
+
+	incomingTransaction := models.UpdateTransaction{Account: 10001, Date: "2023-12-01", Amount: money.MustParse("100")}
+
+	setupUnavailableCache(t)
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	expectLockedTransaction(dbMock, 1, "100.00", 10001, "2023-11-25")
+	expectSplits(dbMock, 1)
+	expectClosedThrough(dbMock, nil)
+	expectClosedThrough(dbMock, nil)
+	expectAccount(dbMock, 10001)
+	dbMock.ExpectExec(`UPDATE "transactions" SET (.+) WHERE "id" = (.+)`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectAudit(dbMock, models.AuditUpdate, "transaction", "1")
+	dbMock.ExpectCommit()
+
+	// When
+	w := performRequest(r, "PUT", "/transactions/1", toJSON(incomingTransaction))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var expected models.Transaction
+	err := json.Unmarshal(w.Body.Bytes(), &expected)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, "2023-12-01", expected.Date.Format("2006-01-02"))
+
+	// Verify all expectations were met, no journal entry is recorded
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFindTransactions_InvalidSign(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/transactions", FindTransactions)
+
+	// When
+	w := performRequest(r, "GET", "/transactions?sign=positive")
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"error":"sign must be debit or credit"}`, w.Body.String())
+}
+
+func TestCreateTransaction_CategoryNotFound(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/transactions", CreateTransaction)
+
+	incomingTransaction := models.CreateTransaction{Account: 10001, Date: "2023-11-25", Amount: money.MustParse("-12.50"), Category: " Travel "}
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`SELECT \* FROM "rules" WHERE enabled = (.+) ORDER BY priority, id`).
+		WithArgs(true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "priority", "enabled", "description_contains", "category"}))
+	expectClosedThrough(dbMock, nil)
+	expectLockedAccount(dbMock, 10001, "100.00")
+	dbMock.ExpectQuery(`SELECT count\(\*\) FROM "categories" WHERE code = (.+)`).
+		WithArgs("travel").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	dbMock.ExpectRollback()
+
+	// When
+	w := performRequest(r, "POST", "/transactions", toJSON(incomingTransaction))
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"error":"category not found"}`, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCreateTransaction_ClosedPeriod(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/transactions", CreateTransaction)
+
+	incomingTransaction := models.CreateTransaction{Account: 10001, Date: "2023-11-25", Amount: money.MustParse("-12.50")}
+	closedThrough := time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`SELECT \* FROM "rules" WHERE enabled = (.+) ORDER BY priority, id`).
+		WithArgs(true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "priority", "enabled", "description_contains", "category"}))
+	expectClosedThrough(dbMock, &closedThrough)
+	dbMock.ExpectQuery(`SELECT count\(\*\) FROM "periods" WHERE date = (.+) AND status = (.+)`).
+		WithArgs(time.Date(2023, 11, 25, 0, 0, 0, 0, time.UTC), models.PeriodOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	dbMock.ExpectRollback()
+
+	// When
+	w := performRequest(r, "POST", "/transactions", toJSON(incomingTransaction))
+
+	// Then
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, `{"error":"period is closed, reopen it to post on this date"}`, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestBulkCreateTransactions_AtomicInvalidItem(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/transactions/bulk", BulkCreateTransactions)
+
+	input := models.BulkCreateTransactions{Transactions: []models.CreateTransaction{
+		{Account: 10001, Date: "2023-11-25", Amount: money.MustParse("-12.50")},
+		{Account: 10001, Date: "25/11/2023", Amount: money.MustParse("8.00")},
+	}}
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	dbMock.ExpectRollback()
+
+	// When
+	w := performRequest(r, "POST", "/transactions/bulk", toJSON(input))
+
+	// Then
+	var bulk models.BulkTransactions
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bulk))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, models.BulkAtomic, bulk.Mode)
+	assert.Equal(t, 0, bulk.Created)
+	assert.Equal(t, 1, bulk.Failed)
+	assert.Equal(t, models.BulkItemNotPosted, bulk.Results[0].Status)
+	assert.Equal(t, models.BulkItemFailed, bulk.Results[1].Status)
+	assert.Equal(t, "Invalid date format", bulk.Results[1].Error)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestBulkCreateTransactions_BestEffortClosedPeriod(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/transactions/bulk", BulkCreateTransactions)
+
+	input := models.BulkCreateTransactions{Mode: models.BulkBestEffort, Transactions: []models.CreateTransaction{
+		{Account: 10001, Date: "2023-11-25", Amount: money.MustParse("-12.50")},
+		{Date: "2023-11-25", Amount: money.MustParse("8.00")},
+	}}
+	closedThrough := time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`SELECT \* FROM "rules" WHERE enabled = (.+) ORDER BY priority, id`).
+		WithArgs(true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "priority", "enabled", "description_contains", "category"}))
+	expectClosedThrough(dbMock, &closedThrough)
+	dbMock.ExpectQuery(`SELECT "date" FROM "periods" WHERE date IN (.+) AND status = (.+)`).
+		WithArgs(time.Date(2023, 11, 25, 0, 0, 0, 0, time.UTC), models.PeriodOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"date"}))
+	dbMock.ExpectCommit()
+
+	// When
+	w := performRequest(r, "POST", "/transactions/bulk", toJSON(input))
+
+	// Then
+	var bulk models.BulkTransactions
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bulk))
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	assert.Equal(t, 2, bulk.Failed)
+	assert.Equal(t, "period is closed, reopen it to post on this date", bulk.Results[0].Error)
+	assert.Contains(t, bulk.Results[1].Error, "'Account' failed on the 'required' tag")
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCreateTransaction_InvalidDate(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/transactions", CreateTransaction)
+
+	incomingTransaction := models.CreateTransaction{Account: 10001, Date: "25/11/2023", Amount: money.MustParse("-80.00")}
+
+	// When
+	w := performRequest(r, "POST", "/transactions", toJSON(incomingTransaction))
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"error":"Invalid date format"}`, w.Body.String())
+}
+
+func TestCreateTransaction_SplitsSumMismatch(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/transactions", CreateTransaction)
+
+	incomingTransaction := models.CreateTransaction{Account: 10001, Date: "2023-11-25", Amount: money.MustParse("-80.00"), Splits: []models.CreateTransactionSplit{
+		{Amount: money.MustParse("-60.00"), Category: "groceries"},
+		{Amount: money.MustParse("-15.00"), Category: "housing", Note: "detergent"},
+	}}
+
+	// When
+	w := performRequest(r, "POST", "/transactions", toJSON(incomingTransaction))
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"error":"splits must sum to the amount of the transaction"}`, w.Body.String())
+}
+
+func TestUpdateTransactionSplits_SumMismatch(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.PUT("/transactions/:id/splits", UpdateTransactionSplits)
+
+	input := models.UpdateTransactionSplits{Splits: []models.CreateTransactionSplit{
+		{Amount: money.MustParse("60.00"), Category: "groceries"},
+		{Amount: money.MustParse("30.00"), Category: "housing"},
+	}}
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	expectLockedTransaction(dbMock, 1, "100.00", 10001, "2023-11-25")
+	expectSplits(dbMock, 1)
+	dbMock.ExpectRollback()
+
+	// When
+	w := performRequest(r, "PUT", "/transactions/1/splits", toJSON(input))
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"error":"splits must sum to the amount of the transaction"}`, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCreateAccountTransaction_AccountNotFound(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/accounts/:account/transactions", CreateAccountTransaction)
+
+	incomingTransaction := models.CreateAccountTransaction{Date: "2023-11-25", Amount: money.MustParse("-12.50")}
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs("999").
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	// When
+	w := performRequest(r, "POST", "/accounts/999/transactions", toJSON(incomingTransaction))
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, `{"error":"account not found"}`, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestFindAccountTransactionSummary(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/accounts/:account/transactions/summary", FindAccountTransactionSummary)
+
+	from := time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs("10001").
+		WillReturnRows(sqlmock.NewRows([]string{"account"}).AddRow(10001))
+	dbMock.ExpectQuery(`SELECT COUNT\(CASE WHEN amount < 0 THEN 1 END\) AS debit_count,(.+) FROM "transactions" WHERE account_id = (.+) AND date >= (.+) AND date <= (.+) AND amount < 0`).
+		WithArgs(10001, from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"debit_count", "credit_count", "total_debits", "total_credits"}).AddRow(3, 0, "42.50", "0"))
+	expectBalanceAsOf(dbMock, 10001, from.AddDate(0, 0, -1), "100.00")
+	expectBalanceAsOf(dbMock, 10001, to, "57.50")
+
+	// When
+	w := performRequest(r, "GET", "/accounts/10001/transactions/summary?from=2023-11-01&to=2023-11-30&sign=debit")
+
+	// Then
+	assert.Equal(t, http.StatusOK, w.Code)
+	expected := `{"account":10001,"from":"2023-11-01","to":"2023-11-30","transaction_count":3,"debit_count":3,"credit_count":0,` +
+		`"total_debits":42.50,"total_credits":0.00,"net":-42.50,"opening_balance":100.00,"closing_balance":57.50}`
+	assert.Equal(t, expected, w.Body.String())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRunningBalances(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	filter, err := filters.ParseTransactions(url.Values{"category": {"groceries"}, "sort": {"-date"}})
+	require.NoError(t, err)
+
+	dbMock.ExpectQuery(`SELECT \* FROM \(SELECT transactions.\*, balances.running_balance FROM "transactions" JOIN \((.+)\) AS balances ON balances.transaction_id = transactions.id WHERE transactions.account_id = \$2\) AS transactions WHERE \(category = \$3 AND NOT EXISTS (.+)\) OR EXISTS (.+) ORDER BY date DESC,id DESC LIMIT 3`).
+		WithArgs(10001, 10001, "groceries", "groceries").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "amount", "running_balance"}).
+			AddRow(9, 10001, "-20.00", "80.00").
+			AddRow(4, 10001, "-5.25", "130.00"))
+
+	// When
+	page, err := pagination.Fetch(runningBalances(gormDB, 10001, filter), pagination.Params{Limit: 2}, filter.Order(),
+		func(transaction models.AccountTransaction) pagination.Cursor {
+			return filter.Cursor(transaction.Transaction)
+		})
+
+	// Then
+	require.NoError(t, err)
+	require.False(t, page.HasMore)
+	require.Len(t, page.Data, 2)
+	require.Equal(t, money.MustParse("80.00"), page.Data[0].RunningBalance)
+	require.Equal(t, money.MustParse("-5.25"), page.Data[1].Amount)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRunningBalances_ReconciledAccount(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	filter, err := filters.ParseTransactions(url.Values{})
+	require.NoError(t, err)
+
+	// The account got a reconciliation adjustment of 10.00 on the day of the
+	// second transaction, which is part of its balance of 90.00
+	dbMock.ExpectQuery(`SELECT \* FROM \(SELECT transactions.\*, balances.running_balance FROM "transactions" JOIN \(SELECT transaction_id, SUM\(SUM\(amount\)\) OVER \(ORDER BY date, transaction_id\) AS running_balance FROM \(SELECT COALESCE\(transactions.date, journal_entries.date\) AS date, journal_entries.transaction_id, postings.amount FROM "postings" JOIN journal_entries ON journal_entries.id = postings.journal_entry_id LEFT JOIN transactions ON transactions.id = journal_entries.transaction_id WHERE postings.account_id = \$1\) AS movements GROUP BY date, transaction_id\) AS balances ON balances.transaction_id = transactions.id WHERE transactions.account_id = \$2\) AS transactions ORDER BY id LIMIT 3`).
+		WithArgs(10001, 10001).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "amount", "running_balance"}).
+			AddRow(1, 10001, "100.00", "100.00").
+			AddRow(2, 10001, "-20.00", "90.00"))
+
+	// When
+	page, err := pagination.Fetch(runningBalances(gormDB, 10001, filter), pagination.Params{Limit: 2}, filter.Order(),
+		func(transaction models.AccountTransaction) pagination.Cursor {
+			return filter.Cursor(transaction.Transaction)
+		})
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, page.Data, 2)
+	require.Equal(t, money.MustParse("90.00"), page.Data[1].RunningBalance)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUpdateTransaction_InvalidDate(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.PUT("/transactions/:id", UpdateTransaction)
+
+	incomingTransaction := models.UpdateTransaction{Account: 10001, Date: "25/11/2023", Amount: money.MustParse("100")}
+
+	// When
+	w := performRequest(r, "PUT", "/transactions/1", toJSON(incomingTransaction))
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, `{"error":"Invalid date format"}`, w.Body.String())
+}
+
+// expectLockedTransaction expects the transaction to be loaded with a row lock.
+func expectLockedTransaction(dbMock sqlmock.Sqlmock, id int, amount string, account int, date string) {
+	parseDate, _ := time.Parse("2006-01-02", date)
+	dbMock.ExpectQuery(`SELECT \* FROM "transactions" WHERE id = (.+) ORDER BY "transactions"."id" LIMIT 1 FOR UPDATE`).
+		WithArgs(strconv.Itoa(id)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "amount", "date", "account_id"}).
+			AddRow(id, amount, parseDate, account))
+}
+
+// expectSplits expects the splits of the transactions to be loaded, none is split.
+func expectSplits(dbMock sqlmock.Sqlmock, ids ...int) {
+	args := make([]driver.Value, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	dbMock.ExpectQuery(`SELECT \* FROM "transaction_splits" WHERE transaction_id IN (.+) ORDER BY id`).
+		WithArgs(args...).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "transaction_id", "amount", "category"}))
+}
+
+// expectClosedThrough expects the lookup of the last closed day
+func expectClosedThrough(dbMock sqlmock.Sqlmock, day *time.Time) {
+	rows := sqlmock.NewRows([]string{"max"}).AddRow(nil)
+	if day != nil {
+		rows = sqlmock.NewRows([]string{"max"}).AddRow(*day)
+	}
+	dbMock.ExpectQuery(`SELECT MAX\(date\) FROM "periods"`).WillReturnRows(rows)
+}
+
+// expectBalanceAsOf expects the computation of the balance of the account
+// at the end of day, without snapshots
+func expectBalanceAsOf(dbMock sqlmock.Sqlmock, account int, day time.Time, balance string) {
+	dbMock.ExpectQuery(`SELECT \* FROM "balance_snapshots"`).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "transactions" WHERE account_id = (.+) AND date <= (.+)`).
+		WithArgs(account, day).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(balance))
+}
+
+// expectAccount expects the existence of the account to be checked.
+func expectAccount(dbMock sqlmock.Sqlmock, account int) {
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs(account).
+		WillReturnRows(sqlmock.NewRows([]string{"account"}).AddRow(account))
+}
+
+// expectLockedAccount expects the account to be locked before it is debited.
+func expectLockedAccount(dbMock sqlmock.Sqlmock, account int, availableBalance string) {
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1 FOR UPDATE`).
+		WithArgs(account).
+		WillReturnRows(sqlmock.NewRows([]string{"account", "balance", "available_balance"}).
+			AddRow(account, availableBalance, availableBalance))
+}
+
+// expectAudit expects an audit entry to be recorded for the entity.
+func expectAudit(dbMock sqlmock.Sqlmock, action string, entity string, id string) {
+	dbMock.ExpectQuery(`INSERT INTO "audit_entries"`).
+		WithArgs("", action, entity, id, sqlmock.AnyArg(), sqlmock.AnyArg(), "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+}
+
+// expectPolicy expects the account to have no policy, so the default one applies.
+func expectPolicy(dbMock sqlmock.Sqlmock, account int) {
+	dbMock.ExpectQuery(`SELECT \* FROM "account_policies" WHERE account_id = (.+) LIMIT 1`).
+		WithArgs(account).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "overdraft_limit"}))
+}
+
+// expectJournalEntry expects a journal entry to be recorded for the transaction.
+func expectJournalEntry(dbMock sqlmock.Sqlmock, transactionID int, entryID int) {
+	dbMock.ExpectQuery(`INSERT INTO "journal_entries"`).
+		WithArgs(transactionID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(entryID))
+}
+
+// expectBalanceRefresh expects the balance of the account to be derived from its postings.
+func expectBalanceRefresh(dbMock sqlmock.Sqlmock, account int, balance string) {
+	dbMock.ExpectQuery(`SELECT "account","version" FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs(account).
+		WillReturnRows(sqlmock.NewRows([]string{"account", "version"}).AddRow(account, 4))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "postings" WHERE account_id = (.+)`).
+		WithArgs(account).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(balance))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "holds" WHERE account_id = (.+) AND status = (.+)`).
+		WithArgs(account, models.HoldPending).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("0.00"))
+	dbMock.ExpectExec(`UPDATE "accounts" SET "available_balance"=(.+),"balance"=(.+),"version"=version \+ 1,"updated_at"=(.+) WHERE account = (.+) AND version = (.+)`).
+		WithArgs(balance, balance, sqlmock.AnyArg(), account, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
 // setupTestDatabase sets up a mock database for testing.
 func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
 	// Create a mock database for testing
@@ -84,11 +726,37 @@ func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
 	return dbMock, gormDB
 }
 
+// setupUnavailableCache points the cache to a Redis that cannot be reached,
+// so the handlers work without their cache as they do when Redis is down
+func setupUnavailableCache(t *testing.T) {
+	cache.Rdb = redis.NewClient(&redis.Options{
+		MaxRetries: -1,
+		Dialer: func(ctx context.Context, network string, addr string) (net.Conn, error) {
+			return nil, errors.New("cache unavailable")
+		},
+	})
+	t.Cleanup(func() {
+		cache.Rdb.Close()
+		cache.Rdb = nil
+	})
+}
+
 // performRequest performs an HTTP request and returns the response recorder.
-func performRequest(r http.Handler, method, path string) *httptest.ResponseRecorder {
-	req, _ := http.NewRequest(method, path, nil)
+func performRequest(r http.Handler, method, path string, requestBody ...[]byte) *httptest.ResponseRecorder {
+	var reqBody []byte
+	if len(requestBody) > 0 {
+		reqBody = requestBody[0]
+	}
+
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 
 	return w
 }
+
+func toJSON(v interface{}) []byte {
+	result, _ := json.Marshal(v)
+	return result
+}
@@ -0,0 +1,242 @@
+package transfers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/audit"
+	"github.com/wjoseperez20/zenwallet/pkg/cache"
+	"github.com/wjoseperez20/zenwallet/pkg/closing"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/ledger"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// @BasePath /api/v1
+
+// FindTransfer godoc
+// @Summary Find a transfer by ID
+// @Description Get details of a transfer and both of its transactions
+// @Tags Transfers
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} models.Transfer "Successfully retrieved transfer"
+// @Failure 404 {string} string "Transfer not found"
+// @Router /transfers/{id} [get]
+func FindTransfer(c *gin.Context) {
+	var transfer models.Transfer
+
+	if err := database.DB.Preload("Transactions").Where("id = ?", c.Param("id")).First(&transfer).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "transfer not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// FindTransfers godoc
+// @Summary Get all transfers with pagination
+// @Description Get a list of all transfers with optional pagination
+// @Tags Transfers
+// @Security JwtAuth
+// @Produce json
+// @Param offset query int false "Offset for pagination" default(0)
+// @Param limit query int false "Limit for pagination" default(10)
+// @Success 200 {array} models.Transfer "Successfully retrieved list of transfers"
+// @Router /transfers [get]
+func FindTransfers(c *gin.Context) {
+	var transfers []models.Transfer
+
+	// Get query params
+	offsetQuery := c.DefaultQuery("offset", "0")
+	limitQuery := c.DefaultQuery("limit", "10")
+
+	// Convert query params to integers
+	offset, err := strconv.Atoi(offsetQuery)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset format"})
+		return
+	}
+
+	limit, err := strconv.Atoi(limitQuery)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit format"})
+		return
+	}
+
+	// Create a cache key based on query params
+	cacheKey := "transfers_offset_" + offsetQuery + "_limit_" + limitQuery
+
+	// Try fetching the data from Redis first
+	cachedTransfers, err := cache.Rdb.Get(cache.Ctx, cacheKey).Result()
+	if err == nil {
+		err := json.Unmarshal([]byte(cachedTransfers), &transfers)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal cached data"})
+			return
+		}
+		c.JSON(http.StatusOK, transfers)
+		return
+	}
+
+	// If cache missed, fetch data from the database
+	database.DB.Preload("Transactions").Offset(offset).Limit(limit).Find(&transfers)
+
+	// Serialize transfers object and store it in Redis
+	serializedTransfers, err := json.Marshal(transfers)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal data"})
+		return
+	}
+	err = cache.Rdb.Set(cache.Ctx, cacheKey, serializedTransfers, time.Minute).Err()
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set cache"})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+// CreateTransfer godoc
+// @Summary Transfer money between two accounts
+// @Description Debit the source account and credit the destination account atomically
+// @Tags Transfers
+// @Security JwtAuth
+// @Accept  json
+// @Produce  json
+// @Param   Idempotency-Key header string false "Key that makes retries of this request safe"
+// @Param   input     body   models.CreateTransfer   true   "Create transfer object"
+// @Success 201 {object} models.Transfer "Successfully created transfer"
+// @Failure 400 {string} string "Bad Request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "account not found"
+// @Failure 409 {string} string "account status rejects the transfer, period closed or request with the same Idempotency-Key in progress"
+// @Failure 422 {string} string "rejected by the account policy or Idempotency-Key reused with a different request"
+// @Router /transfers [post]
+func CreateTransfer(c *gin.Context) {
+	var input models.CreateTransfer
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be positive"})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+
+	transfer := models.Transfer{From: input.From, To: input.To, Date: date, Amount: input.Amount}
+
+	// Move the money and record both legs atomically
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := ledger.Transfer(tx, &transfer); err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditCreate, "transfer", transfer.ID, nil, transfer)
+	})
+	if err != nil {
+		respondTransferError(c, err)
+		return
+	}
+
+	invalidateCache()
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// ReverseTransfer godoc
+// @Summary Reverse a transfer by ID
+// @Description Move the money of the transfer back to its source account, with a reversal dated today
+// @Tags Transfers
+// @Security JwtAuth
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Success 201 {object} models.Transfer "Successfully created reversal transfer"
+// @Failure 404 {string} string "transfer not found"
+// @Failure 409 {string} string "transfer already reversed, account status rejects the reversal or period closed"
+// @Router /transfers/{id}/reverse [post]
+func ReverseTransfer(c *gin.Context) {
+	var reversal *models.Transfer
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var transfer models.Transfer
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", c.Param("id")).First(&transfer).Error; err != nil {
+			return err
+		}
+
+		before := transfer
+
+		var err error
+		if reversal, err = ledger.ReverseTransfer(tx, &transfer, time.Now().UTC().Truncate(24*time.Hour)); err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditReverse, "transfer", transfer.ID, before, reversal)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "transfer not found"})
+			return
+		}
+		respondTransferError(c, err)
+		return
+	}
+
+	invalidateCache()
+
+	c.JSON(http.StatusCreated, reversal)
+}
+
+// respondTransferError maps an error returned by the ledger to an HTTP response
+// Private function, not exposed to the API
+func respondTransferError(c *gin.Context, err error) {
+	var violation *ledger.PolicyViolation
+
+	switch {
+	case errors.Is(err, ledger.ErrAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "account not found"})
+	case errors.Is(err, ledger.ErrSameAccount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ledger.ErrAccountFrozen), errors.Is(err, ledger.ErrAccountDormant), errors.Is(err, ledger.ErrAccountClosed),
+		errors.Is(err, closing.ErrPeriodClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.As(err, &violation):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": violation.Error(), "reason": violation})
+	case errors.Is(err, ledger.ErrTransferReversed), errors.Is(err, ledger.ErrConcurrentUpdate):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to post transfer"})
+	}
+}
+
+// invalidateCache removes the cached transfer and transaction lists
+// Private function, not exposed to the API
+func invalidateCache() {
+	for _, keysPattern := range []string{"transfers_offset_*", "transactions_page_*", "account_transactions_page_*"} {
+		keys, err := cache.Rdb.Keys(cache.Ctx, keysPattern).Result()
+		if err == nil {
+			for _, key := range keys {
+				cache.Rdb.Del(cache.Ctx, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package transfers
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFindTransfer_NotFound(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.GET("/transfers/:id", FindTransfer)
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectQuery(`SELECT \* FROM "transfers" WHERE id = (.+) ORDER BY "transfers"."id" LIMIT 1`).
+		WithArgs("999").
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	// When
+	w := performRequest(r, "GET", "/transfers/999")
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	// Then
+	expected := `{"error":"transfer not found"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+func TestCreateTransfer_InsufficientFunds(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/transfers", CreateTransfer)
+
+	incomingTransfer := models.CreateTransfer{From: 10002, To: 10001, Date: "2023-11-25", Amount: money.MustParse("50")}
+
+	dbMock, gormDB := setupTestDatabase(t)
+	database.DB = gormDB
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`SELECT MAX\(date\) FROM "periods"`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1 FOR UPDATE`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account", "balance", "available_balance"}).AddRow(10001, "0.00", "0.00"))
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1 FOR UPDATE`).
+		WithArgs(10002).
+		WillReturnRows(sqlmock.NewRows([]string{"account", "balance", "available_balance"}).AddRow(10002, "60.00", "49.99"))
+	dbMock.ExpectQuery(`SELECT \* FROM "account_policies" WHERE account_id = (.+) LIMIT 1`).
+		WithArgs(10002).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "overdraft_limit"}))
+	dbMock.ExpectRollback()
+
+	// When
+	w := performRequest(r, "POST", "/transfers", toJSON(incomingTransfer))
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	// Then
+	expected := `{"error":"insufficient funds","reason":{"account":10002,"rule":"overdraft_limit","limit":0.00,"attempted":0.01}}`
+	require.Equal(t, expected, w.Body.String())
+
+	// Verify all expectations were met
+	if err := dbMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCreateTransfer_InvalidAmount(t *testing.T) {
+	// Given
+	r := gin.Default()
+	r.POST("/transfers", CreateTransfer)
+
+	incomingTransfer := models.CreateTransfer{From: 10002, To: 10001, Date: "2023-11-25", Amount: money.MustParse("-5")}
+
+	// When
+	w := performRequest(r, "POST", "/transfers", toJSON(incomingTransfer))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Then
+	expected := `{"error":"amount must be positive"}`
+	require.Equal(t, expected, w.Body.String())
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	// Create a mock database for testing
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// Replace the actual database with the mock database for testing
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
+
+// performRequest performs an HTTP request and returns the response recorder.
+func performRequest(router *gin.Engine, method, path string, requestBody ...[]byte) *httptest.ResponseRecorder {
+	var reqBody []byte
+	if len(requestBody) > 0 {
+		reqBody = requestBody[0]
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func toJSON(v interface{}) []byte {
+	result, _ := json.Marshal(v)
+	return result
+}
@@ -2,6 +2,7 @@ package users
 
 import (
 	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/audit"
 	"github.com/wjoseperez20/zenwallet/pkg/auth"
 	"github.com/wjoseperez20/zenwallet/pkg/database"
 	"github.com/wjoseperez20/zenwallet/pkg/models"
@@ -95,8 +96,15 @@ func RegisterUser(c *gin.Context) {
 	// Create new user
 	newUser := models.User{Username: internalUser.Username, Password: hashedPassword}
 
-	// Save the user to the database
-	if err := database.DB.Create(&newUser).Error; err != nil {
+	// Save the user to the database, the password is never audited
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newUser).Error; err != nil {
+			return err
+		}
+
+		return audit.Record(tx, c, models.AuditCreate, "user", newUser.Username, nil, gin.H{"username": newUser.Username})
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not save user"})
 		return
 	}
@@ -0,0 +1,60 @@
+// Package audit records the writes made through the API in the audit log.
+package audit
+
+import (
+	"fmt"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Record stores an entry for a write made by the request: the action
+// applied to the entity with the given ID, and its state before and after.
+// before is nil for creations and after for deletions. It should run in
+// the database transaction of the write so both are stored or neither.
+func Record(tx *gorm.DB, c *gin.Context, action string, entity string, id interface{}, before interface{}, after interface{}) error {
+	entry := models.AuditEntry{
+		Actor:     c.GetString("username"),
+		Action:    action,
+		Entity:    entity,
+		EntityID:  fmt.Sprint(id),
+		Before:    before,
+		After:     after,
+		RequestID: c.GetString("request_id"),
+		ClientIP:  c.ClientIP(),
+	}
+
+	return tx.Create(&entry).Error
+}
+
+// Change is the state of an entity before and after a write, for RecordAll
+type Change struct {
+	ID     interface{}
+	Before interface{}
+	After  interface{}
+}
+
+// RecordAll stores an entry for each of the changes made by the request to
+// entities of the same kind, as Record does, in batches
+func RecordAll(tx *gorm.DB, c *gin.Context, action string, entity string, changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	entries := make([]models.AuditEntry, 0, len(changes))
+	for _, change := range changes {
+		entries = append(entries, models.AuditEntry{
+			Actor:     c.GetString("username"),
+			Action:    action,
+			Entity:    entity,
+			EntityID:  fmt.Sprint(change.ID),
+			Before:    change.Before,
+			After:     change.After,
+			RequestID: c.GetString("request_id"),
+			ClientIP:  c.ClientIP(),
+		})
+	}
+
+	return tx.CreateInBatches(&entries, 500).Error
+}
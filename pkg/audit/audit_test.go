@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	// Given
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("POST", "/accounts", nil)
+	c.Request.RemoteAddr = "10.0.0.1:5000"
+	c.Set("username", "alice")
+	c.Set("request_id", "abc")
+
+	dbMock.ExpectQuery(`INSERT INTO "audit_entries"`).
+		WithArgs("alice", models.AuditCreate, "account", "1", nil, `{"name":"Main"}`, "abc", "10.0.0.1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	// When
+	err = Record(gormDB, c, models.AuditCreate, "account", 1, nil, map[string]string{"name": "Main"})
+
+	// Then
+	require.NoError(t, err)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
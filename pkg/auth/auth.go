@@ -24,10 +24,13 @@ func GenerateToken(username string) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour).Unix()
 
 	// Create the JWT claims, which includes the username and expiration time
-	claims := &jwt.StandardClaims{
-		// In JWT, the expiry time is expressed as unix milliseconds
-		ExpiresAt: expirationTime,
-		Issuer:    username,
+	claims := &Claims{
+		Username: username,
+		StandardClaims: jwt.StandardClaims{
+			// In JWT, the expiry time is expressed as unix milliseconds
+			ExpiresAt: expirationTime,
+			Issuer:    username,
+		},
 	}
 
 	// Declare the token with the algorithm used for signing, and the claims
@@ -0,0 +1,101 @@
+// Package balances computes the balance of an account at past dates from
+// the dates of its transactions and the snapshots of the closed days.
+package balances
+
+import (
+	"errors"
+	"fmt"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxDays bounds the number of days of a balance series
+const MaxDays = 366
+
+var (
+	ErrInvalidRange  = errors.New("from must not be after to")
+	ErrRangeTooLarge = fmt.Errorf("the range must not exceed %d days", MaxDays)
+)
+
+// AsOf returns the balance of the account at the end of day: the sum of
+// its transactions dated on or before it. The sum starts from the latest
+// snapshot of a closed day on or before day, unless a day up to that
+// snapshot was reopened since and the snapshot may be stale.
+func AsOf(db *gorm.DB, account int, day time.Time) (money.Amount, error) {
+	var snapshots []models.BalanceSnapshot
+	var balance money.Amount
+
+	err := db.Where("account_id = ? AND date <= ?", account, day).
+		Where("NOT EXISTS (SELECT 1 FROM periods WHERE status = ? AND periods.date <= balance_snapshots.date)", models.PeriodOpen).
+		Order("date DESC").
+		Limit(1).
+		Find(&snapshots).Error
+	if err != nil {
+		return money.Zero, err
+	}
+
+	query := db.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("account_id = ? AND date <= ?", account, day)
+	if len(snapshots) > 0 {
+		query = query.Where("date > ?", snapshots[0].Date)
+	}
+
+	if err := query.Scan(&balance).Error; err != nil {
+		return money.Zero, err
+	}
+
+	if len(snapshots) > 0 {
+		balance += snapshots[0].ClosingBalance
+	}
+
+	return balance, nil
+}
+
+// Daily returns the balance of the account at the end of every day from
+// from to to, both included
+func Daily(db *gorm.DB, account int, from time.Time, to time.Time) ([]models.BalancePoint, error) {
+	var movements []struct {
+		Date   time.Time
+		Amount money.Amount
+	}
+
+	if from.After(to) {
+		return nil, ErrInvalidRange
+	}
+	if to.Sub(from) >= MaxDays*24*time.Hour {
+		return nil, ErrRangeTooLarge
+	}
+
+	balance, err := AsOf(db, account, from.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	// The net movement of every day with transactions
+	err = db.Model(&models.Transaction{}).
+		Select("date, SUM(amount) AS amount").
+		Where("account_id = ? AND date >= ? AND date <= ?", account, from, to).
+		Group("date").
+		Order("date").
+		Scan(&movements).Error
+	if err != nil {
+		return nil, err
+	}
+
+	points := []models.BalancePoint{}
+	next := 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		for next < len(movements) && !movements[next].Date.After(day) {
+			balance += movements[next].Amount
+			next++
+		}
+
+		points = append(points, models.BalancePoint{Date: day.Format("2006-01-02"), Balance: balance})
+	}
+
+	return points, nil
+}
@@ -0,0 +1,90 @@
+package balances
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestAsOf(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	expectSnapshot(dbMock, 10023, date(2024, 3, 31), sqlmock.NewRows([]string{"account_id", "date", "closing_balance"}).
+		AddRow(10023, date(2024, 3, 28), "1000.00"))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "transactions" WHERE \(account_id = (.+) AND date <= (.+)\) AND date > (.+)`).
+		WithArgs(10023, date(2024, 3, 31), date(2024, 3, 28)).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow("250.75"))
+
+	// When
+	balance, err := AsOf(gormDB, 10023, date(2024, 3, 31))
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("1250.75"), balance)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestDaily(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	expectSnapshot(dbMock, 10023, date(2024, 3, 29), sqlmock.NewRows([]string{"account_id"}))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "transactions" WHERE account_id = (.+) AND date <= (.+)`).
+		WithArgs(10023, date(2024, 3, 29)).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow("100.00"))
+	dbMock.ExpectQuery(`SELECT date, SUM\(amount\) AS amount FROM "transactions" WHERE account_id = (.+) AND date >= (.+) AND date <= (.+) GROUP BY "date" ORDER BY date`).
+		WithArgs(10023, date(2024, 3, 30), date(2024, 4, 2)).
+		WillReturnRows(sqlmock.NewRows([]string{"date", "amount"}).
+			AddRow(date(2024, 3, 31), "-40.00").
+			AddRow(date(2024, 4, 2), "15.50"))
+
+	// When
+	points, err := Daily(gormDB, 10023, date(2024, 3, 30), date(2024, 4, 2))
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []models.BalancePoint{
+		{Date: "2024-03-30", Balance: money.MustParse("100.00")},
+		{Date: "2024-03-31", Balance: money.MustParse("60.00")},
+		{Date: "2024-04-01", Balance: money.MustParse("60.00")},
+		{Date: "2024-04-02", Balance: money.MustParse("75.50")},
+	}, points)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestDaily_InvalidRange(t *testing.T) {
+	_, gormDB := setupTestDatabase(t)
+
+	_, err := Daily(gormDB, 10023, date(2024, 4, 2), date(2024, 3, 30))
+	require.ErrorIs(t, err, ErrInvalidRange)
+
+	_, err = Daily(gormDB, 10023, date(2023, 1, 1), date(2024, 1, 2))
+	require.ErrorIs(t, err, ErrRangeTooLarge)
+}
+
+// expectSnapshot expects the lookup of the latest valid snapshot of the account
+func expectSnapshot(dbMock sqlmock.Sqlmock, account int, day time.Time, rows *sqlmock.Rows) {
+	dbMock.ExpectQuery(`SELECT \* FROM "balance_snapshots" WHERE \(account_id = (.+) AND date <= (.+)\) AND \(NOT EXISTS (.+)\) ORDER BY date DESC LIMIT 1`).
+		WithArgs(account, day, models.PeriodOpen).
+		WillReturnRows(rows)
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
+
+// date returns the given day at midnight UTC
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,137 @@
+// Package budgeting tracks the spending of the budgets and alerts the
+// clients when it reaches their thresholds.
+package budgeting
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"github.com/wjoseperez20/zenwallet/pkg/splits"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultThresholds are the thresholds of a budget created without any
+var DefaultThresholds = []int{80, 100}
+
+// Notify sends the alert of a budget. It is replaced in tests.
+var Notify = sendAlert
+
+// Period returns the first day of the month of the given date
+func Period(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Spent returns the sum of the debits of the budget category during the
+// month starting on period, on the accounts the budget covers. Only the
+// lines of a split transaction in the budget category count, and reversed
+// debits do not count since their money was given back.
+func Spent(db *gorm.DB, budget models.Budget, period time.Time) (money.Amount, error) {
+	var spent money.Amount
+
+	query := db.Table("(?) AS transactions", splits.Lines(db)).
+		Select("COALESCE(-SUM(amount), 0)").
+		Where("category = ? AND amount < 0 AND reversed_by_id IS NULL AND date >= ? AND date < ?", budget.Category, period, period.AddDate(0, 1, 0))
+	if budget.Account != nil {
+		query = query.Where("account_id = ?", *budget.Account)
+	} else {
+		query = query.Where("account_id IN (?)", db.Model(&models.Account{}).Select("account").Where("client = ?", budget.Client))
+	}
+
+	err := query.Scan(&spent).Error
+	return spent, err
+}
+
+// Progress returns the consumption of the budget during the month starting
+// on period, with the alerts already sent for it
+func Progress(db *gorm.DB, budget models.Budget, period time.Time) (*models.BudgetProgress, error) {
+	spent, err := Spent(db, budget, period)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := &models.BudgetProgress{
+		BudgetID:  budget.ID,
+		Period:    period.Format("2006-01"),
+		Limit:     budget.Limit,
+		Spent:     spent,
+		Remaining: budget.Limit - spent,
+		Percent:   percent(spent, budget.Limit),
+		Crossed:   Crossed(budget, spent),
+		Alerts:    []models.BudgetAlert{},
+	}
+
+	err = db.Where("budget_id = ? AND period = ?", budget.ID, period).Order("threshold").Find(&progress.Alerts).Error
+	return progress, err
+}
+
+// Crossed returns the thresholds of the budget the spent amount reached,
+// in ascending order
+func Crossed(budget models.Budget, spent money.Amount) []int {
+	crossed := []int{}
+
+	for _, threshold := range budget.Thresholds {
+		if spent.Cents()*100 >= budget.Limit.Cents()*int64(threshold) {
+			crossed = append(crossed, threshold)
+		}
+	}
+	sort.Ints(crossed)
+
+	return crossed
+}
+
+// CheckAlerts alerts every budget whose spending reached a threshold during
+// the month starting on period for the first time, and returns how many
+// alerts were sent. An alert that fails to be sent is retried on the next
+// call.
+func CheckAlerts(db *gorm.DB, period time.Time) (int, error) {
+	var budgets []models.Budget
+
+	if err := db.Order("id").Find(&budgets).Error; err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, budget := range budgets {
+		spent, err := Spent(db, budget, period)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, threshold := range Crossed(budget, spent) {
+			alert := models.BudgetAlert{BudgetID: budget.ID, Period: period, Threshold: threshold, Spent: spent}
+
+			// The alert is only recorded once it was sent
+			err := db.Transaction(func(tx *gorm.DB) error {
+				result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&alert)
+				if result.Error != nil || result.RowsAffected == 0 {
+					return result.Error
+				}
+
+				if err := Notify(tx, budget, alert); err != nil {
+					return err
+				}
+
+				sent++
+				return nil
+			})
+			if err != nil {
+				return sent, err
+			}
+		}
+	}
+
+	return sent, nil
+}
+
+// percent returns the share of the limit that was spent, rounded down
+// Private function, not exposed to the API
+func percent(spent money.Amount, limit money.Amount) int {
+	if limit <= 0 {
+		return 0
+	}
+
+	return int(spent.Cents() * 100 / limit.Cents())
+}
@@ -0,0 +1,130 @@
+package budgeting
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestCrossed(t *testing.T) {
+	// Given
+	budget := models.Budget{Limit: money.MustParse("500"), Thresholds: []int{100, 50, 80}}
+
+	// Then
+	require.Equal(t, []int{}, Crossed(budget, money.MustParse("249.99")))
+	require.Equal(t, []int{50, 80}, Crossed(budget, money.MustParse("400")))
+	require.Equal(t, []int{50, 80, 100}, Crossed(budget, money.MustParse("612.30")))
+}
+
+func TestPeriod(t *testing.T) {
+	require.Equal(t, time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC), Period(time.Date(2023, 11, 25, 18, 30, 0, 0, time.UTC)))
+}
+
+func TestSpent_ExcludesReversedDebits(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	period := time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)
+	account := 10001
+	budget := models.Budget{ID: 1, Account: &account, Category: "groceries", Limit: money.MustParse("500")}
+
+	// A debit of 80.00 was reversed, only the other one of 45.00 is left
+	dbMock.ExpectQuery(`SELECT COALESCE\(-SUM\(amount\), 0\) FROM \(SELECT (.+)transactions.reversed_by_id(.+) LEFT JOIN transaction_splits (.+)\) AS transactions WHERE \(category = (.+) AND amount < 0 AND reversed_by_id IS NULL AND date >= (.+) AND date < (.+)\) AND account_id = (.+)`).
+		WithArgs("groceries", period, period.AddDate(0, 1, 0), 10001).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow("45.00"))
+
+	// When
+	spent, err := Spent(gormDB, budget, period)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("45"), spent)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCheckAlerts_SendsEachThresholdOnce(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	period := time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	var notified []int
+	Notify = func(db *gorm.DB, budget models.Budget, alert models.BudgetAlert) error {
+		notified = append(notified, alert.Threshold)
+		return nil
+	}
+	defer func() { Notify = sendAlert }()
+
+	dbMock.ExpectQuery(`SELECT \* FROM "budgets" ORDER BY id`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "account_id", "category", "limit_amount", "thresholds"}).
+			AddRow(1, "Groceries", 10001, "groceries", "500.00", "[80,100]"))
+	dbMock.ExpectQuery(`SELECT COALESCE\(-SUM\(amount\), 0\) FROM \(SELECT (.+) LEFT JOIN transaction_splits (.+)\) AS transactions WHERE \(category = (.+) AND amount < 0 AND reversed_by_id IS NULL AND date >= (.+) AND date < (.+)\) AND account_id = (.+)`).
+		WithArgs("groceries", period, period.AddDate(0, 1, 0), 10001).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow("450.00"))
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`INSERT INTO "budget_alerts" (.+) ON CONFLICT DO NOTHING RETURNING "id"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	dbMock.ExpectCommit()
+
+	// When
+	sent, err := CheckAlerts(gormDB, period)
+
+	// Then
+	require.NoError(t, err)
+	require.Zero(t, sent)
+	require.Empty(t, notified)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCheckAlerts_NotifiesNewThreshold(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	period := time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	var notified []models.BudgetAlert
+	Notify = func(db *gorm.DB, budget models.Budget, alert models.BudgetAlert) error {
+		notified = append(notified, alert)
+		return nil
+	}
+	defer func() { Notify = sendAlert }()
+
+	dbMock.ExpectQuery(`SELECT \* FROM "budgets" ORDER BY id`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "client", "category", "limit_amount", "thresholds"}).
+			AddRow(2, "Dining out", "John Doe", "restaurants", "200.00", "[80,100]"))
+	dbMock.ExpectQuery(`SELECT COALESCE\(-SUM\(amount\), 0\) FROM \(SELECT (.+) LEFT JOIN transaction_splits (.+)\) AS transactions WHERE \(category = (.+) AND amount < 0 AND reversed_by_id IS NULL AND date >= (.+) AND date < (.+)\) AND account_id IN \(SELECT "account" FROM "accounts" WHERE client = (.+)\)`).
+		WithArgs("restaurants", period, period.AddDate(0, 1, 0), "John Doe").
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow("170.00"))
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`INSERT INTO "budget_alerts" (.+) ON CONFLICT DO NOTHING RETURNING "id"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	dbMock.ExpectCommit()
+
+	// When
+	sent, err := CheckAlerts(gormDB, period)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, 1, sent)
+	require.Len(t, notified, 1)
+	require.Equal(t, 80, notified[0].Threshold)
+	require.Equal(t, money.MustParse("170"), notified[0].Spent)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	// Create a mock database for testing
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// Replace the actual database with the mock database for testing
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
@@ -0,0 +1,66 @@
+package budgeting
+
+import (
+	"bytes"
+	"github.com/wjoseperez20/zenwallet/pkg/gmail"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"gopkg.in/gomail.v2"
+	"html/template"
+	"os"
+	"path/filepath"
+
+	"gorm.io/gorm"
+)
+
+// sendAlert emails the alert to every account the budget covers
+// Private function, not exposed to the API
+func sendAlert(db *gorm.DB, budget models.Budget, alert models.BudgetAlert) error {
+	var recipients []string
+
+	query := db.Model(&models.Account{}).Distinct("email").Where("email <> ''")
+	if budget.Account != nil {
+		query = query.Where("account = ?", *budget.Account)
+	} else {
+		query = query.Where("client = ?", budget.Client)
+	}
+	if err := query.Pluck("email", &recipients).Error; err != nil {
+		return err
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	basePath, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	budgetAlertTemplate, err := template.ParseFiles(filepath.Join(basePath, "assets/budget_alert_template.html"))
+	if err != nil {
+		return err
+	}
+
+	// Data for the emails template
+	budgetAlertData := map[string]interface{}{
+		"Budget":    budget.Name,
+		"Category":  budget.Category,
+		"Period":    alert.Period.Format("January 2006"),
+		"Threshold": alert.Threshold,
+		"Spent":     alert.Spent,
+		"Limit":     budget.Limit,
+		"Remaining": budget.Limit - alert.Spent,
+	}
+
+	var renderedBody bytes.Buffer
+	if err := budgetAlertTemplate.Execute(&renderedBody, budgetAlertData); err != nil {
+		return err
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", "zenwallet.app@gmail.com")
+	m.SetHeader("To", recipients...)
+	m.SetHeader("Subject", "Budget alert: "+budget.Name)
+	m.SetBody("text/html", renderedBody.String())
+
+	return gmail.Mailer.DialAndSend(m)
+}
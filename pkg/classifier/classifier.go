@@ -0,0 +1,266 @@
+// Package classifier categorizes transactions with the user-defined rules.
+package classifier
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// batchSize is the number of transactions loaded at once when rules are
+// evaluated against the existing transactions
+const batchSize = 500
+
+// Engine evaluates a set of rules in priority order
+type Engine struct {
+	rules []compiledRule
+}
+
+// compiledRule is a rule with its description pattern compiled
+type compiledRule struct {
+	models.Rule
+	pattern *regexp.Regexp
+}
+
+// Load returns an engine with every enabled rule
+func Load(db *gorm.DB) (*Engine, error) {
+	var rules []models.Rule
+
+	if err := db.Where("enabled = ?", true).Order("priority, id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	return NewEngine(rules...)
+}
+
+// NewEngine returns an engine evaluating the given rules in their order
+func NewEngine(rules ...models.Rule) (*Engine, error) {
+	engine := &Engine{}
+
+	for _, rule := range rules {
+		compiled := compiledRule{Rule: rule}
+		if rule.DescriptionPattern != "" {
+			pattern, err := regexp.Compile("(?i)" + rule.DescriptionPattern)
+			if err != nil {
+				return nil, err
+			}
+			compiled.pattern = pattern
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+
+	return engine, nil
+}
+
+// Apply classifies the transaction with the first matching rule and
+// returns it, or nil when no rule matches. The rule adds its tags, and
+// sets its category unless the transaction has an explicit one.
+func (e *Engine) Apply(transaction *models.Transaction) *models.Rule {
+	for i := range e.rules {
+		rule := &e.rules[i]
+		if !rule.matches(transaction) {
+			continue
+		}
+
+		if rule.Category != nil && (transaction.Category == nil || transaction.RuleID != nil) {
+			category := *rule.Category
+			id := rule.ID
+			transaction.Category = &category
+			transaction.RuleID = &id
+		}
+		transaction.Tags = mergeTags(transaction.Tags, rule.Tags)
+
+		return &rule.Rule
+	}
+
+	return nil
+}
+
+// Reclassify evaluates the rules against every transaction whose category
+// was not set explicitly and calls visit with the transactions whose
+// category or tags would change. Transactions that no longer match any rule
+// lose the category a rule gave them. It does not write anything.
+func (e *Engine) Reclassify(db *gorm.DB, visit func(before models.Transaction, after models.Transaction) error) error {
+	return forEachClassified(db, func(transaction models.Transaction) error {
+		after := e.reclassify(transaction)
+		if !changed(transaction, after) {
+			return nil
+		}
+
+		return visit(transaction, after)
+	})
+}
+
+// DryRun returns the transactions whose classification would change if the
+// candidate rule was saved and enabled, taking the priority of the other
+// enabled rules into account. At most limit transactions are returned,
+// along with the total number of affected transactions.
+func DryRun(db *gorm.DB, candidate models.Rule, limit int) ([]models.RuleMatch, int, error) {
+	var enabled []models.Rule
+
+	if err := db.Where("enabled = ?", true).Order("priority, id").Find(&enabled).Error; err != nil {
+		return nil, 0, err
+	}
+
+	current, err := NewEngine(enabled...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The candidate replaces the saved version of the rule it edits
+	candidates := []models.Rule{candidate}
+	for _, rule := range enabled {
+		if rule.ID != candidate.ID || candidate.ID == 0 {
+			candidates = append(candidates, rule)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Priority < candidates[j].Priority })
+
+	proposed, err := NewEngine(candidates...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	matches := []models.RuleMatch{}
+	total := 0
+	err = forEachClassified(db, func(transaction models.Transaction) error {
+		before, after := current.reclassify(transaction), proposed.reclassify(transaction)
+		if !changed(before, after) {
+			return nil
+		}
+
+		total++
+		if len(matches) < limit {
+			matches = append(matches, models.RuleMatch{
+				TransactionID:   transaction.ID,
+				Description:     transaction.Description,
+				CurrentCategory: before.Category,
+				Category:        after.Category,
+				CurrentTags:     before.Tags,
+				Tags:            after.Tags,
+			})
+		}
+		return nil
+	})
+
+	return matches, total, err
+}
+
+// reclassify returns the transaction as the rules would classify it if it
+// was created now, dropping the category a rule previously gave it
+// Private function, not exposed to the API
+func (e *Engine) reclassify(transaction models.Transaction) models.Transaction {
+	transaction.Tags = append([]string(nil), transaction.Tags...)
+	if transaction.RuleID != nil {
+		transaction.Category, transaction.RuleID = nil, nil
+	}
+
+	e.Apply(&transaction)
+	return transaction
+}
+
+// forEachClassified calls fn with every transaction whose category was
+// not set explicitly, in batches
+// Private function, not exposed to the API
+func forEachClassified(db *gorm.DB, fn func(transaction models.Transaction) error) error {
+	var transactions []models.Transaction
+
+	result := db.Where("(category IS NULL OR rule_id IS NOT NULL)").
+		FindInBatches(&transactions, batchSize, func(tx *gorm.DB, batch int) error {
+			for _, transaction := range transactions {
+				if err := fn(transaction); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	return result.Error
+}
+
+// matches reports whether the transaction meets every condition of the rule
+// Private function, not exposed to the API
+func (r *compiledRule) matches(transaction *models.Transaction) bool {
+	if r.Account != nil && *r.Account != transaction.Account {
+		return false
+	}
+	if r.MinAmount != nil && transaction.Amount < *r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != nil && transaction.Amount > *r.MaxAmount {
+		return false
+	}
+	if r.DescriptionContains != "" && !containsFold(transaction.Description, r.DescriptionContains) {
+		return false
+	}
+	if r.Counterparty != "" && !containsFold(transaction.Counterparty, r.Counterparty) {
+		return false
+	}
+	if r.pattern != nil && !r.pattern.MatchString(transaction.Description) {
+		return false
+	}
+
+	return true
+}
+
+// changed reports whether the rules changed the category or the tags
+// Private function, not exposed to the API
+func changed(before models.Transaction, after models.Transaction) bool {
+	if (before.Category == nil) != (after.Category == nil) {
+		return true
+	}
+	if before.Category != nil && *before.Category != *after.Category {
+		return true
+	}
+
+	return !sameTags(before.Tags, after.Tags)
+}
+
+// sameTags reports whether both lists hold the same tags, in any order
+// Private function, not exposed to the API
+func sameTags(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	counts := make(map[string]int, len(a))
+	for _, tag := range a {
+		counts[tag]++
+	}
+	for _, tag := range b {
+		if counts[tag] == 0 {
+			return false
+		}
+		counts[tag]--
+	}
+
+	return true
+}
+
+// mergeTags adds the tags that are not present yet, keeping their order
+// Private function, not exposed to the API
+func mergeTags(tags []string, added []string) []string {
+	for _, tag := range added {
+		present := false
+		for _, existing := range tags {
+			if existing == tag {
+				present = true
+				break
+			}
+		}
+		if !present {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// containsFold reports whether substr is within s, ignoring case
+// Private function, not exposed to the API
+func containsFold(s string, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,90 @@
+package classifier
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"testing"
+)
+
+func TestApply_FirstMatchingRuleWins(t *testing.T) {
+	// Given
+	engine, err := NewEngine(
+		models.Rule{ID: 1, Priority: 1, DescriptionContains: "uber eats", Category: strPtr("dining")},
+		models.Rule{ID: 2, Priority: 2, DescriptionContains: "UBER", Category: strPtr("travel")},
+	)
+	require.NoError(t, err)
+	transaction := models.Transaction{Description: "Uber Eats order", Amount: money.MustParse("-20")}
+
+	// When
+	rule := engine.Apply(&transaction)
+
+	// Then
+	require.NotNil(t, rule)
+	assert.Equal(t, 1, rule.ID)
+	assert.Equal(t, "dining", *transaction.Category)
+	assert.Equal(t, 1, *transaction.RuleID)
+}
+
+func TestApply_KeepsExplicitCategory(t *testing.T) {
+	// Given
+	engine, err := NewEngine(models.Rule{ID: 1, Counterparty: "acme", Category: strPtr("salary"), Tags: []string{"income"}})
+	require.NoError(t, err)
+	transaction := models.Transaction{Counterparty: "ACME Corp", Category: strPtr("other"), Tags: []string{"income", "monthly"}}
+
+	// When
+	engine.Apply(&transaction)
+
+	// Then
+	assert.Equal(t, "other", *transaction.Category)
+	assert.Nil(t, transaction.RuleID)
+	assert.Equal(t, []string{"income", "monthly"}, transaction.Tags)
+}
+
+func TestApply_AmountRangeAndPattern(t *testing.T) {
+	// Given
+	min, max := money.MustParse("-100"), money.MustParse("-10")
+	engine, err := NewEngine(models.Rule{ID: 3, DescriptionPattern: `^netflix\b`, MinAmount: &min, MaxAmount: &max, Tags: []string{"subscription"}})
+	require.NoError(t, err)
+	matching := models.Transaction{Description: "NETFLIX.COM", Amount: money.MustParse("-15.99")}
+	tooLarge := models.Transaction{Description: "Netflix gift card", Amount: money.MustParse("-150")}
+
+	// When
+	matched := engine.Apply(&matching)
+	missed := engine.Apply(&tooLarge)
+
+	// Then
+	require.NotNil(t, matched)
+	assert.Nil(t, matching.Category)
+	assert.Equal(t, []string{"subscription"}, matching.Tags)
+	assert.Nil(t, missed)
+	assert.Empty(t, tooLarge.Tags)
+}
+
+func TestChanged_SwappedTag(t *testing.T) {
+	// Given
+	before := models.Transaction{Category: strPtr("dining"), Tags: []string{"food", "weekly"}}
+	after := models.Transaction{Category: strPtr("dining"), Tags: []string{"food", "monthly"}}
+	reordered := models.Transaction{Category: strPtr("dining"), Tags: []string{"weekly", "food"}}
+
+	// When
+	swapped, moved := changed(before, after), changed(before, reordered)
+
+	// Then
+	assert.True(t, swapped)
+	assert.False(t, moved)
+}
+
+func TestNewEngine_InvalidPattern(t *testing.T) {
+	// When
+	_, err := NewEngine(models.Rule{ID: 1, DescriptionPattern: "(unclosed"})
+
+	// Then
+	assert.Error(t, err)
+}
+
+// strPtr returns a pointer to the given string.
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,416 @@
+// Package closing closes business days: it snapshots the balance of every
+// account at the end of the day and locks the day against back-dated
+// transactions until it is reopened.
+package closing
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// closingDelay is how many days a day stays open once it ended, so the
+// background jobs can still post their transactions dated on it
+const closingDelay = 1
+
+// snapshotBatchSize bounds the snapshots inserted per statement
+const snapshotBatchSize = 500
+
+var (
+	ErrPeriodClosed  = errors.New("period is closed, reopen it to post on this date")
+	ErrNotClosed     = errors.New("period is not closed yet")
+	ErrAlreadyOpen   = errors.New("period is already reopened")
+	ErrAlreadyClosed = errors.New("period is already closed")
+)
+
+// ClosedThrough returns the last closed day, or nil when no day was closed yet
+func ClosedThrough(tx *gorm.DB) (*time.Time, error) {
+	var last sql.NullTime
+
+	if err := tx.Model(&models.Period{}).Select("MAX(date)").Scan(&last).Error; err != nil {
+		return nil, err
+	}
+	if !last.Valid {
+		return nil, nil
+	}
+
+	return &last.Time, nil
+}
+
+// EnsureOpen returns ErrPeriodClosed when transactions dated on day are
+// locked: the day is on or before the last closed day and was not reopened
+func EnsureOpen(tx *gorm.DB, day time.Time) error {
+	through, err := ClosedThrough(tx)
+	if err != nil || through == nil || day.After(*through) {
+		return err
+	}
+
+	var reopened int64
+	err = tx.Model(&models.Period{}).Where("date = ? AND status = ?", day, models.PeriodOpen).Count(&reopened).Error
+	if err != nil || reopened > 0 {
+		return err
+	}
+
+	return ErrPeriodClosed
+}
+
+// LockedDays returns the days among days that transactions cannot be
+// dated on, as EnsureOpen does for each of them, keyed as YYYY-MM-DD
+func LockedDays(tx *gorm.DB, days []time.Time) (map[string]bool, error) {
+	locked := make(map[string]bool)
+
+	through, err := ClosedThrough(tx)
+	if err != nil || through == nil {
+		return locked, err
+	}
+
+	var closed []time.Time
+	seen := make(map[string]bool)
+	for _, day := range days {
+		if key := day.Format("2006-01-02"); !day.After(*through) && !seen[key] {
+			seen[key] = true
+			closed = append(closed, day)
+		}
+	}
+	if len(closed) == 0 {
+		return locked, nil
+	}
+
+	var reopened []time.Time
+	err = tx.Model(&models.Period{}).Where("date IN ? AND status = ?", closed, models.PeriodOpen).Pluck("date", &reopened).Error
+	if err != nil {
+		return nil, err
+	}
+
+	open := make(map[string]bool)
+	for _, day := range reopened {
+		open[day.Format("2006-01-02")] = true
+	}
+	for _, day := range closed {
+		if key := day.Format("2006-01-02"); !open[key] {
+			locked[key] = true
+		}
+	}
+
+	return locked, nil
+}
+
+// Find returns the period of day. The days before the first one closed by
+// the job have no record and are returned as closed.
+func Find(tx *gorm.DB, day time.Time) (models.Period, error) {
+	var periods []models.Period
+
+	through, err := ClosedThrough(tx)
+	if err != nil {
+		return models.Period{}, err
+	}
+	if through == nil || day.After(*through) {
+		return models.Period{}, ErrNotClosed
+	}
+
+	if err := tx.Where("date = ?", day).Limit(1).Find(&periods).Error; err != nil {
+		return models.Period{}, err
+	}
+	if len(periods) == 0 {
+		return models.Period{Date: day, Status: models.PeriodClosed}, nil
+	}
+
+	return periods[0], nil
+}
+
+// OpenOn returns the first day on or after day that transactions can be
+// dated on, so the background jobs post what was due on a day closed since
+// on the first open day instead
+func OpenOn(tx *gorm.DB, day time.Time) (time.Time, error) {
+	through, err := ClosedThrough(tx)
+	if err != nil || through == nil || day.After(*through) {
+		return day, err
+	}
+
+	var reopened []time.Time
+	err = tx.Model(&models.Period{}).
+		Where("date >= ? AND date <= ? AND status = ?", day, *through, models.PeriodOpen).
+		Order("date").
+		Limit(1).
+		Pluck("date", &reopened).Error
+	if err != nil {
+		return day, err
+	}
+	if len(reopened) > 0 {
+		return reopened[0], nil
+	}
+
+	return through.AddDate(0, 0, 1), nil
+}
+
+// Run closes every day that ended more than closingDelay days before
+// today and was not closed yet, in order, and returns how many days were
+// closed. Days the background jobs still have to post on stay open, and so
+// do the days after them. The first run starts from the first day with
+// transactions.
+func Run(db *gorm.DB, today time.Time) (int, error) {
+	last := today.AddDate(0, 0, -1-closingDelay)
+
+	pending, err := pendingFrom(db)
+	if err != nil {
+		return 0, err
+	}
+	if pending != nil && !pending.After(last) {
+		last = pending.AddDate(0, 0, -1)
+	}
+
+	through, err := ClosedThrough(db)
+	if err != nil {
+		return 0, err
+	}
+
+	day := last
+	if through != nil {
+		day = through.AddDate(0, 0, 1)
+	} else {
+		var first sql.NullTime
+		if err := db.Model(&models.Transaction{}).Select("MIN(date)").Scan(&first).Error; err != nil {
+			return 0, err
+		}
+		if first.Valid && first.Time.Before(last) {
+			day = first.Time
+		}
+	}
+
+	closed := 0
+	for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return Close(tx, day)
+		})
+		if err != nil {
+			return closed, err
+		}
+		closed++
+	}
+
+	return closed, nil
+}
+
+// Close snapshots the balances of day and marks it closed. When a reopened
+// day is closed again, the snapshots of the closed days following it are
+// computed again too, up to the next reopened day.
+func Close(tx *gorm.DB, day time.Time) error {
+	through, err := ClosedThrough(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := snapshot(tx, day); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	period := models.Period{Date: day, Status: models.PeriodClosed, ClosedAt: &now}
+	err = tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "closed_at", "updated_at"}),
+	}).Create(&period).Error
+	if err != nil {
+		return err
+	}
+
+	if through == nil {
+		return nil
+	}
+
+	for next := day.AddDate(0, 0, 1); !next.After(*through); next = next.AddDate(0, 0, 1) {
+		var reopened int64
+		err := tx.Model(&models.Period{}).Where("date = ? AND status = ?", next, models.PeriodOpen).Count(&reopened).Error
+		if err != nil || reopened > 0 {
+			return err
+		}
+
+		if err := snapshot(tx, next); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reopen unlocks a closed period so transactions can be posted on its day
+// again, recording who reopened it and why
+func Reopen(tx *gorm.DB, period *models.Period, reason string, actor string) error {
+	if period.Status == models.PeriodOpen {
+		return ErrAlreadyOpen
+	}
+
+	now := time.Now().UTC()
+	period.Status = models.PeriodOpen
+	period.ReopenedAt = &now
+	period.ReopenedBy = actor
+	period.Reason = reason
+
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "reopened_at", "reopened_by", "reason", "updated_at"}),
+	}).Create(period).Error
+}
+
+// pendingFrom returns the first day the background jobs still have to post
+// on: the first day the interest of an account that is not closed was not
+// accrued for, or the next monthly fee charge. It returns nil when none is
+// pending.
+// Private function, not exposed to the API
+func pendingFrom(db *gorm.DB) (*time.Time, error) {
+	var interest, fees sql.NullTime
+
+	open := db.Model(&models.Account{}).Select("account").Where("status <> ?", models.AccountClosed)
+	err := db.Model(&models.InterestProduct{}).
+		Select("MIN(COALESCE(accrued_through + 1, start_date))").
+		Where("account_id IN (?)", open).
+		Scan(&interest).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Model(&models.AccountFee{}).Select("MIN(next_charge)").Scan(&fees).Error; err != nil {
+		return nil, err
+	}
+
+	switch {
+	case interest.Valid && (!fees.Valid || interest.Time.Before(fees.Time)):
+		return &interest.Time, nil
+	case fees.Valid:
+		return &fees.Time, nil
+	default:
+		return nil, nil
+	}
+}
+
+// snapshot stores the opening and closing balance of every account on day
+// and the totals of the transactions dated on it
+// Private function, not exposed to the API
+func snapshot(tx *gorm.DB, day time.Time) error {
+	var accounts []int
+	var totals []struct {
+		Account      int
+		TotalDebits  money.Amount
+		TotalCredits money.Amount
+		DebitCount   int
+		CreditCount  int
+	}
+
+	if err := tx.Model(&models.Account{}).Order("account").Pluck("account", &accounts).Error; err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	opening, err := openingBalances(tx, day, accounts)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Model(&models.Transaction{}).
+		Select(`account_id AS account,
+			COALESCE(SUM(CASE WHEN amount < 0 THEN -amount ELSE 0 END), 0) AS total_debits,
+			COALESCE(SUM(CASE WHEN amount >= 0 THEN amount ELSE 0 END), 0) AS total_credits,
+			COUNT(CASE WHEN amount < 0 THEN 1 END) AS debit_count,
+			COUNT(CASE WHEN amount >= 0 THEN 1 END) AS credit_count`).
+		Where("date = ?", day).
+		Group("account_id").
+		Scan(&totals).Error
+	if err != nil {
+		return err
+	}
+
+	snapshots := make([]models.BalanceSnapshot, 0, len(accounts))
+	index := make(map[int]int, len(accounts))
+	for _, account := range accounts {
+		index[account] = len(snapshots)
+		snapshots = append(snapshots, models.BalanceSnapshot{
+			Account:        account,
+			Date:           day,
+			OpeningBalance: opening[account],
+			ClosingBalance: opening[account],
+		})
+	}
+
+	for _, total := range totals {
+		i, ok := index[total.Account]
+		if !ok {
+			continue
+		}
+
+		snapshots[i].TotalDebits = total.TotalDebits
+		snapshots[i].TotalCredits = total.TotalCredits
+		snapshots[i].DebitCount = total.DebitCount
+		snapshots[i].CreditCount = total.CreditCount
+		snapshots[i].TransactionCount = total.DebitCount + total.CreditCount
+		snapshots[i].ClosingBalance += total.TotalCredits - total.TotalDebits
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "account_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"opening_balance", "closing_balance", "total_debits", "total_credits", "debit_count", "credit_count", "transaction_count",
+		}),
+	}).CreateInBatches(&snapshots, snapshotBatchSize).Error
+}
+
+// openingBalances returns the balance of the accounts at the start of day,
+// from the snapshots of the previous day when it is closed and from the
+// transactions otherwise
+// Private function, not exposed to the API
+func openingBalances(tx *gorm.DB, day time.Time, accounts []int) (map[int]money.Amount, error) {
+	var periods []models.Period
+	var snapshots []models.BalanceSnapshot
+
+	balances := make(map[int]money.Amount, len(accounts))
+	previous := day.AddDate(0, 0, -1)
+
+	if err := tx.Where("date = ? AND status = ?", previous, models.PeriodClosed).Limit(1).Find(&periods).Error; err != nil {
+		return nil, err
+	}
+	if len(periods) > 0 {
+		if err := tx.Where("date = ?", previous).Find(&snapshots).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[int]bool, len(snapshots))
+	for _, snapshot := range snapshots {
+		balances[snapshot.Account] = snapshot.ClosingBalance
+		seen[snapshot.Account] = true
+	}
+
+	var missing []int
+	for _, account := range accounts {
+		if !seen[account] {
+			missing = append(missing, account)
+		}
+	}
+	if len(missing) == 0 {
+		return balances, nil
+	}
+
+	var sums []struct {
+		Account int
+		Balance money.Amount
+	}
+	err := tx.Model(&models.Transaction{}).
+		Select("account_id AS account, SUM(amount) AS balance").
+		Where("account_id IN ? AND date < ?", missing, day).
+		Group("account_id").
+		Scan(&sums).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sum := range sums {
+		balances[sum.Account] = sum.Balance
+	}
+
+	return balances, nil
+}
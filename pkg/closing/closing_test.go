@@ -0,0 +1,141 @@
+package closing
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestEnsureOpen(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	expectClosedThrough(dbMock, date(2024, 3, 30))
+	expectClosedThrough(dbMock, date(2024, 3, 30))
+	dbMock.ExpectQuery(`SELECT count\(\*\) FROM "periods" WHERE date = (.+) AND status = (.+)`).
+		WithArgs(date(2024, 3, 12), models.PeriodOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	expectClosedThrough(dbMock, date(2024, 3, 30))
+	dbMock.ExpectQuery(`SELECT count\(\*\) FROM "periods" WHERE date = (.+) AND status = (.+)`).
+		WithArgs(date(2024, 3, 13), models.PeriodOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	// Then
+	require.NoError(t, EnsureOpen(gormDB, date(2024, 3, 31)))
+	require.ErrorIs(t, EnsureOpen(gormDB, date(2024, 3, 12)), ErrPeriodClosed)
+	require.NoError(t, EnsureOpen(gormDB, date(2024, 3, 13)))
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestLockedDays(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	expectClosedThrough(dbMock, date(2024, 3, 30))
+	dbMock.ExpectQuery(`SELECT "date" FROM "periods" WHERE date IN (.+) AND status = (.+)`).
+		WithArgs(date(2024, 3, 12), date(2024, 3, 13), models.PeriodOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"date"}).AddRow(date(2024, 3, 13)))
+
+	// When
+	locked, err := LockedDays(gormDB, []time.Time{date(2024, 3, 12), date(2024, 3, 13), date(2024, 3, 31)})
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"2024-03-12": true}, locked)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestFind_BeforeFirstClosedDay(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	expectClosedThrough(dbMock, date(2024, 3, 30))
+	dbMock.ExpectQuery(`SELECT \* FROM "periods" WHERE date = (.+) LIMIT 1`).
+		WithArgs(date(2023, 12, 31)).
+		WillReturnRows(sqlmock.NewRows([]string{"date"}))
+
+	// When
+	period, err := Find(gormDB, date(2023, 12, 31))
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, models.Period{Date: date(2023, 12, 31), Status: models.PeriodClosed}, period)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReopen_AlreadyOpen(t *testing.T) {
+	_, gormDB := setupTestDatabase(t)
+
+	period := models.Period{Date: date(2024, 3, 12), Status: models.PeriodOpen}
+	require.ErrorIs(t, Reopen(gormDB, &period, "late card settlement", "jdoe"), ErrAlreadyOpen)
+}
+
+func TestOpenOn(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	expectClosedThrough(dbMock, date(2024, 3, 30))
+	expectClosedThrough(dbMock, date(2024, 3, 30))
+	dbMock.ExpectQuery(`SELECT "date" FROM "periods" WHERE date >= (.+) AND date <= (.+) AND status = (.+) ORDER BY date LIMIT 1`).
+		WithArgs(date(2024, 3, 12), date(2024, 3, 30), models.PeriodOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"date"}).AddRow(date(2024, 3, 13)))
+	expectClosedThrough(dbMock, date(2024, 3, 30))
+	dbMock.ExpectQuery(`SELECT "date" FROM "periods" WHERE date >= (.+) AND date <= (.+) AND status = (.+) ORDER BY date LIMIT 1`).
+		WithArgs(date(2024, 3, 14), date(2024, 3, 30), models.PeriodOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"date"}))
+
+	// When
+	unlocked, unlockedErr := OpenOn(gormDB, date(2024, 3, 31))
+	reopened, reopenedErr := OpenOn(gormDB, date(2024, 3, 12))
+	next, nextErr := OpenOn(gormDB, date(2024, 3, 14))
+
+	// Then
+	require.NoError(t, unlockedErr)
+	require.NoError(t, reopenedErr)
+	require.NoError(t, nextErr)
+	require.Equal(t, date(2024, 3, 31), unlocked)
+	require.Equal(t, date(2024, 3, 13), reopened)
+	require.Equal(t, date(2024, 3, 31), next)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRun_KeepsPendingDaysOpen(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	dbMock.ExpectQuery(`SELECT MIN\(COALESCE\(accrued_through \+ 1, start_date\)\) FROM "interest_products" WHERE account_id IN \(SELECT "account" FROM "accounts" WHERE status <> (.+)\)`).
+		WithArgs(models.AccountClosed).
+		WillReturnRows(sqlmock.NewRows([]string{"min"}).AddRow(date(2024, 3, 29)))
+	dbMock.ExpectQuery(`SELECT MIN\(next_charge\) FROM "account_fees"`).
+		WillReturnRows(sqlmock.NewRows([]string{"min"}).AddRow(date(2024, 3, 31)))
+	expectClosedThrough(dbMock, date(2024, 3, 28))
+
+	// When
+	closed, err := Run(gormDB, date(2024, 4, 2))
+
+	// Then
+	require.NoError(t, err)
+	require.Zero(t, closed)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// expectClosedThrough expects the lookup of the last closed day
+func expectClosedThrough(dbMock sqlmock.Sqlmock, day time.Time) {
+	dbMock.ExpectQuery(`SELECT MAX\(date\) FROM "periods"`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(day))
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
+
+// date returns the given day at midnight UTC
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,353 @@
+// Package fees charges the fees of the fee schedules attached to accounts,
+// as transactions of their own.
+package fees
+
+import (
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/balances"
+	"github.com/wjoseperez20/zenwallet/pkg/closing"
+	"github.com/wjoseperez20/zenwallet/pkg/ledger"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// MonthlyFeeDescription is the description of the monthly maintenance fees
+	MonthlyFeeDescription = "monthly maintenance fee"
+	// TransactionFeeDescription is the description of the per-transaction fees
+	TransactionFeeDescription = "transaction fee"
+	// PercentageFeeDescription is the description of the percentage fees on debits
+	PercentageFeeDescription = "percentage fee"
+)
+
+// basisPoints is the number of basis points in a whole
+const basisPoints = 10000
+
+var (
+	ErrInvalidFee        = errors.New("fees must be positive")
+	ErrInvalidPercentage = errors.New("percentage_fee_bps must be between 1 and 10000")
+	ErrInvalidThreshold  = errors.New("percentage_threshold must not be negative")
+)
+
+// Validate checks the fees of a fee schedule
+func Validate(schedule models.FeeSchedule) error {
+	for _, fee := range []*money.Amount{schedule.MonthlyFee, schedule.TransactionFee} {
+		if fee != nil && *fee <= 0 {
+			return ErrInvalidFee
+		}
+	}
+
+	if schedule.PercentageFeeBps != nil && (*schedule.PercentageFeeBps <= 0 || *schedule.PercentageFeeBps > basisPoints) {
+		return ErrInvalidPercentage
+	}
+
+	if schedule.PercentageThreshold < 0 {
+		return ErrInvalidThreshold
+	}
+
+	return nil
+}
+
+// TransactionFees returns the fees a movement of amount incurs under the
+// schedule, given the balance of the account once it is posted. Only
+// debits incur fees, and none while the balance reaches the waiver.
+func TransactionFees(schedule models.FeeSchedule, amount money.Amount, balance money.Amount) []models.Transaction {
+	if amount >= 0 || waived(schedule, balance) {
+		return nil
+	}
+	debit := -amount
+
+	var fees []models.Transaction
+	if schedule.TransactionFee != nil {
+		fees = append(fees, fee(-*schedule.TransactionFee, TransactionFeeDescription))
+	}
+
+	// The fee is rounded to the cent half away from zero, as every amount
+	if schedule.PercentageFeeBps != nil && debit > schedule.PercentageThreshold {
+		amount := money.FromCents(debit.Cents() * *schedule.PercentageFeeBps).Div(basisPoints)
+		if amount > 0 {
+			fees = append(fees, fee(-amount, PercentageFeeDescription))
+		}
+	}
+
+	return fees
+}
+
+// Charge posts the fees the given transaction incurs under the fee schedule
+// of its account, linked to it, and returns them. The transaction must be
+// posted already, in the same database transaction.
+func Charge(tx *gorm.DB, transaction *models.Transaction) ([]models.Transaction, error) {
+	schedule, err := scheduleOf(tx, transaction.Account)
+	if err != nil || schedule == nil {
+		return nil, err
+	}
+
+	balance, err := ledger.Balance(tx, transaction.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	charged := TransactionFees(*schedule, transaction.Amount, balance)
+	for i := range charged {
+		charged[i].Account = transaction.Account
+		charged[i].Date = transaction.Date
+		charged[i].Currency = transaction.Currency
+		charged[i].FeeOf = &transaction.ID
+
+		if err := ledger.PostFee(tx, &charged[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return charged, nil
+}
+
+// ChargeBatch charges the fees of transactions posted together by
+// ledger.PostBatch, as Charge does for each of them in order: each fee is
+// computed on the balance left by the transactions and fees before it. The
+// schedules are loaded once and the fees posted at once, after the batch,
+// so they do not count against the limits of the batch. It returns the
+// fees posted.
+func ChargeBatch(tx *gorm.DB, posted []*models.Transaction) ([]models.Transaction, error) {
+	var accounts []int
+	seen := make(map[int]bool)
+	for _, transaction := range posted {
+		if !seen[transaction.Account] {
+			seen[transaction.Account] = true
+			accounts = append(accounts, transaction.Account)
+		}
+	}
+	if len(accounts) == 0 {
+		return nil, nil
+	}
+
+	schedules, err := schedulesOf(tx, accounts)
+	if err != nil || len(schedules) == 0 {
+		return nil, err
+	}
+
+	// The balances are read once the batch is posted, the walk starts
+	// from the balance before it
+	balances := make(map[int]money.Amount)
+	for account := range schedules {
+		balance, err := ledger.Balance(tx, account)
+		if err != nil {
+			return nil, err
+		}
+		balances[account] = balance
+	}
+	for _, transaction := range posted {
+		if _, ok := schedules[transaction.Account]; ok {
+			balances[transaction.Account] -= transaction.Amount
+		}
+	}
+
+	var charged []models.Transaction
+	for _, transaction := range posted {
+		schedule, ok := schedules[transaction.Account]
+		if !ok {
+			continue
+		}
+
+		balances[transaction.Account] += transaction.Amount
+		for _, charge := range TransactionFees(schedule, transaction.Amount, balances[transaction.Account]) {
+			charge.Account = transaction.Account
+			charge.Date = transaction.Date
+			charge.Currency = transaction.Currency
+			charge.FeeOf = &transaction.ID
+
+			balances[transaction.Account] += charge.Amount
+			charged = append(charged, charge)
+		}
+	}
+
+	fees := make([]*models.Transaction, len(charged))
+	for i := range charged {
+		fees[i] = &charged[i]
+	}
+	if err := ledger.PostFees(tx, fees); err != nil {
+		return nil, err
+	}
+
+	return charged, nil
+}
+
+// NextMonthEnd returns the last day of the month following the given day
+func NextMonthEnd(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month()+2, 0, 0, 0, 0, 0, day.Location())
+}
+
+// MonthEnd returns the last day of the month of the given day
+func MonthEnd(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location())
+}
+
+// ChargeMonthly charges the monthly fee of every month that ended before
+// today and was not charged yet, on its last day, and returns how many fees
+// were posted. The fee is waived when the balance at the end of the month
+// reaches the waiver, and only active accounts are charged. Each account is
+// charged in its own database transaction, an account that fails is
+// retried on the next call.
+func ChargeMonthly(db *gorm.DB, today time.Time) (int, error) {
+	var accounts []int
+
+	err := db.Model(&models.AccountFee{}).
+		Where("next_charge < ?", today).
+		Order("account_id").
+		Pluck("account_id", &accounts).Error
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for _, account := range accounts {
+		var count int
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			count, err = chargeAccount(tx, account, today)
+			return err
+		})
+		if err != nil {
+			log.Printf("Monthly fee of account %d: %v", account, err)
+			continue
+		}
+
+		posted += count
+	}
+
+	return posted, nil
+}
+
+// chargeAccount charges the months of the account that ended before today
+// Private function, not exposed to the API
+func chargeAccount(tx *gorm.DB, account int, today time.Time) (int, error) {
+	var assignments []models.AccountFee
+	var schedule models.FeeSchedule
+	var accounts []models.Account
+
+	// An assignment locked by another process is left alone
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("account_id = ? AND next_charge < ?", account, today).
+		Limit(1).
+		Find(&assignments).Error
+	if err != nil || len(assignments) == 0 {
+		return 0, err
+	}
+	assignment := &assignments[0]
+
+	if err := tx.Where("id = ?", assignment.FeeScheduleID).First(&schedule).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Where("account = ?", account).Limit(1).Find(&accounts).Error; err != nil {
+		return 0, err
+	}
+	active := len(accounts) > 0 && accounts[0].Status == models.AccountActive
+
+	posted := 0
+	for ; assignment.NextCharge.Before(today); assignment.NextCharge = NextMonthEnd(assignment.NextCharge) {
+		if schedule.MonthlyFee == nil || !active {
+			continue
+		}
+
+		// An amended transaction counts on its current date
+		balance, err := balances.AsOf(tx, account, assignment.NextCharge)
+		if err != nil {
+			return 0, err
+		}
+		if waived(schedule, balance) {
+			continue
+		}
+
+		// A month end closed since is charged on the first open day
+		date, err := closing.OpenOn(tx, assignment.NextCharge)
+		if err != nil {
+			return 0, err
+		}
+
+		charge := fee(-*schedule.MonthlyFee, MonthlyFeeDescription)
+		charge.Account = account
+		charge.Date = date
+		if err := ledger.PostFee(tx, &charge); err != nil {
+			return 0, err
+		}
+		posted++
+	}
+
+	return posted, tx.Model(assignment).Update("next_charge", assignment.NextCharge).Error
+}
+
+// scheduleOf returns the fee schedule attached to the account, or nil
+// Private function, not exposed to the API
+func scheduleOf(tx *gorm.DB, account int) (*models.FeeSchedule, error) {
+	var assignments []models.AccountFee
+	var schedule models.FeeSchedule
+
+	if err := tx.Where("account_id = ?", account).Limit(1).Find(&assignments).Error; err != nil || len(assignments) == 0 {
+		return nil, err
+	}
+
+	if err := tx.Where("id = ?", assignments[0].FeeScheduleID).First(&schedule).Error; err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// schedulesOf returns the fee schedules attached to the accounts, keyed
+// by account, the accounts without one are missing
+// Private function, not exposed to the API
+func schedulesOf(tx *gorm.DB, accounts []int) (map[int]models.FeeSchedule, error) {
+	var assignments []models.AccountFee
+	var schedules []models.FeeSchedule
+	var ids []int
+
+	if err := tx.Where("account_id IN ?", accounts).Find(&assignments).Error; err != nil || len(assignments) == 0 {
+		return nil, err
+	}
+	for _, assignment := range assignments {
+		ids = append(ids, assignment.FeeScheduleID)
+	}
+
+	if err := tx.Where("id IN ?", ids).Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[int]models.FeeSchedule)
+	for _, schedule := range schedules {
+		byID[schedule.ID] = schedule
+	}
+
+	attached := make(map[int]models.FeeSchedule)
+	for _, assignment := range assignments {
+		if schedule, ok := byID[assignment.FeeScheduleID]; ok {
+			attached[assignment.Account] = schedule
+		}
+	}
+
+	return attached, nil
+}
+
+// waived reports whether the balance exempts the account from the fees
+// Private function, not exposed to the API
+func waived(schedule models.FeeSchedule, balance money.Amount) bool {
+	return schedule.WaiverBalance != nil && balance >= *schedule.WaiverBalance
+}
+
+// fee builds a fee transaction of the given amount
+// Private function, not exposed to the API
+func fee(amount money.Amount, description string) models.Transaction {
+	category := models.FeeCategory
+
+	return models.Transaction{
+		Amount:      amount,
+		Currency:    models.DefaultCurrency,
+		Description: description,
+		Category:    &category,
+		Fee:         true,
+	}
+}
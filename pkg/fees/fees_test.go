@@ -0,0 +1,116 @@
+package fees
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	zero := money.Zero
+	percentage := int64(15000)
+
+	require.NoError(t, Validate(models.FeeSchedule{Name: "basic"}))
+	require.ErrorIs(t, Validate(models.FeeSchedule{MonthlyFee: &zero}), ErrInvalidFee)
+	require.ErrorIs(t, Validate(models.FeeSchedule{PercentageFeeBps: &percentage}), ErrInvalidPercentage)
+	require.ErrorIs(t, Validate(models.FeeSchedule{PercentageThreshold: money.MustParse("-1")}), ErrInvalidThreshold)
+}
+
+func TestTransactionFees(t *testing.T) {
+	// Given
+	transactionFee := money.MustParse("0.50")
+	percentage := int64(150)
+	schedule := models.FeeSchedule{TransactionFee: &transactionFee, PercentageFeeBps: &percentage, PercentageThreshold: money.MustParse("100.00")}
+
+	// When
+	small := TransactionFees(schedule, money.MustParse("-20.00"), money.MustParse("80.00"))
+	large := TransactionFees(schedule, money.MustParse("-200.33"), money.MustParse("80.00"))
+	credit := TransactionFees(schedule, money.MustParse("200.00"), money.MustParse("80.00"))
+
+	// Then
+	require.Len(t, small, 1)
+	require.Equal(t, money.MustParse("-0.50"), small[0].Amount)
+	require.Equal(t, TransactionFeeDescription, small[0].Description)
+	require.Equal(t, models.FeeCategory, *small[0].Category)
+	require.True(t, small[0].Fee)
+
+	require.Len(t, large, 2)
+	require.Equal(t, money.MustParse("-3.00"), large[1].Amount)
+	require.Equal(t, PercentageFeeDescription, large[1].Description)
+
+	require.Empty(t, credit)
+}
+
+func TestTransactionFees_RoundsHalfAwayFromZero(t *testing.T) {
+	// Given
+	percentage := int64(50)
+	schedule := models.FeeSchedule{PercentageFeeBps: &percentage}
+
+	for debit, expected := range map[string]string{
+		"-1.00":  "0.01", // 0.005 rounds up
+		"-0.99":  "0.00", // 0.00495 rounds down
+		"-3.00":  "0.02", // 0.015 rounds up
+		"-2.98":  "0.01", // 0.0149 rounds down
+		"-10.10": "0.05", // 0.0505 rounds down
+		"-10.90": "0.05", // 0.0545 rounds down
+		"-11.00": "0.06", // 0.055 rounds up
+	} {
+		// When
+		fees := TransactionFees(schedule, money.MustParse(debit), money.Zero)
+
+		// Then
+		if expected == "0.00" {
+			require.Empty(t, fees, debit)
+			continue
+		}
+		require.Len(t, fees, 1, debit)
+		require.Equal(t, -money.MustParse(expected), fees[0].Amount, debit)
+	}
+}
+
+func TestTransactionFees_Waived(t *testing.T) {
+	// Given
+	transactionFee := money.MustParse("0.50")
+	waiver := money.MustParse("1000.00")
+	schedule := models.FeeSchedule{TransactionFee: &transactionFee, WaiverBalance: &waiver}
+
+	// Then
+	require.Empty(t, TransactionFees(schedule, money.MustParse("-20.00"), money.MustParse("1000.00")))
+	require.Len(t, TransactionFees(schedule, money.MustParse("-20.00"), money.MustParse("999.99")), 1)
+}
+
+func TestMonthEnds(t *testing.T) {
+	require.Equal(t, date(2024, 2, 29), MonthEnd(date(2024, 2, 3)))
+	require.Equal(t, date(2024, 2, 29), NextMonthEnd(date(2024, 1, 31)))
+	require.Equal(t, date(2025, 1, 31), NextMonthEnd(date(2024, 12, 31)))
+}
+
+func TestCharge_NoSchedule(t *testing.T) {
+	// Given
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	require.NoError(t, err)
+
+	dbMock.ExpectQuery(`SELECT \* FROM "account_fees" WHERE account_id = (.+) LIMIT 1`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}))
+
+	// When
+	charged, err := Charge(gormDB, &models.Transaction{ID: 7, Account: 10001, Amount: money.MustParse("-20.00")})
+
+	// Then
+	require.NoError(t, err)
+	require.Empty(t, charged)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// date returns the given day at midnight UTC
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
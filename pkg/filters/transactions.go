@@ -0,0 +1,226 @@
+// Package filters parses the query filters of the list endpoints and
+// applies them to the database queries.
+package filters
+
+import (
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"github.com/wjoseperez20/zenwallet/pkg/pagination"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	SignDebit  = "debit"
+	SignCredit = "credit"
+)
+
+// sortOrders maps the accepted sort values to their order, the ID breaks
+// the ties so the order is stable across pages
+var sortOrders = map[string]pagination.Order{
+	"id":      {Key: "id"},
+	"-id":     {Key: "id", Desc: true},
+	"date":    {Column: "date", Key: "id"},
+	"-date":   {Column: "date", Key: "id", Desc: true},
+	"amount":  {Column: "amount", Key: "id"},
+	"-amount": {Column: "amount", Key: "id", Desc: true},
+}
+
+// DefaultSort is the order of the transactions when none is requested
+const DefaultSort = "id"
+
+var (
+	ErrInvalidAccount   = errors.New("Invalid account format")
+	ErrInvalidFrom      = errors.New("Invalid from format")
+	ErrInvalidTo        = errors.New("Invalid to format")
+	ErrInvalidMinAmount = errors.New("Invalid min_amount format")
+	ErrInvalidMaxAmount = errors.New("Invalid max_amount format")
+	ErrInvalidSign      = errors.New("sign must be debit or credit")
+	ErrInvalidSort      = errors.New("sort must be one of id, date, amount, optionally prefixed with -")
+	ErrInvalidDateRange = errors.New("from must not be after to")
+	ErrInvalidAmounts   = errors.New("min_amount must not be greater than max_amount")
+)
+
+// Transactions filters a list of transactions. Empty fields do not filter.
+type Transactions struct {
+	Account   *int
+	From      *time.Time
+	To        *time.Time
+	MinAmount *money.Amount
+	MaxAmount *money.Amount
+	Sign      string
+	Category  string
+	Text      string
+	Sort      string
+}
+
+// ParseTransactions reads and validates the filters of the query string:
+// account, from and to as YYYY-MM-DD, min_amount and max_amount, sign,
+// category, q for a text in the description, and sort
+func ParseTransactions(query url.Values) (Transactions, error) {
+	filter := Transactions{
+		Sign:     query.Get("sign"),
+		Category: strings.ToLower(strings.TrimSpace(query.Get("category"))),
+		Text:     strings.TrimSpace(query.Get("q")),
+		Sort:     query.Get("sort"),
+	}
+
+	if value := query.Get("account"); value != "" {
+		account, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, ErrInvalidAccount
+		}
+		filter.Account = &account
+	}
+
+	if value := query.Get("from"); value != "" {
+		from, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return filter, ErrInvalidFrom
+		}
+		filter.From = &from
+	}
+	if value := query.Get("to"); value != "" {
+		to, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return filter, ErrInvalidTo
+		}
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return filter, ErrInvalidDateRange
+	}
+
+	if value := query.Get("min_amount"); value != "" {
+		amount, err := money.Parse(value)
+		if err != nil {
+			return filter, ErrInvalidMinAmount
+		}
+		filter.MinAmount = &amount
+	}
+	if value := query.Get("max_amount"); value != "" {
+		amount, err := money.Parse(value)
+		if err != nil {
+			return filter, ErrInvalidMaxAmount
+		}
+		filter.MaxAmount = &amount
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return filter, ErrInvalidAmounts
+	}
+
+	if filter.Sign != "" && filter.Sign != SignDebit && filter.Sign != SignCredit {
+		return filter, ErrInvalidSign
+	}
+
+	if filter.Sort == "" {
+		filter.Sort = DefaultSort
+	}
+	if _, ok := sortOrders[filter.Sort]; !ok {
+		return filter, ErrInvalidSort
+	}
+
+	return filter, nil
+}
+
+// Apply adds the filters to a query on the transactions
+func (f Transactions) Apply(query *gorm.DB) *gorm.DB {
+	if f.Account != nil {
+		query = query.Where("account_id = ?", *f.Account)
+	}
+	if f.From != nil {
+		query = query.Where("date >= ?", *f.From)
+	}
+	if f.To != nil {
+		query = query.Where("date <= ?", *f.To)
+	}
+	if f.MinAmount != nil {
+		query = query.Where("amount >= ?", *f.MinAmount)
+	}
+	if f.MaxAmount != nil {
+		query = query.Where("amount <= ?", *f.MaxAmount)
+	}
+
+	switch f.Sign {
+	case SignDebit:
+		query = query.Where("amount < 0")
+	case SignCredit:
+		query = query.Where("amount >= 0")
+	}
+
+	// A split transaction matches the categories of its splits instead of its own
+	if f.Category != "" {
+		query = query.Where(`(category = ? AND NOT EXISTS (SELECT 1 FROM transaction_splits WHERE transaction_splits.transaction_id = transactions.id))
+			OR EXISTS (SELECT 1 FROM transaction_splits WHERE transaction_splits.transaction_id = transactions.id AND transaction_splits.category = ?)`,
+			f.Category, f.Category)
+	}
+	if f.Text != "" {
+		query = query.Where("description ILIKE ?", "%"+escapeLike(f.Text)+"%")
+	}
+
+	return query
+}
+
+// Order returns the sort order of the transactions
+func (f Transactions) Order() pagination.Order {
+	return sortOrders[f.Sort]
+}
+
+// Cursor returns the position of a transaction in the sort order
+func (f Transactions) Cursor(transaction models.Transaction) pagination.Cursor {
+	cursor := pagination.Cursor{ID: transaction.ID}
+
+	switch sortOrders[f.Sort].Column {
+	case "date":
+		cursor.Value = transaction.Date.Format("2006-01-02")
+	case "amount":
+		cursor.Value = transaction.Amount.String()
+	}
+
+	return cursor
+}
+
+// Key returns the filters in a canonical form, to tell cached lists apart
+func (f Transactions) Key() string {
+	values := url.Values{}
+
+	if f.Account != nil {
+		values.Set("account", strconv.Itoa(*f.Account))
+	}
+	if f.From != nil {
+		values.Set("from", f.From.Format("2006-01-02"))
+	}
+	if f.To != nil {
+		values.Set("to", f.To.Format("2006-01-02"))
+	}
+	if f.MinAmount != nil {
+		values.Set("min_amount", f.MinAmount.String())
+	}
+	if f.MaxAmount != nil {
+		values.Set("max_amount", f.MaxAmount.String())
+	}
+	if f.Sign != "" {
+		values.Set("sign", f.Sign)
+	}
+	if f.Category != "" {
+		values.Set("category", f.Category)
+	}
+	if f.Text != "" {
+		values.Set("q", f.Text)
+	}
+	values.Set("sort", f.Sort)
+
+	// Encode sorts the keys
+	return values.Encode()
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+// Private function, not exposed to the API
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+}
@@ -0,0 +1,86 @@
+package filters
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseTransactions(t *testing.T) {
+	// Given
+	query, _ := url.ParseQuery("account=10001&from=2024-03-01&to=2024-03-31&min_amount=-50&sign=debit&q=coffee&sort=-date")
+
+	// When
+	filter, err := ParseTransactions(query)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, 10001, *filter.Account)
+	require.Equal(t, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), *filter.To)
+	require.Equal(t, money.MustParse("-50.00"), *filter.MinAmount)
+	require.Nil(t, filter.MaxAmount)
+	require.Equal(t, "account=10001&from=2024-03-01&min_amount=-50.00&q=coffee&sign=debit&sort=-date&to=2024-03-31", filter.Key())
+}
+
+func TestParseTransactions_Category(t *testing.T) {
+	// Given
+	query, _ := url.ParseQuery("category=%20Groceries%20")
+
+	// When
+	filter, err := ParseTransactions(query)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, "groceries", filter.Category)
+	require.Equal(t, "category=groceries&sort=id", filter.Key())
+}
+
+func TestParseTransactions_Invalid(t *testing.T) {
+	tests := map[string]error{
+		"account=abc":                   ErrInvalidAccount,
+		"from=01/03/2024":               ErrInvalidFrom,
+		"to=2024-3-31":                  ErrInvalidTo,
+		"min_amount=ten":                ErrInvalidMinAmount,
+		"max_amount=1e3":                ErrInvalidMaxAmount,
+		"sign=positive":                 ErrInvalidSign,
+		"sort=description":              ErrInvalidSort,
+		"from=2024-03-31&to=2024-03-01": ErrInvalidDateRange,
+		"min_amount=10&max_amount=-10":  ErrInvalidAmounts,
+	}
+
+	for raw, expected := range tests {
+		query, _ := url.ParseQuery(raw)
+		_, err := ParseTransactions(query)
+		require.ErrorIs(t, err, expected, raw)
+	}
+}
+
+func TestApply(t *testing.T) {
+	// Given
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	require.NoError(t, err)
+
+	query := url.Values{"category": {"food"}, "sign": {"credit"}, "q": {"50%_off"}, "sort": {"-amount"}}
+	filter, err := ParseTransactions(query)
+	require.NoError(t, err)
+
+	dbMock.ExpectQuery(`SELECT \* FROM "transactions" WHERE amount >= 0 AND \(\(category = (.+) AND NOT EXISTS (.+)\) OR EXISTS (.+) transaction_splits.category = (.+)\) AND description ILIKE (.+)`).
+		WithArgs("food", "food", `%50\%\_off%`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	// When
+	var transactions []models.Transaction
+	err = filter.Apply(gormDB).Find(&transactions).Error
+
+	// Then
+	require.NoError(t, err)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,277 @@
+// Package interest accrues interest on the end-of-day balances of the
+// accounts with an interest product and posts it as transactions.
+package interest
+
+import (
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/balances"
+	"github.com/wjoseperez20/zenwallet/pkg/closing"
+	"github.com/wjoseperez20/zenwallet/pkg/ledger"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"log"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Description is the description of the interest transactions
+const Description = "interest"
+
+// rateScale is the scale of the annual rates, stored with four decimals
+const rateScale = 10000
+
+var (
+	ErrInvalidRate        = errors.New("annual_rate must be between 0 and 100")
+	ErrInvalidDayCount    = errors.New("day_count must be ACT/365, ACT/360 or 30/360")
+	ErrInvalidCompounding = errors.New("compounding must be none, daily or monthly")
+	ErrInvalidPosting     = errors.New("posting must be monthly, quarterly or annually")
+)
+
+// Validate checks the terms of an interest product
+func Validate(product models.InterestProduct) error {
+	if product.AnnualRate < 0 || product.AnnualRate > 100 {
+		return ErrInvalidRate
+	}
+
+	switch product.DayCount {
+	case models.DayCountActual365, models.DayCountActual360, models.DayCount30360:
+	default:
+		return ErrInvalidDayCount
+	}
+
+	switch product.Compounding {
+	case models.CompoundingNone, models.CompoundingDaily, models.CompoundingMonthly:
+	default:
+		return ErrInvalidCompounding
+	}
+
+	switch product.Posting {
+	case models.PostingMonthly, models.PostingQuarterly, models.PostingAnnually:
+	default:
+		return ErrInvalidPosting
+	}
+
+	return nil
+}
+
+// DayFraction returns the part of a year the given day accounts for under
+// the day count convention. Under 30/360 every month counts 30 days: the
+// 30th of a 31-day month counts nothing and the last day of February makes
+// up the month.
+func DayFraction(dayCount string, day time.Time) float64 {
+	days, year := dayCountOf(dayCount, day)
+
+	return float64(days) / float64(year)
+}
+
+// IsPostingDate reports whether the accrued interest is posted at the end of day
+func IsPostingDate(posting string, day time.Time) bool {
+	if !isMonthEnd(day) {
+		return false
+	}
+
+	switch posting {
+	case models.PostingQuarterly:
+		return day.Month()%3 == 0
+	case models.PostingAnnually:
+		return day.Month() == time.December
+	default:
+		return true
+	}
+}
+
+// Accrue adds the interest of day on the given end-of-day balance to the
+// product and returns the record of the calculation. Interest is earned on
+// the balance plus the compounded interest, and only when it is positive.
+// It is kept in micro-cents and only rounded to the cent when posted.
+func Accrue(product *models.InterestProduct, day time.Time, balance money.Amount) models.InterestAccrual {
+	base := balance.Micros() + product.Compounded
+	days, year := dayCountOf(product.DayCount, day)
+
+	// The rate has four decimals, as stored, so the interest is computed on integers
+	var interest money.Micros
+	if base > 0 {
+		rate := int64(math.Round(product.AnnualRate * rateScale))
+		interest = base.MulDiv(rate*days, 100*rateScale*year)
+	}
+
+	product.Accrued += interest
+	switch {
+	case product.Compounding == models.CompoundingDaily:
+		product.Compounded = product.Accrued
+	case product.Compounding == models.CompoundingMonthly && isMonthEnd(day):
+		product.Compounded = product.Accrued
+	}
+	accruedThrough := day
+	product.AccruedThrough = &accruedThrough
+
+	return models.InterestAccrual{
+		Account:    product.Account,
+		Date:       day,
+		Balance:    balance,
+		Base:       base,
+		AnnualRate: product.AnnualRate,
+		DayCount:   product.DayCount,
+		Fraction:   DayFraction(product.DayCount, day),
+		Interest:   interest,
+		Accrued:    product.Accrued,
+	}
+}
+
+// Due returns the accrued interest of the product rounded to the cent
+func Due(product models.InterestProduct) money.Amount {
+	return product.Accrued.Round()
+}
+
+// Run accrues the interest of every day that ended before today and was
+// not accrued yet, and posts it on the posting dates. It returns how many
+// interest transactions were posted. Each account is accrued in its own
+// database transaction, an account that fails is retried on the next run.
+func Run(db *gorm.DB, today time.Time) (int, error) {
+	var accounts []int
+
+	yesterday := today.AddDate(0, 0, -1)
+	err := db.Model(&models.InterestProduct{}).
+		Where("start_date <= ? AND (accrued_through IS NULL OR accrued_through < ?)", yesterday, yesterday).
+		Order("account_id").
+		Pluck("account_id", &accounts).Error
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for _, account := range accounts {
+		var count int
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			count, err = accrueAccount(tx, account, today)
+			return err
+		})
+		if err != nil {
+			log.Printf("Interest of account %d: %v", account, err)
+			continue
+		}
+
+		posted += count
+	}
+
+	return posted, nil
+}
+
+// accrueAccount accrues the days of the account up to today, posting the
+// interest due on the posting dates. Closed accounts no longer accrue.
+// Private function, not exposed to the API
+func accrueAccount(tx *gorm.DB, account int, today time.Time) (int, error) {
+	var products []models.InterestProduct
+	var accounts []models.Account
+
+	// A product locked by another process is left alone
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("account_id = ?", account).
+		Limit(1).
+		Find(&products).Error
+	if err != nil || len(products) == 0 {
+		return 0, err
+	}
+	product := &products[0]
+
+	if err := tx.Where("account = ?", account).Limit(1).Find(&accounts).Error; err != nil {
+		return 0, err
+	}
+	if len(accounts) == 0 || accounts[0].Status == models.AccountClosed {
+		return 0, nil
+	}
+
+	day := product.StartDate
+	if product.AccruedThrough != nil {
+		day = product.AccruedThrough.AddDate(0, 0, 1)
+	}
+
+	posted := 0
+	for ; day.Before(today); day = day.AddDate(0, 0, 1) {
+		// Summed from the transactions, so a transaction counts on its
+		// current date even when amended after being posted
+		balance, err := balances.AsOf(tx, account, day)
+		if err != nil {
+			return 0, err
+		}
+
+		accrual := Accrue(product, day, balance)
+
+		if amount := Due(*product); IsPostingDate(product.Posting, day) && amount > 0 {
+			// Interest due on a day closed since is posted on the first open day
+			date, err := closing.OpenOn(tx, day)
+			if err != nil {
+				return 0, err
+			}
+
+			transaction := models.Transaction{
+				Account:     account,
+				Date:        date,
+				Amount:      amount,
+				Currency:    models.DefaultCurrency,
+				Description: Description,
+			}
+			if err := ledger.Post(tx, &transaction); err != nil {
+				return 0, err
+			}
+
+			// The fraction of a cent left is carried to the next period
+			product.Accrued -= amount.Micros()
+			product.Compounded = 0
+			accrual.Posted = &amount
+			accrual.TransactionID = &transaction.ID
+			posted++
+		}
+
+		if err := tx.Create(&accrual).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Model(product).Updates(map[string]interface{}{
+		"accrued_through": product.AccruedThrough,
+		"accrued":         product.Accrued,
+		"compounded":      product.Compounded,
+	}).Error
+
+	return posted, err
+}
+
+// dayCountOf returns the days the given day counts for and the days of the
+// year under the day count convention
+// Private function, not exposed to the API
+func dayCountOf(dayCount string, day time.Time) (int64, int64) {
+	switch dayCount {
+	case models.DayCountActual360:
+		return 1, 360
+	case models.DayCount30360:
+		return int64(days360(day, day.AddDate(0, 0, 1))), 360
+	default:
+		return 1, 365
+	}
+}
+
+// days360 counts the days between two dates as if every month had 30 days
+// Private function, not exposed to the API
+func days360(from time.Time, to time.Time) int {
+	d1, d2 := from.Day(), to.Day()
+	if d1 > 30 {
+		d1 = 30
+	}
+	if d2 > 30 {
+		d2 = 30
+	}
+
+	return (to.Year()-from.Year())*360 + (int(to.Month())-int(from.Month()))*30 + d2 - d1
+}
+
+// isMonthEnd reports whether day is the last day of its month
+// Private function, not exposed to the API
+func isMonthEnd(day time.Time) bool {
+	return day.AddDate(0, 0, 1).Day() == 1
+}
@@ -0,0 +1,251 @@
+package interest
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	product := models.InterestProduct{AnnualRate: 3.5, DayCount: models.DayCountActual365, Compounding: models.CompoundingDaily, Posting: models.PostingMonthly}
+	require.NoError(t, Validate(product))
+
+	invalid := product
+	invalid.AnnualRate = -1
+	require.ErrorIs(t, Validate(invalid), ErrInvalidRate)
+
+	invalid = product
+	invalid.DayCount = "ACT/ACT"
+	require.ErrorIs(t, Validate(invalid), ErrInvalidDayCount)
+
+	invalid = product
+	invalid.Posting = "weekly"
+	require.ErrorIs(t, Validate(invalid), ErrInvalidPosting)
+}
+
+func TestDayFraction_30360(t *testing.T) {
+	// Given
+	months := []time.Time{date(2023, 1, 1), date(2023, 2, 1), date(2024, 2, 1), date(2023, 12, 1)}
+
+	for _, month := range months {
+		// When
+		total := 0.0
+		for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+			total += DayFraction(models.DayCount30360, day)
+		}
+
+		// Then
+		require.InDelta(t, 30.0/360, total, 1e-12, month.Format("2006-01"))
+	}
+	require.Zero(t, DayFraction(models.DayCount30360, date(2023, 1, 30)))
+	require.InDelta(t, 3.0/360, DayFraction(models.DayCount30360, date(2023, 2, 28)), 1e-12)
+}
+
+func TestIsPostingDate(t *testing.T) {
+	require.True(t, IsPostingDate(models.PostingMonthly, date(2024, 2, 29)))
+	require.False(t, IsPostingDate(models.PostingMonthly, date(2024, 2, 28)))
+	require.True(t, IsPostingDate(models.PostingQuarterly, date(2023, 9, 30)))
+	require.False(t, IsPostingDate(models.PostingQuarterly, date(2023, 10, 31)))
+	require.True(t, IsPostingDate(models.PostingAnnually, date(2023, 12, 31)))
+	require.False(t, IsPostingDate(models.PostingAnnually, date(2023, 11, 30)))
+}
+
+func TestAccrue_SimpleInterest(t *testing.T) {
+	// Given
+	product := models.InterestProduct{Account: 10001, AnnualRate: 3.65, DayCount: models.DayCountActual365, Compounding: models.CompoundingNone}
+
+	// When
+	Accrue(&product, date(2023, 11, 1), money.MustParse("1000.00"))
+	accrual := Accrue(&product, date(2023, 11, 2), money.MustParse("1000.00"))
+
+	// Then
+	require.Equal(t, money.MustParseMicros("1000"), accrual.Base)
+	require.Equal(t, money.MustParseMicros("0.1"), accrual.Interest)
+	require.Equal(t, money.MustParseMicros("0.2"), accrual.Accrued)
+	require.Zero(t, product.Compounded)
+	require.Equal(t, date(2023, 11, 2), *product.AccruedThrough)
+}
+
+func TestAccrue_DailyCompounding(t *testing.T) {
+	// Given
+	product := models.InterestProduct{Account: 10001, AnnualRate: 36.5, DayCount: models.DayCountActual365, Compounding: models.CompoundingDaily}
+
+	// When
+	Accrue(&product, date(2023, 11, 1), money.MustParse("1000.00"))
+	accrual := Accrue(&product, date(2023, 11, 2), money.MustParse("1000.00"))
+
+	// Then
+	require.Equal(t, money.MustParseMicros("1001"), accrual.Base)
+	require.Equal(t, money.MustParseMicros("1.001"), accrual.Interest)
+	require.Equal(t, money.MustParseMicros("2.001"), product.Compounded)
+	require.Equal(t, money.MustParse("2.00"), Due(product))
+}
+
+func TestAccrue_MonthlyCompounding(t *testing.T) {
+	// Given
+	product := models.InterestProduct{Account: 10001, AnnualRate: 36.5, DayCount: models.DayCountActual365, Compounding: models.CompoundingMonthly}
+
+	// When
+	Accrue(&product, date(2023, 11, 29), money.MustParse("1000.00"))
+	require.Zero(t, product.Compounded)
+	Accrue(&product, date(2023, 11, 30), money.MustParse("1000.00"))
+	accrual := Accrue(&product, date(2023, 12, 1), money.MustParse("1000.00"))
+
+	// Then
+	require.Equal(t, money.MustParseMicros("1002"), accrual.Base)
+	require.Equal(t, money.MustParseMicros("1.002"), accrual.Interest)
+}
+
+func TestAccrue_NegativeBalance(t *testing.T) {
+	// Given
+	product := models.InterestProduct{Account: 10001, AnnualRate: 5, DayCount: models.DayCountActual360, Compounding: models.CompoundingDaily}
+
+	// When
+	accrual := Accrue(&product, date(2023, 11, 1), money.MustParse("-50.00"))
+
+	// Then
+	require.Zero(t, accrual.Interest)
+	require.Equal(t, money.Zero, Due(product))
+}
+
+func TestAccrue_KeepsSubCentAccruals(t *testing.T) {
+	// Given
+	product := models.InterestProduct{Account: 10001, AnnualRate: 1, DayCount: models.DayCountActual365, Compounding: models.CompoundingNone}
+
+	// When
+	for day := date(2023, 11, 1); day.Month() == time.November; day = day.AddDate(0, 0, 1) {
+		Accrue(&product, day, money.MustParse("100.00"))
+	}
+
+	// Then
+	require.Equal(t, money.MustParseMicros("0.0821919"), product.Accrued)
+	require.Equal(t, money.MustParse("0.08"), Due(product))
+}
+
+func TestAccrueAccount_BalanceFromTransactionDates(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	day := date(2023, 11, 1)
+
+	dbMock.ExpectQuery(`SELECT \* FROM "interest_products" WHERE account_id = (.+) LIMIT 1 FOR UPDATE SKIP LOCKED`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "annual_rate", "day_count", "compounding", "posting", "start_date", "accrued", "compounded"}).
+			AddRow(10001, 3.65, models.DayCountActual365, models.CompoundingNone, models.PostingMonthly, day, "0", "0"))
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) LIMIT 1`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account", "status"}).AddRow(10001, models.AccountActive))
+	dbMock.ExpectQuery(`SELECT \* FROM "balance_snapshots"`).
+		WithArgs(10001, day, models.PeriodOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "date", "closing_balance"}))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "transactions" WHERE account_id = (.+) AND date <= (.+)`).
+		WithArgs(10001, day).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("1000.00"))
+	dbMock.ExpectQuery(`INSERT INTO "interest_accruals"`).
+		WithArgs(10001, day, "1000.00", "1000.00000000", 3.65, models.DayCountActual365, sqlmock.AnyArg(),
+			"0.10000000", "0.10000000", nil, nil, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	dbMock.ExpectExec(`UPDATE "interest_products" SET "accrued"=(.+),"accrued_through"=(.+),"compounded"=(.+)`).
+		WithArgs("0.10000000", day, "0.00000000", sqlmock.AnyArg(), 10001).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// When
+	posted, err := accrueAccount(gormDB, 10001, date(2023, 11, 2))
+
+	// Then
+	require.NoError(t, err)
+	require.Zero(t, posted)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestAccrueAccount_DueOnClosedDay(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	day := date(2023, 11, 30)
+	open := date(2023, 12, 6)
+
+	dbMock.ExpectQuery(`SELECT \* FROM "interest_products" WHERE account_id = (.+) LIMIT 1 FOR UPDATE SKIP LOCKED`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "annual_rate", "day_count", "compounding", "posting", "start_date", "accrued", "compounded"}).
+			AddRow(10001, 3.65, models.DayCountActual365, models.CompoundingNone, models.PostingMonthly, day, "0", "0"))
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) LIMIT 1`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account", "status"}).AddRow(10001, models.AccountActive))
+	dbMock.ExpectQuery(`SELECT \* FROM "balance_snapshots"`).
+		WithArgs(10001, day, models.PeriodOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "date", "closing_balance"}))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "transactions" WHERE account_id = (.+) AND date <= (.+)`).
+		WithArgs(10001, day).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("1000.00"))
+	expectClosedThrough(dbMock, date(2023, 12, 5))
+	dbMock.ExpectQuery(`SELECT "date" FROM "periods" WHERE date >= (.+) AND date <= (.+) AND status = (.+) ORDER BY date LIMIT 1`).
+		WithArgs(day, date(2023, 12, 5), models.PeriodOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"date"}))
+	expectClosedThrough(dbMock, date(2023, 12, 5))
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1 FOR UPDATE`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account", "balance"}).AddRow(1, 10001, "1000.00"))
+	dbMock.ExpectQuery(`SELECT \* FROM "account_policies" WHERE account_id = (.+) LIMIT 1`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id"}))
+	dbMock.ExpectQuery(`INSERT INTO "transactions"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	dbMock.ExpectQuery(`INSERT INTO "journal_entries"`).
+		WithArgs(7, "transaction posted", open, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	dbMock.ExpectQuery(`INSERT INTO "postings"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	dbMock.ExpectQuery(`SELECT "account","version" FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account", "version"}).AddRow(10001, 1))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "postings" WHERE account_id = (.+)`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("1000.10"))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "holds" WHERE account_id = (.+) AND status = (.+)`).
+		WithArgs(10001, models.HoldPending).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("0.00"))
+	dbMock.ExpectExec(`UPDATE "accounts" SET "available_balance"=(.+),"balance"=(.+),"version"=version \+ 1,"updated_at"=(.+) WHERE account = (.+) AND version = (.+)`).
+		WithArgs("1000.10", "1000.10", sqlmock.AnyArg(), 10001, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectQuery(`INSERT INTO "interest_accruals"`).
+		WithArgs(10001, day, "1000.00", "1000.00000000", 3.65, models.DayCountActual365, sqlmock.AnyArg(),
+			"0.10000000", sqlmock.AnyArg(), "0.10", 7, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	dbMock.ExpectExec(`UPDATE "interest_products" SET "accrued"=(.+),"accrued_through"=(.+),"compounded"=(.+)`).
+		WithArgs("0.00000000", day, "0.00000000", sqlmock.AnyArg(), 10001).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// When
+	posted, err := accrueAccount(gormDB, 10001, date(2023, 12, 1))
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, 1, posted)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
+
+// expectClosedThrough expects the lookup of the last closed day
+func expectClosedThrough(dbMock sqlmock.Sqlmock, day time.Time) {
+	dbMock.ExpectQuery(`SELECT MAX\(date\) FROM "periods"`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(day))
+}
+
+// date returns the given day at midnight UTC
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,38 @@
+package jobs
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/budgeting"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"log"
+	"time"
+)
+
+// budgetCheckInterval is how often the budgets are checked against their thresholds
+const budgetCheckInterval = 5 * time.Minute
+
+// StartBudgetMonitor sends the alerts of the budgets whose spending of the
+// current month reached a threshold in the background, for as long as the
+// process runs.
+func StartBudgetMonitor() {
+	go func() {
+		ticker := time.NewTicker(budgetCheckInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			checkBudgets()
+		}
+	}()
+}
+
+// checkBudgets runs a single alerting pass
+// Private function, not exposed to the API
+func checkBudgets() {
+	sent, err := budgeting.CheckAlerts(database.DB, budgeting.Period(time.Now().UTC()))
+	if err != nil {
+		log.Default().Println(err)
+	}
+
+	if sent > 0 {
+		log.Printf("Sent %d budget alerts", sent)
+	}
+}
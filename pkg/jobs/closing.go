@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/closing"
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"log"
+	"time"
+)
+
+// closingInterval is how often the days not closed yet are looked for
+const closingInterval = time.Hour
+
+// StartDailyClose closes the days that ended once at startup and then in
+// the background, for as long as the process runs.
+func StartDailyClose() {
+	go func() {
+		closeDays()
+
+		ticker := time.NewTicker(closingInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			closeDays()
+		}
+	}()
+}
+
+// closeDays runs a single closing pass
+// Private function, not exposed to the API
+func closeDays() {
+	closed, err := closing.Run(database.DB, time.Now().UTC().Truncate(24*time.Hour))
+	if err != nil {
+		log.Default().Println(err)
+		return
+	}
+
+	if closed > 0 {
+		log.Printf("Closed %d days", closed)
+	}
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/fees"
+	"log"
+	"time"
+)
+
+// feeInterval is how often the monthly fees due are looked for, accounts
+// that failed are retried on the next pass
+const feeInterval = time.Hour
+
+// StartFeeCharger charges the monthly fees of the months that ended once
+// at startup and then in the background, for as long as the process runs.
+func StartFeeCharger() {
+	go func() {
+		chargeFees()
+
+		ticker := time.NewTicker(feeInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			chargeFees()
+		}
+	}()
+}
+
+// chargeFees runs a single charging pass
+// Private function, not exposed to the API
+func chargeFees() {
+	posted, err := fees.ChargeMonthly(database.DB, time.Now().UTC().Truncate(24*time.Hour))
+	if err != nil {
+		log.Default().Println(err)
+		return
+	}
+
+	if posted > 0 {
+		log.Printf("Charged %d monthly fees", posted)
+	}
+}
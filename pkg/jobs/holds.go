@@ -0,0 +1,47 @@
+// Package jobs runs the background work of the server process.
+package jobs
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/ledger"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// holdSweepInterval is how often expired holds are looked for
+const holdSweepInterval = time.Minute
+
+// StartHoldSweeper expires the pending holds past their expiration in the
+// background, for as long as the process runs.
+func StartHoldSweeper() {
+	go func() {
+		ticker := time.NewTicker(holdSweepInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			sweepHolds()
+		}
+	}()
+}
+
+// sweepHolds runs a single expiration pass
+// Private function, not exposed to the API
+func sweepHolds() {
+	var expired int
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		expired, err = ledger.ExpireHolds(tx, time.Now().UTC())
+		return err
+	})
+	if err != nil {
+		log.Default().Println(err)
+		return
+	}
+
+	if expired > 0 {
+		log.Printf("Expired %d holds", expired)
+	}
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/interest"
+	"log"
+	"time"
+)
+
+// interestInterval is how often the days not accrued yet are looked for,
+// accounts that failed are retried on the next pass
+const interestInterval = time.Hour
+
+// StartInterestAccrual accrues the interest of the days that ended once at
+// startup and then in the background, for as long as the process runs.
+func StartInterestAccrual() {
+	go func() {
+		accrueInterest()
+
+		ticker := time.NewTicker(interestInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			accrueInterest()
+		}
+	}()
+}
+
+// accrueInterest runs a single accrual pass
+// Private function, not exposed to the API
+func accrueInterest() {
+	posted, err := interest.Run(database.DB, time.Now().UTC().Truncate(24*time.Hour))
+	if err != nil {
+		log.Default().Println(err)
+		return
+	}
+
+	if posted > 0 {
+		log.Printf("Posted %d interest transactions", posted)
+	}
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/database"
+	"github.com/wjoseperez20/zenwallet/pkg/scheduler"
+	"log"
+	"time"
+)
+
+// scheduleInterval is how often due occurrences are looked for, failed
+// occurrences are retried on the next pass
+const scheduleInterval = time.Hour
+
+// StartScheduler posts the due occurrences of the schedules once at
+// startup and then in the background, for as long as the process runs.
+func StartScheduler() {
+	go func() {
+		runSchedules()
+
+		ticker := time.NewTicker(scheduleInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			runSchedules()
+		}
+	}()
+}
+
+// runSchedules runs a single scheduling pass
+// Private function, not exposed to the API
+func runSchedules() {
+	posted, err := scheduler.RunDue(database.DB, time.Now().UTC().Truncate(24*time.Hour))
+	if err != nil {
+		log.Default().Println(err)
+		return
+	}
+
+	if posted > 0 {
+		log.Printf("Posted %d scheduled transactions", posted)
+	}
+}
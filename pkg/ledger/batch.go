@@ -0,0 +1,306 @@
+package ledger
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/closing"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// batchSize bounds the rows inserted per statement by a batch
+const batchSize = 500
+
+// PostBatch posts many transactions at once, as Post does for each of them
+// in order: every transaction sees the balances and debit limits left by
+// the previous ones. The accounts, categories and policies are loaded once,
+// the rows are inserted in batches and each balance is refreshed once. A
+// transaction dated in a closed period fails with closing.ErrPeriodClosed.
+//
+// It returns the error of every transaction that cannot be posted, nil for
+// the others. When atomic is set and any transaction fails, none is posted;
+// otherwise the failing ones are skipped. The second error is a database
+// error, in which case the database transaction must be rolled back. It
+// must be called inside a database transaction.
+func PostBatch(tx *gorm.DB, transactions []*models.Transaction, atomic bool) ([]error, error) {
+	return postBatch(tx, transactions, atomic, true)
+}
+
+// PostFees posts many fees at once, as PostFee does for each of them. Fees
+// are not subject to the policy of the account, so only an unknown or
+// closed account makes the batch fail. It must be called inside a
+// database transaction.
+func PostFees(tx *gorm.DB, fees []*models.Transaction) error {
+	for _, fee := range fees {
+		fee.Fee = true
+	}
+
+	failed, err := postBatch(tx, fees, true, false)
+	if err != nil {
+		return err
+	}
+
+	for _, err := range failed {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// postBatch posts the transactions, checking the policies of the accounts
+// when enforce is set
+// Private function, not exposed to the API
+func postBatch(tx *gorm.DB, transactions []*models.Transaction, atomic bool, enforce bool) ([]error, error) {
+	failed := make([]error, len(transactions))
+	if len(transactions) == 0 {
+		return failed, nil
+	}
+
+	days := make([]time.Time, 0, len(transactions))
+	for _, transaction := range transactions {
+		days = append(days, transaction.Date)
+	}
+	locked, err := closing.LockedDays(tx, days)
+	if err != nil {
+		return nil, err
+	}
+
+	var open []*models.Transaction
+	for i, transaction := range transactions {
+		if locked[transaction.Date.Format("2006-01-02")] {
+			failed[i] = closing.ErrPeriodClosed
+			continue
+		}
+		open = append(open, transaction)
+	}
+	if len(open) == 0 || (atomic && len(open) < len(transactions)) {
+		return failed, nil
+	}
+
+	state, err := loadBatchState(tx, open, enforce)
+	if err != nil {
+		return nil, err
+	}
+
+	var accepted []*models.Transaction
+	for i, transaction := range transactions {
+		if failed[i] != nil {
+			continue
+		}
+		if transaction.Currency == "" {
+			transaction.Currency = models.DefaultCurrency
+		}
+
+		if err := state.check(transaction, enforce); err != nil {
+			failed[i] = err
+			continue
+		}
+
+		state.apply(transaction)
+		accepted = append(accepted, transaction)
+	}
+
+	if len(accepted) == 0 || (atomic && len(accepted) < len(transactions)) {
+		return failed, nil
+	}
+
+	if err := tx.CreateInBatches(accepted, batchSize).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.JournalEntry, 0, len(accepted))
+	for _, transaction := range accepted {
+		entries = append(entries, models.JournalEntry{
+			TransactionID: transaction.ID,
+			Description:   "transaction posted",
+			Date:          transaction.Date,
+			Postings:      postingsFor(transaction.Account, transaction.Amount),
+		})
+	}
+	if err := tx.CreateInBatches(&entries, batchSize).Error; err != nil {
+		return nil, err
+	}
+
+	refreshed := make(map[int]bool)
+	for _, transaction := range accepted {
+		if refreshed[transaction.Account] {
+			continue
+		}
+		if err := refreshBalance(tx, transaction.Account); err != nil {
+			return nil, err
+		}
+		refreshed[transaction.Account] = true
+	}
+
+	return failed, nil
+}
+
+// batchState holds what the checks of a batch need, updated as the
+// transactions of the batch are accepted
+type batchState struct {
+	tx         *gorm.DB
+	accounts   map[int]models.Account
+	categories map[string]bool
+	policies   map[int]models.AccountPolicy
+
+	// debited holds the debits of an account in a period, from the database
+	// and from the accepted transactions
+	debited map[debitPeriod]money.Amount
+}
+
+// debitPeriod identifies the debits of an account dated in [from, to)
+type debitPeriod struct {
+	account int
+	from    string
+	to      string
+}
+
+// loadBatchState locks the accounts of the transactions, in order so
+// concurrent batches cannot deadlock, and loads their categories and, when
+// enforce is set, their policies
+// Private function, not exposed to the API
+func loadBatchState(tx *gorm.DB, transactions []*models.Transaction, enforce bool) (*batchState, error) {
+	var accounts []models.Account
+	var numbers []int
+	var codes []string
+
+	state := &batchState{
+		tx:         tx,
+		accounts:   make(map[int]models.Account),
+		categories: make(map[string]bool),
+		policies:   make(map[int]models.AccountPolicy),
+		debited:    make(map[debitPeriod]money.Amount),
+	}
+
+	seen := make(map[int]bool)
+	for _, transaction := range transactions {
+		if !seen[transaction.Account] {
+			seen[transaction.Account] = true
+			numbers = append(numbers, transaction.Account)
+		}
+		if transaction.Category != nil && !state.categories[*transaction.Category] {
+			state.categories[*transaction.Category] = false
+			codes = append(codes, *transaction.Category)
+		}
+	}
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("account IN ?", numbers).
+		Order("account").
+		Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		state.accounts[account.Account] = account
+	}
+
+	if len(codes) > 0 {
+		var existing []string
+		if err := tx.Model(&models.Category{}).Where("code IN ?", codes).Pluck("code", &existing).Error; err != nil {
+			return nil, err
+		}
+		for _, code := range existing {
+			state.categories[code] = true
+		}
+	}
+
+	if enforce {
+		var policies []models.AccountPolicy
+		if err := tx.Where("account_id IN ?", numbers).Find(&policies).Error; err != nil {
+			return nil, err
+		}
+		for _, policy := range policies {
+			state.policies[policy.Account] = policy
+		}
+	}
+
+	return state, nil
+}
+
+// check returns why the transaction cannot be posted, if it cannot
+// Private function, not exposed to the API
+func (s *batchState) check(transaction *models.Transaction, enforce bool) error {
+	account, ok := s.accounts[transaction.Account]
+	if !ok {
+		return ErrAccountNotFound
+	}
+
+	if err := ensurePostable(account, transaction.Amount); err != nil {
+		return err
+	}
+
+	if transaction.Category != nil && !s.categories[*transaction.Category] {
+		return ErrCategoryNotFound
+	}
+	if err := ensureUnreserved(transaction.Fee, transaction.Category); err != nil {
+		return err
+	}
+
+	if !enforce {
+		return nil
+	}
+
+	policy, ok := s.policies[account.Account]
+	if !ok {
+		policy = models.AccountPolicy{Account: account.Account}
+	}
+
+	return checkPolicy(policy, account.AvailableBalance, transaction.Amount, transaction.Currency, transaction.Date,
+		func(from time.Time, to time.Time) (money.Amount, error) {
+			return s.debitedBetween(account.Account, from, to)
+		})
+}
+
+// apply updates the state with an accepted transaction
+// Private function, not exposed to the API
+func (s *batchState) apply(transaction *models.Transaction) {
+	account := s.accounts[transaction.Account]
+	account.Balance += transaction.Amount
+	account.AvailableBalance += transaction.Amount
+	s.accounts[transaction.Account] = account
+
+	if transaction.Amount >= 0 {
+		return
+	}
+
+	// Only the periods loaded by the checks need to be kept up to date,
+	// the others are loaded from the database with the batch rows missing
+	// and are not used: they are loaded before the first debit they cover
+	for period := range s.debited {
+		if period.account == transaction.Account && covers(period, transaction.Date) {
+			s.debited[period] -= transaction.Amount
+		}
+	}
+}
+
+// debitedBetween returns the debits of the account dated in [from, to),
+// including the accepted transactions of the batch
+// Private function, not exposed to the API
+func (s *batchState) debitedBetween(account int, from time.Time, to time.Time) (money.Amount, error) {
+	period := debitPeriod{account: account, from: from.Format("2006-01-02"), to: to.Format("2006-01-02")}
+
+	if debited, ok := s.debited[period]; ok {
+		return debited, nil
+	}
+
+	debited, err := debitedBetween(s.tx, account, from, to)
+	if err != nil {
+		return money.Zero, err
+	}
+	s.debited[period] = debited
+
+	return debited, nil
+}
+
+// covers reports whether the day is in the period
+// Private function, not exposed to the API
+func covers(period debitPeriod, day time.Time) bool {
+	date := day.Format("2006-01-02")
+
+	return date >= period.from && date < period.to
+}
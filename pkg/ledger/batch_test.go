@@ -0,0 +1,94 @@
+package ledger
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"testing"
+	"time"
+)
+
+func TestPostBatch_AtomicRejectsWholeBatch(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	date := time.Date(2023, 11, 25, 0, 0, 0, 0, time.UTC)
+	expectClosedThrough(dbMock, nil)
+	expectBatchAccounts(dbMock, 10001, "30.00")
+	dbMock.ExpectQuery(`SELECT \* FROM "account_policies" WHERE account_id IN (.+)`).
+		WithArgs(10001, 999).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "overdraft_limit"}))
+
+	// When
+	transactions := []*models.Transaction{
+		{Account: 10001, Amount: money.MustParse("-20"), Date: date},
+		{Account: 10001, Amount: money.MustParse("-20"), Date: date},
+		{Account: 999, Amount: money.MustParse("5"), Date: date},
+	}
+	failed, err := PostBatch(gormDB, transactions, true)
+
+	// Then
+	require.NoError(t, err)
+	require.NoError(t, failed[0])
+	var violation *PolicyViolation
+	require.ErrorAs(t, failed[1], &violation)
+	require.Equal(t, RuleOverdraftLimit, violation.Rule)
+	require.Equal(t, money.MustParse("10"), *violation.Attempted)
+	require.ErrorIs(t, failed[2], ErrAccountNotFound)
+	require.Zero(t, transactions[0].ID)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestPostBatch_BestEffortSkipsFailed(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	date := time.Date(2023, 11, 25, 0, 0, 0, 0, time.UTC)
+	expectClosedThrough(dbMock, nil)
+	expectBatchAccounts(dbMock, 10001, "30.00")
+	dbMock.ExpectQuery(`SELECT \* FROM "account_policies" WHERE account_id IN (.+)`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "overdraft_limit"}))
+	dbMock.ExpectQuery(`INSERT INTO "transactions"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7).AddRow(8))
+	dbMock.ExpectQuery(`INSERT INTO "journal_entries"`).
+		WithArgs(7, "transaction posted", date, sqlmock.AnyArg(), 8, "transaction posted", date, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(4))
+	dbMock.ExpectQuery(`INSERT INTO "postings"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3).AddRow(4))
+	dbMock.ExpectQuery(`SELECT "account","version" FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account", "version"}).AddRow(10001, 1))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "postings" WHERE account_id = (.+)`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("15.00"))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "holds" WHERE account_id = (.+) AND status = (.+)`).
+		WithArgs(10001, models.HoldPending).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("0.00"))
+	dbMock.ExpectExec(`UPDATE "accounts" SET "available_balance"=(.+),"balance"=(.+),"version"=version \+ 1,"updated_at"=(.+) WHERE account = (.+) AND version = (.+)`).
+		WithArgs("15.00", "15.00", sqlmock.AnyArg(), 10001, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// When
+	transactions := []*models.Transaction{
+		{Account: 10001, Amount: money.MustParse("-20"), Date: date},
+		{Account: 10001, Amount: money.MustParse("-20"), Date: date},
+		{Account: 10001, Amount: money.MustParse("5"), Date: date},
+	}
+	failed, err := PostBatch(gormDB, transactions, false)
+
+	// Then
+	require.NoError(t, err)
+	require.NoError(t, failed[0])
+	require.Error(t, failed[1])
+	require.NoError(t, failed[2])
+	require.Equal(t, 7, transactions[0].ID)
+	require.Equal(t, 8, transactions[2].ID)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// expectBatchAccounts expects the accounts of a batch to be locked at once
+func expectBatchAccounts(dbMock sqlmock.Sqlmock, account int, balance string) {
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account IN (.+) ORDER BY account FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"account", "balance", "available_balance", "status"}).
+			AddRow(account, balance, balance, models.AccountActive))
+}
@@ -0,0 +1,137 @@
+package ledger
+
+import (
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/closing"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrHoldNotPending     = errors.New("hold is no longer pending")
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the held amount")
+)
+
+// Authorize places the given hold on its account. The hold reduces the
+// available balance of the account but does not record any journal entry,
+// it must comply with the policy of the account as a debit would. It must
+// be called inside a database transaction.
+func Authorize(tx *gorm.DB, hold *models.Hold) error {
+	accounts, err := lockAccounts(tx, hold.Account)
+	if err != nil {
+		return err
+	}
+
+	if err := ensurePostable(accounts[hold.Account], -hold.Amount); err != nil {
+		return err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if err := enforcePolicy(tx, accounts[hold.Account], -hold.Amount, models.DefaultCurrency, today); err != nil {
+		return err
+	}
+
+	hold.Status = models.HoldPending
+	if err := tx.Create(hold).Error; err != nil {
+		return err
+	}
+
+	return refreshBalance(tx, hold.Account)
+}
+
+// Capture turns a pending hold into a debit transaction of amount, or of
+// the whole held amount when amount is zero. Capturing less than the held
+// amount releases the remainder. The policy of the account was enforced
+// when the hold was authorized and is not checked again. The date must not
+// be in a closed period.
+func Capture(tx *gorm.DB, hold *models.Hold, amount money.Amount, date time.Time) (*models.Transaction, error) {
+	if hold.Status != models.HoldPending {
+		return nil, ErrHoldNotPending
+	}
+
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if amount < 0 || amount > hold.Amount {
+		return nil, ErrCaptureExceedsHold
+	}
+
+	if err := closing.EnsureOpen(tx, date); err != nil {
+		return nil, err
+	}
+
+	// The hold stops counting against the available balance before the debit is posted
+	if err := tx.Model(hold).Updates(map[string]interface{}{"status": models.HoldCaptured, "captured": amount}).Error; err != nil {
+		return nil, err
+	}
+
+	transaction := models.Transaction{Account: hold.Account, Date: date, Amount: -amount, Description: hold.Description}
+	if err := postOpen(tx, &transaction, false); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(hold).Update("transaction_id", transaction.ID).Error; err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// Release cancels a pending hold and gives its amount back to the
+// available balance of the account.
+func Release(tx *gorm.DB, hold *models.Hold) error {
+	return closeHold(tx, hold, models.HoldReleased)
+}
+
+// ExpireHolds expires every pending hold whose expiration is before now
+// and returns how many were expired. Holds locked by a concurrent capture
+// or release are skipped, the next run picks them up if still pending.
+func ExpireHolds(tx *gorm.DB, now time.Time) (int, error) {
+	var holds []models.Hold
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND expires_at <= ?", models.HoldPending, now).
+		Order("id").
+		Find(&holds).Error
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range holds {
+		if err := closeHold(tx, &holds[i], models.HoldExpired); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(holds), nil
+}
+
+// Held returns the amount reserved on the account by its pending holds
+func Held(tx *gorm.DB, account int) (money.Amount, error) {
+	var held money.Amount
+
+	err := tx.Model(&models.Hold{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("account_id = ? AND status = ?", account, models.HoldPending).
+		Scan(&held).Error
+
+	return held, err
+}
+
+// closeHold moves a pending hold to a final status without capturing it
+// Private function, not exposed to the API
+func closeHold(tx *gorm.DB, hold *models.Hold, status string) error {
+	if hold.Status != models.HoldPending {
+		return ErrHoldNotPending
+	}
+
+	if err := tx.Model(hold).Update("status", status).Error; err != nil {
+		return err
+	}
+
+	return refreshBalance(tx, hold.Account)
+}
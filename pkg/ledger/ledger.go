@@ -0,0 +1,471 @@
+// Package ledger implements the double-entry book keeping behind every
+// transaction. Client account balances are never modified directly: each
+// write produces a balanced journal entry and the balance is recomputed
+// from the postings of the account.
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"github.com/wjoseperez20/zenwallet/pkg/closing"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// CashInAccount is the system account that funds credits to client accounts
+	CashInAccount = 1
+	// CashOutAccount is the system account that receives debits from client accounts
+	CashOutAccount = 2
+	// TransitAccount is the system account that holds transfers between their two legs
+	TransitAccount = 3
+	// AdjustmentAccount is the system account that balances reconciliation adjustments
+	AdjustmentAccount = 4
+
+	// maxBalanceRetries bounds the attempts to write a balance under contention
+	maxBalanceRetries = 5
+)
+
+var (
+	ErrAccountNotFound  = errors.New("account not found")
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrReservedCategory = errors.New("category fees is reserved for the fees")
+	ErrUnbalancedEntry  = errors.New("journal entry is not balanced")
+	ErrEmptyEntry       = errors.New("journal entry has no postings")
+	ErrConcurrentUpdate = errors.New("account was updated concurrently, retry the operation")
+
+	ErrAlreadyReversed     = errors.New("transaction already reversed")
+	ErrReversedTransaction = errors.New("transaction is part of a reversal")
+	ErrTransferTransaction = errors.New("transaction belongs to a transfer")
+)
+
+// Post persists the given transaction and records the journal entry
+// that moves its amount between the client account and the system
+// cash accounts. The transaction must comply with the policy of the
+// account. It must be called inside a database transaction.
+func Post(tx *gorm.DB, transaction *models.Transaction) error {
+	return post(tx, transaction, true)
+}
+
+// PostFee persists a fee charged to an account, marked as such, and records
+// its journal entry. Fees are not subject to the policy of the account and
+// may take it over its overdraft limit. It must be called inside a database
+// transaction.
+func PostFee(tx *gorm.DB, fee *models.Transaction) error {
+	fee.Fee = true
+
+	return post(tx, fee, false)
+}
+
+// Amend applies the changes of updated to transaction. The journal entry
+// only holds the delta between the previous and the new state: a change of
+// amount moves the difference, a change of account moves the whole effect
+// from the old account to the new one, and a change of date alone does not
+// move any money. The description, counterparty, category, external
+// reference and metadata are replaced, empty ones clear them. Neither the
+// previous nor the new date may be in a closed period. The transaction
+// should be locked by the caller.
+func Amend(tx *gorm.DB, transaction *models.Transaction, updated models.Transaction) error {
+	if err := ensureMutable(transaction); err != nil {
+		return err
+	}
+
+	if err := closing.EnsureOpen(tx, transaction.Date); err != nil {
+		return err
+	}
+	if err := closing.EnsureOpen(tx, updated.Date); err != nil {
+		return err
+	}
+
+	if err := ensureAccount(tx, updated.Account); err != nil {
+		return err
+	}
+
+	if err := ensureCategory(tx, updated.Category); err != nil {
+		return err
+	}
+	if err := ensureUnreserved(transaction.Fee, updated.Category); err != nil {
+		return err
+	}
+
+	postings := reversingPostings(transaction.Account, transaction.Amount)
+	postings = append(postings, postingsFor(updated.Account, updated.Amount)...)
+	postings = netPostings(postings)
+
+	// The accounts are locked at once, in order, so concurrent amendments cannot deadlock
+	var numbers []int
+	for _, posting := range postings {
+		if !IsSystemAccount(posting.Account) {
+			numbers = append(numbers, posting.Account)
+		}
+	}
+	accounts, err := lockAccounts(tx, numbers...)
+	if err != nil {
+		return err
+	}
+
+	// Only the delta is checked, the debits already include the previous amount
+	for _, posting := range postings {
+		if IsSystemAccount(posting.Account) {
+			continue
+		}
+
+		if err := ensurePostable(accounts[posting.Account], posting.Amount); err != nil {
+			return err
+		}
+		if posting.Amount >= 0 {
+			continue
+		}
+		if err := enforcePolicy(tx, accounts[posting.Account], posting.Amount, transaction.Currency, updated.Date); err != nil {
+			return err
+		}
+	}
+
+	// Every amendable column is written, so a field can be cleared
+	err = tx.Model(transaction).
+		Select("account_id", "date", "amount", "description", "counterparty", "category", "external_reference", "metadata").
+		Updates(updated).Error
+	if err != nil {
+		return err
+	}
+
+	if len(postings) == 0 {
+		return nil
+	}
+
+	return record(tx, transaction.ID, updated.Date, "transaction amended", postings)
+}
+
+// Reverse posts a compensating transaction that cancels the effect of the
+// given one. Both transactions reference each other, the original one is
+// never modified otherwise. The status of the account must accept the
+// reversal. It must be called inside a database transaction.
+func Reverse(tx *gorm.DB, transaction *models.Transaction, date time.Time) (*models.Transaction, error) {
+	if transaction.TransferID != nil {
+		return nil, ErrTransferTransaction
+	}
+	if transaction.ReversedBy != nil {
+		return nil, ErrAlreadyReversed
+	}
+
+	if err := closing.EnsureOpen(tx, date); err != nil {
+		return nil, err
+	}
+
+	accounts, err := lockAccounts(tx, transaction.Account)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensurePostable(accounts[transaction.Account], -transaction.Amount); err != nil {
+		return nil, err
+	}
+
+	reversal := models.Transaction{
+		Account:    transaction.Account,
+		Date:       date,
+		Amount:     -transaction.Amount,
+		Currency:   transaction.Currency,
+		ReversalOf: &transaction.ID,
+	}
+	if err := tx.Create(&reversal).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(transaction).Update("reversed_by_id", reversal.ID).Error; err != nil {
+		return nil, err
+	}
+	transaction.ReversedBy = &reversal.ID
+
+	postings := reversingPostings(transaction.Account, transaction.Amount)
+	if err := record(tx, reversal.ID, date, fmt.Sprintf("reversal of transaction %d", transaction.ID), postings); err != nil {
+		return nil, err
+	}
+
+	return &reversal, nil
+}
+
+// Adjust brings the ledger balance of an account to the given target by
+// posting the difference against the adjustment account, then refreshes the
+// stored balance. The journal entry is not linked to any transaction.
+func Adjust(tx *gorm.DB, account int, target money.Amount, description string) error {
+	if err := ensureAccount(tx, account); err != nil {
+		return err
+	}
+
+	balance, err := Balance(tx, account)
+	if err != nil {
+		return err
+	}
+
+	if difference := target - balance; difference != 0 {
+		return record(tx, 0, time.Now().UTC(), description, []models.Posting{
+			{Account: account, Amount: difference},
+			{Account: AdjustmentAccount, Amount: -difference},
+		})
+	}
+
+	return refreshBalance(tx, account)
+}
+
+// Balance returns the sum of the postings of the given account
+func Balance(tx *gorm.DB, account int) (money.Amount, error) {
+	var balance money.Amount
+
+	err := tx.Model(&models.Posting{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("account_id = ?", account).
+		Scan(&balance).Error
+
+	return balance, err
+}
+
+// IsSystemAccount reports whether the account number belongs to the ledger
+func IsSystemAccount(account int) bool {
+	switch account {
+	case CashInAccount, CashOutAccount, TransitAccount, AdjustmentAccount:
+		return true
+	default:
+		return false
+	}
+}
+
+// post persists and records a transaction, checking the policy of the
+// account when enforce is set. The date must not be in a closed period.
+// Private function, not exposed to the API
+func post(tx *gorm.DB, transaction *models.Transaction, enforce bool) error {
+	if err := closing.EnsureOpen(tx, transaction.Date); err != nil {
+		return err
+	}
+
+	return postOpen(tx, transaction, enforce)
+}
+
+// postOpen posts a transaction whose date is known to be open
+// Private function, not exposed to the API
+func postOpen(tx *gorm.DB, transaction *models.Transaction, enforce bool) error {
+	if transaction.Currency == "" {
+		transaction.Currency = models.DefaultCurrency
+	}
+
+	accounts, err := lockAccounts(tx, transaction.Account)
+	if err != nil {
+		return err
+	}
+
+	if err := ensurePostable(accounts[transaction.Account], transaction.Amount); err != nil {
+		return err
+	}
+
+	if err := ensureCategory(tx, transaction.Category); err != nil {
+		return err
+	}
+	if err := ensureUnreserved(transaction.Fee, transaction.Category); err != nil {
+		return err
+	}
+
+	if enforce {
+		err := enforcePolicy(tx, accounts[transaction.Account], transaction.Amount, transaction.Currency, transaction.Date)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Create(transaction).Error; err != nil {
+		return err
+	}
+
+	return record(tx, transaction.ID, transaction.Date, "transaction posted",
+		postingsFor(transaction.Account, transaction.Amount))
+}
+
+// record validates and stores a journal entry, then refreshes the
+// balance of every client account it touches.
+// Private function, not exposed to the API
+func record(tx *gorm.DB, transactionID int, date time.Time, description string, postings []models.Posting) error {
+	if len(postings) == 0 {
+		return ErrEmptyEntry
+	}
+
+	var total money.Amount
+	for _, posting := range postings {
+		total += posting.Amount
+	}
+	if total != 0 {
+		return ErrUnbalancedEntry
+	}
+
+	entry := models.JournalEntry{
+		TransactionID: transactionID,
+		Description:   description,
+		Date:          date,
+		Postings:      postings,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return err
+	}
+
+	refreshed := make(map[int]bool)
+	for _, posting := range postings {
+		if IsSystemAccount(posting.Account) || refreshed[posting.Account] {
+			continue
+		}
+		if err := refreshBalance(tx, posting.Account); err != nil {
+			return err
+		}
+		refreshed[posting.Account] = true
+	}
+
+	return nil
+}
+
+// postingsFor builds the two sides of a movement of amount on a client
+// account, using the cash-in account for credits and the cash-out
+// account for debits.
+// Private function, not exposed to the API
+func postingsFor(account int, amount money.Amount) []models.Posting {
+	counterpart := CashInAccount
+	if amount < 0 {
+		counterpart = CashOutAccount
+	}
+
+	return []models.Posting{
+		{Account: account, Amount: amount},
+		{Account: counterpart, Amount: -amount},
+	}
+}
+
+// reversingPostings undoes the postings of a movement of amount on a client
+// account, including the system account side it was originally posted to
+// Private function, not exposed to the API
+func reversingPostings(account int, amount money.Amount) []models.Posting {
+	postings := postingsFor(account, amount)
+	for i := range postings {
+		postings[i].Amount = -postings[i].Amount
+	}
+
+	return postings
+}
+
+// netPostings merges the postings of the same account and drops the ones
+// that cancel out, keeping the order in which accounts first appear
+// Private function, not exposed to the API
+func netPostings(postings []models.Posting) []models.Posting {
+	var accounts []int
+	totals := make(map[int]money.Amount)
+	for _, posting := range postings {
+		if _, ok := totals[posting.Account]; !ok {
+			accounts = append(accounts, posting.Account)
+		}
+		totals[posting.Account] += posting.Amount
+	}
+
+	var netted []models.Posting
+	for _, account := range accounts {
+		if totals[account] != 0 {
+			netted = append(netted, models.Posting{Account: account, Amount: totals[account]})
+		}
+	}
+
+	return netted
+}
+
+// refreshBalance derives the stored balance of an account from its postings
+// and its available balance from the balance and the pending holds.
+// The write is conditioned on the version of the account, so when another
+// posting updates the account concurrently the balance is recomputed and
+// written again instead of overwriting the other one.
+// Private function, not exposed to the API
+func refreshBalance(tx *gorm.DB, account int) error {
+	for attempt := 0; attempt < maxBalanceRetries; attempt++ {
+		var current models.Account
+		if err := tx.Select("account", "version").Where("account = ?", account).First(&current).Error; err != nil {
+			return err
+		}
+
+		balance, err := Balance(tx, account)
+		if err != nil {
+			return err
+		}
+
+		held, err := Held(tx, account)
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.Account{}).
+			Where("account = ? AND version = ?", account, current.Version).
+			Updates(map[string]interface{}{
+				"balance":           balance,
+				"available_balance": balance - held,
+				"version":           gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 1 {
+			return nil
+		}
+	}
+
+	return ErrConcurrentUpdate
+}
+
+// ensureMutable checks that the transaction can still be amended. Transfer
+// legs and transactions involved in a reversal are final.
+// Private function, not exposed to the API
+func ensureMutable(transaction *models.Transaction) error {
+	if transaction.TransferID != nil {
+		return ErrTransferTransaction
+	}
+	if transaction.ReversedBy != nil || transaction.ReversalOf != nil {
+		return ErrReversedTransaction
+	}
+
+	return nil
+}
+
+// ensureAccount checks that the given client account exists
+// Private function, not exposed to the API
+func ensureAccount(tx *gorm.DB, account int) error {
+	var existing models.Account
+
+	if err := tx.Where("account = ?", account).First(&existing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAccountNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ensureUnreserved checks that only a fee is in the fees category
+// Private function, not exposed to the API
+func ensureUnreserved(fee bool, category *string) error {
+	if !fee && category != nil && *category == models.FeeCategory {
+		return ErrReservedCategory
+	}
+
+	return nil
+}
+
+// ensureCategory checks that the category, when set, is in the catalog
+// Private function, not exposed to the API
+func ensureCategory(tx *gorm.DB, category *string) error {
+	if category == nil {
+		return nil
+	}
+
+	var count int64
+	if err := tx.Model(&models.Category{}).Where("code = ?", *category).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrCategoryNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,242 @@
+package ledger
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/closing"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestPostingsFor_Balanced(t *testing.T) {
+	for _, amount := range []money.Amount{money.MustParse("125.50"), money.MustParse("-40.25")} {
+		// When
+		postings := postingsFor(10001, amount)
+
+		// Then
+		require.Len(t, postings, 2)
+		require.Equal(t, amount, postings[0].Amount)
+		require.Zero(t, amount+postings[1].Amount)
+	}
+
+	require.Equal(t, CashInAccount, postingsFor(10001, 10)[1].Account)
+	require.Equal(t, CashOutAccount, postingsFor(10001, -10)[1].Account)
+}
+
+func TestPost_AccountNotFound(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	expectClosedThrough(dbMock, nil)
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs(999).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	// When
+	err := Post(gormDB, &models.Transaction{Account: 999, Amount: 10, Date: time.Now()})
+
+	// Then
+	require.ErrorIs(t, err, ErrAccountNotFound)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestPost_ReservedCategory(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	expectClosedThrough(dbMock, nil)
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account", "balance"}).AddRow(1, 10001, "100.00"))
+	dbMock.ExpectQuery(`SELECT count\(\*\) FROM "categories" WHERE code = (.+)`).
+		WithArgs(models.FeeCategory).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	// When
+	category := models.FeeCategory
+	err := Post(gormDB, &models.Transaction{Account: 10001, Amount: money.MustParse("-5"), Date: time.Now(), Category: &category})
+
+	// Then
+	require.ErrorIs(t, err, ErrReservedCategory)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestPost_RecordsBalancedEntry(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	date := time.Date(2023, 11, 25, 0, 0, 0, 0, time.UTC)
+
+	expectClosedThrough(dbMock, nil)
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account", "balance"}).AddRow(1, 10001, "0.00"))
+	expectPolicy(dbMock, 10001, sqlmock.NewRows([]string{"account_id", "overdraft_limit"}))
+	dbMock.ExpectQuery(`INSERT INTO "transactions"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	dbMock.ExpectQuery(`INSERT INTO "journal_entries"`).
+		WithArgs(7, "transaction posted", date, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	dbMock.ExpectQuery(`INSERT INTO "postings"`).
+		WithArgs(3, 10001, "25.00", sqlmock.AnyArg(), 3, CashInAccount, "-25.00", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	dbMock.ExpectQuery(`SELECT "account","version" FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account", "version"}).AddRow(10001, 1))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "postings" WHERE account_id = (.+)`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("25.00"))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "holds" WHERE account_id = (.+) AND status = (.+)`).
+		WithArgs(10001, models.HoldPending).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("0.00"))
+	dbMock.ExpectExec(`UPDATE "accounts" SET "available_balance"=(.+),"balance"=(.+),"version"=version \+ 1,"updated_at"=(.+) WHERE account = (.+) AND version = (.+)`).
+		WithArgs("25.00", "25.00", sqlmock.AnyArg(), 10001, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// When
+	transaction := models.Transaction{Account: 10001, Amount: money.MustParse("25"), Date: date}
+	err := Post(gormDB, &transaction)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, 7, transaction.ID)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReverse_FinalTransactions(t *testing.T) {
+	// Given
+	_, gormDB := setupTestDatabase(t)
+	id := 2
+
+	// When
+	_, transferErr := Reverse(gormDB, &models.Transaction{ID: 1, TransferID: &id}, time.Now())
+	_, reversedErr := Reverse(gormDB, &models.Transaction{ID: 1, ReversedBy: &id}, time.Now())
+
+	// Then
+	require.ErrorIs(t, transferErr, ErrTransferTransaction)
+	require.ErrorIs(t, reversedErr, ErrAlreadyReversed)
+}
+
+func TestRefreshBalance_RetriesOnConcurrentUpdate(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	for version, rowsAffected := range []int64{0, 1} {
+		dbMock.ExpectQuery(`SELECT "account","version" FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1`).
+			WithArgs(10001).
+			WillReturnRows(sqlmock.NewRows([]string{"account", "version"}).AddRow(10001, version+1))
+		dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "postings" WHERE account_id = (.+)`).
+			WithArgs(10001).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("25.00"))
+		dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "holds" WHERE account_id = (.+) AND status = (.+)`).
+			WithArgs(10001, models.HoldPending).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("0.00"))
+		dbMock.ExpectExec(`UPDATE "accounts" SET "available_balance"=(.+),"balance"=(.+),"version"=version \+ 1,"updated_at"=(.+) WHERE account = (.+) AND version = (.+)`).
+			WithArgs("25.00", "25.00", sqlmock.AnyArg(), 10001, version+1).
+			WillReturnResult(sqlmock.NewResult(0, rowsAffected))
+	}
+
+	// When
+	err := refreshBalance(gormDB, 10001)
+
+	// Then
+	require.NoError(t, err)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCapture_OnlyPendingHolds(t *testing.T) {
+	// Given
+	_, gormDB := setupTestDatabase(t)
+	hold := models.Hold{ID: 5, Account: 10001, Amount: money.MustParse("50"), Status: models.HoldReleased}
+
+	// When
+	_, err := Capture(gormDB, &hold, 0, time.Now())
+
+	// Then
+	require.ErrorIs(t, err, ErrHoldNotPending)
+
+	hold.Status = models.HoldPending
+	_, err = Capture(gormDB, &hold, money.MustParse("50.01"), time.Now())
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+}
+
+func TestCapture_ClosedPeriod(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	closed := time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC)
+	expectClosedThrough(dbMock, &closed)
+	dbMock.ExpectQuery(`SELECT count\(\*\) FROM "periods" WHERE date = (.+) AND status = (.+)`).
+		WithArgs(time.Date(2023, 11, 25, 0, 0, 0, 0, time.UTC), models.PeriodOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	hold := models.Hold{ID: 5, Account: 10001, Amount: money.MustParse("50"), Status: models.HoldPending}
+
+	// When
+	_, err := Capture(gormDB, &hold, 0, time.Date(2023, 11, 25, 0, 0, 0, 0, time.UTC))
+
+	// Then
+	require.ErrorIs(t, err, closing.ErrPeriodClosed)
+	require.Equal(t, models.HoldPending, hold.Status)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestTransfer_ClosedPeriod(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	closed := time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC)
+	expectClosedThrough(dbMock, &closed)
+	dbMock.ExpectQuery(`SELECT count\(\*\) FROM "periods" WHERE date = (.+) AND status = (.+)`).
+		WithArgs(time.Date(2023, 11, 25, 0, 0, 0, 0, time.UTC), models.PeriodOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// When
+	transfer := models.Transfer{From: 10001, To: 10002, Amount: money.MustParse("25"), Date: time.Date(2023, 11, 25, 0, 0, 0, 0, time.UTC)}
+	err := Transfer(gormDB, &transfer)
+
+	// Then
+	require.ErrorIs(t, err, closing.ErrPeriodClosed)
+	require.Zero(t, transfer.ID)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReverseTransfer_DatedOnGivenDay(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	closed := time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC)
+	expectClosedThrough(dbMock, &closed)
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1 FOR UPDATE`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account"}))
+	transfer := models.Transfer{ID: 7, From: 10001, To: 10002, Amount: money.MustParse("25"), Date: time.Date(2023, 11, 25, 0, 0, 0, 0, time.UTC), Status: models.TransferPosted}
+
+	// When
+	_, err := ReverseTransfer(gormDB, &transfer, time.Date(2023, 12, 5, 0, 0, 0, 0, time.UTC))
+
+	// Then
+	require.ErrorIs(t, err, ErrAccountNotFound)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// expectClosedThrough expects the lookup of the last closed day, none when
+// day is nil
+func expectClosedThrough(dbMock sqlmock.Sqlmock, day *time.Time) {
+	rows := sqlmock.NewRows([]string{"max"}).AddRow(nil)
+	if day != nil {
+		rows = sqlmock.NewRows([]string{"max"}).AddRow(*day)
+	}
+	dbMock.ExpectQuery(`SELECT MAX\(date\) FROM "periods"`).WillReturnRows(rows)
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	// Create a mock database for testing
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// Replace the actual database with the mock database for testing
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
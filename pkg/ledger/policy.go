@@ -0,0 +1,166 @@
+package ledger
+
+import (
+	"fmt"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Rules of an account policy, reported in a PolicyViolation
+const (
+	RuleOverdraftLimit    = "overdraft_limit"
+	RuleMaxDebit          = "max_debit"
+	RuleDailyDebitLimit   = "daily_debit_limit"
+	RuleMonthlyDebitLimit = "monthly_debit_limit"
+	RuleAllowedCurrencies = "allowed_currencies"
+)
+
+// PolicyViolation is returned when a write is rejected by the policy of
+// an account. It is meant to be returned to the client as is.
+type PolicyViolation struct {
+	Account   int           `json:"account"`
+	Rule      string        `json:"rule"`
+	Limit     *money.Amount `json:"limit,omitempty" swaggertype:"number"`
+	Attempted *money.Amount `json:"attempted,omitempty" swaggertype:"number"`
+	Currency  string        `json:"currency,omitempty"`
+}
+
+func (v *PolicyViolation) Error() string {
+	switch v.Rule {
+	case RuleOverdraftLimit:
+		return "insufficient funds"
+	case RuleMaxDebit:
+		return "debit exceeds the maximum single debit of the account"
+	case RuleDailyDebitLimit:
+		return "daily debit limit of the account exceeded"
+	case RuleMonthlyDebitLimit:
+		return "monthly debit limit of the account exceeded"
+	case RuleAllowedCurrencies:
+		return fmt.Sprintf("currency %s is not allowed on the account", v.Currency)
+	default:
+		return "rejected by the account policy"
+	}
+}
+
+// Policy returns the policy of the account, or the default policy when
+// the account has none.
+func Policy(tx *gorm.DB, account int) (models.AccountPolicy, error) {
+	var policies []models.AccountPolicy
+
+	if err := tx.Where("account_id = ?", account).Limit(1).Find(&policies).Error; err != nil {
+		return models.AccountPolicy{}, err
+	}
+	if len(policies) == 0 {
+		return models.AccountPolicy{Account: account}, nil
+	}
+
+	return policies[0], nil
+}
+
+// enforcePolicy checks a movement of amount in currency on the given
+// account against its policy. The account must be locked by the caller so
+// its available balance cannot change until the movement is recorded.
+// Private function, not exposed to the API
+func enforcePolicy(tx *gorm.DB, account models.Account, amount money.Amount, currency string, date time.Time) error {
+	policy, err := Policy(tx, account.Account)
+	if err != nil {
+		return err
+	}
+
+	return checkPolicy(policy, account.AvailableBalance, amount, currency, date, func(from time.Time, to time.Time) (money.Amount, error) {
+		return debitedBetween(tx, account.Account, from, to)
+	})
+}
+
+// checkPolicy checks a movement of amount in currency against the policy,
+// given the available balance of the account and debited, which returns
+// the debits of the account dated in [from, to)
+// Private function, not exposed to the API
+func checkPolicy(policy models.AccountPolicy, available money.Amount, amount money.Amount, currency string, date time.Time,
+	debited func(from time.Time, to time.Time) (money.Amount, error)) error {
+	if !allowsCurrency(policy, currency) {
+		return &PolicyViolation{Account: policy.Account, Rule: RuleAllowedCurrencies, Currency: currency}
+	}
+
+	// Credits are only restricted by their currency
+	if amount >= 0 {
+		return nil
+	}
+	debit := -amount
+
+	if policy.MaxDebit != nil && debit > *policy.MaxDebit {
+		return &PolicyViolation{Account: policy.Account, Rule: RuleMaxDebit, Limit: policy.MaxDebit, Attempted: &debit}
+	}
+
+	if policy.DailyDebitLimit != nil {
+		if err := enforceDebitLimit(policy.Account, RuleDailyDebitLimit, *policy.DailyDebitLimit,
+			date, date.AddDate(0, 0, 1), debit, debited); err != nil {
+			return err
+		}
+	}
+
+	if policy.MonthlyDebitLimit != nil {
+		monthStart := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+		if err := enforceDebitLimit(policy.Account, RuleMonthlyDebitLimit, *policy.MonthlyDebitLimit,
+			monthStart, monthStart.AddDate(0, 1, 0), debit, debited); err != nil {
+			return err
+		}
+	}
+
+	// The available balance may go below zero by at most the overdraft limit
+	if overdraft := debit - available; overdraft > policy.OverdraftLimit {
+		return &PolicyViolation{Account: policy.Account, Rule: RuleOverdraftLimit, Limit: &policy.OverdraftLimit, Attempted: &overdraft}
+	}
+
+	return nil
+}
+
+// enforceDebitLimit checks that the debits of the account dated in
+// [from, to), plus the given debit, stay within limit
+// Private function, not exposed to the API
+func enforceDebitLimit(account int, rule string, limit money.Amount, from time.Time, to time.Time, debit money.Amount,
+	debited func(from time.Time, to time.Time) (money.Amount, error)) error {
+	previous, err := debited(from, to)
+	if err != nil {
+		return err
+	}
+
+	if total := previous + debit; total > limit {
+		return &PolicyViolation{Account: account, Rule: rule, Limit: &limit, Attempted: &total}
+	}
+
+	return nil
+}
+
+// debitedBetween returns the debits of the account dated in [from, to)
+// Private function, not exposed to the API
+func debitedBetween(tx *gorm.DB, account int, from time.Time, to time.Time) (money.Amount, error) {
+	var debited money.Amount
+
+	err := tx.Model(&models.Transaction{}).
+		Select("COALESCE(-SUM(amount), 0)").
+		Where("account_id = ? AND amount < 0 AND date >= ? AND date < ?", account, from, to).
+		Scan(&debited).Error
+
+	return debited, err
+}
+
+// allowsCurrency reports whether the policy accepts the currency
+// Private function, not exposed to the API
+func allowsCurrency(policy models.AccountPolicy, currency string) bool {
+	if len(policy.AllowedCurrencies) == 0 {
+		return true
+	}
+
+	for _, allowed := range policy.AllowedCurrencies {
+		if strings.EqualFold(allowed, currency) {
+			return true
+		}
+	}
+
+	return false
+}
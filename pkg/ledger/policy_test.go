@@ -0,0 +1,103 @@
+package ledger
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"testing"
+	"time"
+)
+
+func TestEnforcePolicy_DefaultForbidsOverdraft(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	expectPolicy(dbMock, 10001, sqlmock.NewRows([]string{"account_id", "overdraft_limit"}))
+	account := models.Account{Account: 10001, AvailableBalance: money.MustParse("30")}
+
+	// When
+	err := enforcePolicy(gormDB, account, money.MustParse("-30.01"), models.DefaultCurrency, time.Now())
+
+	// Then
+	var violation *PolicyViolation
+	require.ErrorAs(t, err, &violation)
+	require.Equal(t, RuleOverdraftLimit, violation.Rule)
+	require.Equal(t, money.MustParse("0.01"), *violation.Attempted)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestEnforcePolicy_OverdraftLimit(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	expectPolicy(dbMock, 10001, sqlmock.NewRows([]string{"account_id", "overdraft_limit"}).AddRow(10001, "100.00"))
+	account := models.Account{Account: 10001, AvailableBalance: money.MustParse("30")}
+
+	// When
+	err := enforcePolicy(gormDB, account, money.MustParse("-130"), models.DefaultCurrency, time.Now())
+
+	// Then
+	require.NoError(t, err)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestEnforcePolicy_MaxDebit(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	expectPolicy(dbMock, 10001, sqlmock.NewRows([]string{"account_id", "overdraft_limit", "max_debit"}).AddRow(10001, "0.00", "50.00"))
+	account := models.Account{Account: 10001, AvailableBalance: money.MustParse("1000")}
+
+	// When
+	err := enforcePolicy(gormDB, account, money.MustParse("-50.01"), models.DefaultCurrency, time.Now())
+
+	// Then
+	var violation *PolicyViolation
+	require.ErrorAs(t, err, &violation)
+	require.Equal(t, RuleMaxDebit, violation.Rule)
+	require.Equal(t, money.MustParse("50"), *violation.Limit)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestEnforcePolicy_DailyDebitLimit(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	date := time.Date(2023, 11, 25, 0, 0, 0, 0, time.UTC)
+	expectPolicy(dbMock, 10001, sqlmock.NewRows([]string{"account_id", "overdraft_limit", "daily_debit_limit"}).AddRow(10001, "0.00", "100.00"))
+	dbMock.ExpectQuery(`SELECT COALESCE\(-SUM\(amount\), 0\) FROM "transactions" WHERE account_id = (.+) AND amount < 0 AND date >= (.+) AND date < (.+)`).
+		WithArgs(10001, date, date.AddDate(0, 0, 1)).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("80.00"))
+	account := models.Account{Account: 10001, AvailableBalance: money.MustParse("1000")}
+
+	// When
+	err := enforcePolicy(gormDB, account, money.MustParse("-20.01"), models.DefaultCurrency, date)
+
+	// Then
+	var violation *PolicyViolation
+	require.ErrorAs(t, err, &violation)
+	require.Equal(t, RuleDailyDebitLimit, violation.Rule)
+	require.Equal(t, money.MustParse("100.01"), *violation.Attempted)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestEnforcePolicy_AllowedCurrencies(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	expectPolicy(dbMock, 10001, sqlmock.NewRows([]string{"account_id", "overdraft_limit", "allowed_currencies"}).AddRow(10001, "0.00", `["USD"]`))
+	account := models.Account{Account: 10001}
+
+	// When
+	err := enforcePolicy(gormDB, account, money.MustParse("10"), "EUR", time.Now())
+
+	// Then
+	var violation *PolicyViolation
+	require.ErrorAs(t, err, &violation)
+	require.Equal(t, RuleAllowedCurrencies, violation.Rule)
+	require.Equal(t, "currency EUR is not allowed on the account", violation.Error())
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// expectPolicy expects the policy of the account to be loaded
+func expectPolicy(dbMock sqlmock.Sqlmock, account int, rows *sqlmock.Rows) {
+	dbMock.ExpectQuery(`SELECT \* FROM "account_policies" WHERE account_id = (.+) LIMIT 1`).
+		WithArgs(account).
+		WillReturnRows(rows)
+}
@@ -0,0 +1,104 @@
+package ledger
+
+import (
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAccountFrozen     = errors.New("account is frozen")
+	ErrAccountDormant    = errors.New("account is dormant")
+	ErrAccountClosed     = errors.New("account is closed")
+	ErrInvalidTransition = errors.New("account status transition not allowed")
+	ErrBalanceNotZero    = errors.New("account must have a zero balance and no pending holds to be closed")
+)
+
+// transitions lists the statuses an account may move to from each status.
+// Closed accounts are final.
+var transitions = map[string][]string{
+	models.AccountActive:  {models.AccountFrozen, models.AccountDormant, models.AccountClosed},
+	models.AccountFrozen:  {models.AccountActive, models.AccountClosed},
+	models.AccountDormant: {models.AccountActive, models.AccountFrozen, models.AccountClosed},
+}
+
+// CanTransition reports whether an account may move between the statuses
+func CanTransition(from string, to string) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Transition moves the account to the given status and records the change
+// with its reason and the user who made it. Closing an account requires a
+// zero balance without pending holds, and cancels its schedules. The
+// account must be locked by the caller.
+func Transition(tx *gorm.DB, account *models.Account, status string, reason string, actor string) (*models.AccountStatusChange, error) {
+	if !CanTransition(account.Status, status) {
+		return nil, ErrInvalidTransition
+	}
+
+	if status == models.AccountClosed {
+		if account.Balance != 0 || account.AvailableBalance != 0 {
+			return nil, ErrBalanceNotZero
+		}
+
+		err := tx.Model(&models.Schedule{}).
+			Where("account_id = ? AND status IN ?", account.Account, []string{models.ScheduleActive, models.SchedulePaused}).
+			Update("status", models.ScheduleCancelled).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	change := models.AccountStatusChange{Account: account.Account, From: account.Status, To: status, Reason: reason, Actor: actor}
+	if err := tx.Create(&change).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	err := tx.Model(account).Updates(map[string]interface{}{
+		"status":            status,
+		"status_reason":     reason,
+		"status_changed_at": now,
+		"version":           gorm.Expr("version + 1"),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	account.Status = status
+	account.StatusReason = reason
+	account.StatusChangedAt = &now
+	account.Version++
+
+	return &change, nil
+}
+
+// ensurePostable checks that the status of the account accepts a movement
+// of amount. Frozen and dormant accounts only accept credits, closed
+// accounts accept nothing.
+// Private function, not exposed to the API
+func ensurePostable(account models.Account, amount money.Amount) error {
+	switch account.Status {
+	case models.AccountClosed:
+		return ErrAccountClosed
+	case models.AccountFrozen:
+		if amount < 0 {
+			return ErrAccountFrozen
+		}
+	case models.AccountDormant:
+		if amount < 0 {
+			return ErrAccountDormant
+		}
+	}
+
+	return nil
+}
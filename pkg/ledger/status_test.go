@@ -0,0 +1,49 @@
+package ledger
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"testing"
+	"time"
+)
+
+func TestCanTransition(t *testing.T) {
+	require.True(t, CanTransition(models.AccountActive, models.AccountFrozen))
+	require.True(t, CanTransition(models.AccountFrozen, models.AccountActive))
+	require.True(t, CanTransition(models.AccountDormant, models.AccountClosed))
+	require.False(t, CanTransition(models.AccountFrozen, models.AccountDormant))
+	require.False(t, CanTransition(models.AccountActive, models.AccountActive))
+	require.False(t, CanTransition(models.AccountClosed, models.AccountActive))
+}
+
+func TestEnsurePostable(t *testing.T) {
+	debit, credit := money.MustParse("-10"), money.MustParse("10")
+
+	require.NoError(t, ensurePostable(models.Account{Status: models.AccountActive}, debit))
+	require.NoError(t, ensurePostable(models.Account{Status: models.AccountFrozen}, credit))
+	require.ErrorIs(t, ensurePostable(models.Account{Status: models.AccountFrozen}, debit), ErrAccountFrozen)
+	require.NoError(t, ensurePostable(models.Account{Status: models.AccountDormant}, credit))
+	require.ErrorIs(t, ensurePostable(models.Account{Status: models.AccountDormant}, debit), ErrAccountDormant)
+	require.ErrorIs(t, ensurePostable(models.Account{Status: models.AccountClosed}, credit), ErrAccountClosed)
+}
+
+func TestTransfer_FrozenSource(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	expectClosedThrough(dbMock, nil)
+	for _, account := range [][]interface{}{{10001, models.AccountFrozen}, {10002, models.AccountActive}} {
+		dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1 FOR UPDATE`).
+			WithArgs(account[0]).
+			WillReturnRows(sqlmock.NewRows([]string{"account", "balance", "available_balance", "status"}).
+				AddRow(account[0], "100.00", "100.00", account[1]))
+	}
+
+	// When
+	err := Transfer(gormDB, &models.Transfer{From: 10001, To: 10002, Amount: money.MustParse("25"), Date: time.Now()})
+
+	// Then
+	require.ErrorIs(t, err, ErrAccountFrozen)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,143 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"github.com/wjoseperez20/zenwallet/pkg/closing"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrSameAccount      = errors.New("source and destination accounts must differ")
+	ErrTransferReversed = errors.New("transfer already reversed")
+)
+
+// Transfer moves the amount of the given transfer from its source account
+// to its destination account. Both legs are stored as transactions linked
+// to the transfer, each with its own journal entry. The debit must comply
+// with the policy of the source account. It must be called inside a
+// database transaction.
+func Transfer(tx *gorm.DB, transfer *models.Transfer) error {
+	return move(tx, transfer, true)
+}
+
+// ReverseTransfer posts a transfer in the opposite direction of the given
+// one, dated on the given day, and marks the original as reversed. The
+// reversal is not subject to the policy of the account it debits.
+func ReverseTransfer(tx *gorm.DB, transfer *models.Transfer, date time.Time) (*models.Transfer, error) {
+	if transfer.Status == models.TransferReversed {
+		return nil, ErrTransferReversed
+	}
+
+	reversal := models.Transfer{
+		From:       transfer.To,
+		To:         transfer.From,
+		Amount:     transfer.Amount,
+		Date:       date,
+		ReversalOf: &transfer.ID,
+	}
+	if err := move(tx, &reversal, false); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Model(transfer).Update("status", models.TransferReversed).Error; err != nil {
+		return nil, err
+	}
+
+	return &reversal, nil
+}
+
+// move stores the transfer, its two legs and the journal entry between
+// them. The date of the transfer must not be in a closed period.
+// Private function, not exposed to the API
+func move(tx *gorm.DB, transfer *models.Transfer, enforce bool) error {
+	if transfer.From == transfer.To {
+		return ErrSameAccount
+	}
+
+	if err := closing.EnsureOpen(tx, transfer.Date); err != nil {
+		return err
+	}
+
+	accounts, err := lockAccounts(tx, transfer.From, transfer.To)
+	if err != nil {
+		return err
+	}
+
+	if err := ensurePostable(accounts[transfer.From], -transfer.Amount); err != nil {
+		return err
+	}
+	if err := ensurePostable(accounts[transfer.To], transfer.Amount); err != nil {
+		return err
+	}
+
+	if enforce {
+		err := enforcePolicy(tx, accounts[transfer.From], -transfer.Amount, models.DefaultCurrency, transfer.Date)
+		if err != nil {
+			return err
+		}
+		err = enforcePolicy(tx, accounts[transfer.To], transfer.Amount, models.DefaultCurrency, transfer.Date)
+		if err != nil {
+			return err
+		}
+	}
+
+	transfer.Status = models.TransferPosted
+	if err := tx.Omit(clause.Associations).Create(transfer).Error; err != nil {
+		return err
+	}
+
+	debit := models.Transaction{Account: transfer.From, Date: transfer.Date, Amount: -transfer.Amount, Currency: models.DefaultCurrency, TransferID: &transfer.ID}
+	credit := models.Transaction{Account: transfer.To, Date: transfer.Date, Amount: transfer.Amount, Currency: models.DefaultCurrency, TransferID: &transfer.ID}
+	if err := tx.Create(&debit).Error; err != nil {
+		return err
+	}
+	if err := tx.Create(&credit).Error; err != nil {
+		return err
+	}
+	transfer.Transactions = []models.Transaction{debit, credit}
+
+	// Each leg is balanced against the transit account, which nets to zero
+	description := fmt.Sprintf("transfer %d", transfer.ID)
+	if err := record(tx, debit.ID, transfer.Date, description, []models.Posting{
+		{Account: transfer.From, Amount: debit.Amount},
+		{Account: TransitAccount, Amount: -debit.Amount},
+	}); err != nil {
+		return err
+	}
+
+	return record(tx, credit.ID, transfer.Date, description, []models.Posting{
+		{Account: TransitAccount, Amount: -credit.Amount},
+		{Account: transfer.To, Amount: credit.Amount},
+	})
+}
+
+// lockAccounts loads the given accounts with a row lock. Rows are locked in
+// ascending account number so concurrent transfers cannot deadlock.
+// Private function, not exposed to the API
+func lockAccounts(tx *gorm.DB, numbers ...int) (map[int]models.Account, error) {
+	sorted := append([]int(nil), numbers...)
+	sort.Ints(sorted)
+
+	accounts := make(map[int]models.Account, len(sorted))
+	for _, number := range sorted {
+		var account models.Account
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("account = ?", number).First(&account).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrAccountNotFound
+			}
+			return nil, err
+		}
+
+		accounts[number] = account
+	}
+
+	return accounts, nil
+}
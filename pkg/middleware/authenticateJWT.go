@@ -44,7 +44,13 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 
-		c.Set("username", claims.Username)
+		// Tokens issued before the username claim existed only carry it as issuer
+		username := claims.Username
+		if username == "" {
+			username = claims.Issuer
+		}
+
+		c.Set("username", username)
 		c.Next()
 	}
 }
@@ -14,9 +14,9 @@ func Cors() gin.HandlerFunc {
 			"http://127.0.0.1:8001",
 			"http://localhost",
 			"http://localhost:8001"},
-		AllowMethods: []string{"*"},
-		AllowHeaders: []string{"*"},
-		//ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"*"},
+		AllowHeaders:     []string{"*"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 		//AllowOriginFunc: func(origin string) bool {
 		//	return origin == "https://github.com"
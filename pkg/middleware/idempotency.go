@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/wjoseperez20/zenwallet/pkg/cache"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	idempotencyHeader = "Idempotency-Key"
+	idempotencyTTL    = 24 * time.Hour
+)
+
+// idempotentResponse is the record stored in Redis for every Idempotency-Key
+type idempotentResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	Completed   bool   `json:"completed"`
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// bodyRecorder keeps a copy of the response body written by the handler
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Idempotency makes retries of a request carrying an Idempotency-Key
+// header safe. The first response for a key is stored in Redis and
+// replayed for any later request with the same key and body. Reusing a key
+// with a different request is rejected with 422, and a retry arriving while
+// the first request is still running is rejected with 409. Server errors,
+// conflicts and rate limits are transient and not stored, so a retry runs
+// the request again. Requests without the header are processed normally. It
+// must run after JWTAuth.
+func Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(idempotencyHeader)
+		if idempotencyKey == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Keys are scoped to the user and the fingerprint covers the whole request
+		cacheKey := "idempotency_" + c.GetString("username") + "_" + idempotencyKey
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		pending, err := json.Marshal(idempotentResponse{Fingerprint: fingerprint})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal data"})
+			c.Abort()
+			return
+		}
+
+		// Claim the key, only one request can hold it
+		claimed, err := cache.Rdb.SetNX(cache.Ctx, cacheKey, pending, idempotencyTTL).Result()
+		if err != nil {
+			log.Default().Println(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read idempotency key"})
+			c.Abort()
+			return
+		}
+
+		if !claimed {
+			replayResponse(c, cacheKey, fingerprint)
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		// Transient failures are not stored so the client can retry them
+		if !storable(recorder.Status()) {
+			cache.Rdb.Del(cache.Ctx, cacheKey)
+			return
+		}
+
+		completed, err := json.Marshal(idempotentResponse{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		if err != nil {
+			log.Default().Println(err)
+			cache.Rdb.Del(cache.Ctx, cacheKey)
+			return
+		}
+
+		if err := cache.Rdb.Set(cache.Ctx, cacheKey, completed, idempotencyTTL).Err(); err != nil {
+			log.Default().Println(err)
+		}
+	}
+}
+
+// replayResponse answers a retried request with the stored response
+// Private function, not exposed to the API
+func replayResponse(c *gin.Context, cacheKey string, fingerprint string) {
+	var stored idempotentResponse
+
+	cached, err := cache.Rdb.Get(cache.Ctx, cacheKey).Result()
+	if err == redis.Nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Idempotency-Key was released, retry the request"})
+		c.Abort()
+		return
+	}
+	if err != nil {
+		log.Default().Println(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read idempotency key"})
+		c.Abort()
+		return
+	}
+
+	if err := json.Unmarshal([]byte(cached), &stored); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unmarshal cached data"})
+		c.Abort()
+		return
+	}
+
+	switch {
+	case stored.Fingerprint != fingerprint:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key already used with a different request"})
+	case !stored.Completed:
+		c.JSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still being processed"})
+	default:
+		c.Header("Idempotent-Replayed", "true")
+		c.Data(stored.Status, stored.ContentType, stored.Body)
+	}
+
+	c.Abort()
+}
+
+// storable reports whether a response with the given status is final and
+// can be replayed, as opposed to a transient failure worth retrying
+// Private function, not exposed to the API
+func storable(status int) bool {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return false
+	case status == http.StatusConflict, status == http.StatusTooManyRequests:
+		return false
+	default:
+		return true
+	}
+}
+
+// requestFingerprint hashes the parts of a request that must not change between retries
+// Private function, not exposed to the API
+func requestFingerprint(method string, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte(path))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,323 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/cache"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	// Given
+	setupFakeRedis(t)
+	calls := 0
+	r := setupIdempotentRouter(func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	// When
+	first := performIdempotentRequest(r, "key-1", `{"amount":10}`)
+	second := performIdempotentRequest(r, "key-1", `{"amount":10}`)
+
+	// Then
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", second.Header().Get("Content-Type"))
+	assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+	assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotency_DifferentRequest(t *testing.T) {
+	// Given
+	setupFakeRedis(t)
+	calls := 0
+	r := setupIdempotentRouter(func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	// When
+	performIdempotentRequest(r, "key-1", `{"amount":10}`)
+	w := performIdempotentRequest(r, "key-1", `{"amount":20}`)
+
+	// Then
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, `{"error":"Idempotency-Key already used with a different request"}`, w.Body.String())
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	// Given
+	store := setupFakeRedis(t)
+	calls := 0
+	r := setupIdempotentRouter(func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	// The first request claimed the key and has not completed yet
+	pending, err := json.Marshal(idempotentResponse{Fingerprint: requestFingerprint(http.MethodPost, "/transactions", []byte(`{"amount":10}`))})
+	require.NoError(t, err)
+	store.set("idempotency__key-1", string(pending))
+
+	// When
+	w := performIdempotentRequest(r, "key-1", `{"amount":10}`)
+
+	// Then
+	assert.Zero(t, calls)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, `{"error":"A request with this Idempotency-Key is still being processed"}`, w.Body.String())
+}
+
+func TestIdempotency_ServerErrorReleasesKey(t *testing.T) {
+	// Given
+	store := setupFakeRedis(t)
+	calls := 0
+	r := setupIdempotentRouter(func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create transaction"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	// When
+	first := performIdempotentRequest(r, "key-1", `{"amount":10}`)
+	_, stored := store.get("idempotency__key-1")
+	second := performIdempotentRequest(r, "key-1", `{"amount":10}`)
+
+	// Then
+	assert.Equal(t, http.StatusInternalServerError, first.Code)
+	assert.False(t, stored)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Empty(t, second.Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotency_ConflictReleasesKey(t *testing.T) {
+	// Given
+	store := setupFakeRedis(t)
+	calls := 0
+	r := setupIdempotentRouter(func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			c.JSON(http.StatusConflict, gin.H{"error": "account was updated concurrently, retry the request"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	// When
+	first := performIdempotentRequest(r, "key-1", `{"amount":10}`)
+	_, stored := store.get("idempotency__key-1")
+	second := performIdempotentRequest(r, "key-1", `{"amount":10}`)
+
+	// Then
+	assert.Equal(t, http.StatusConflict, first.Code)
+	assert.False(t, stored)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Empty(t, second.Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotency_ReplaysValidationError(t *testing.T) {
+	// Given
+	setupFakeRedis(t)
+	calls := 0
+	r := setupIdempotentRouter(func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+	})
+
+	// When
+	performIdempotentRequest(r, "key-1", `{"amount":10}`)
+	w := performIdempotentRequest(r, "key-1", `{"amount":10}`)
+
+	// Then
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+}
+
+func TestIdempotency_WithoutKey(t *testing.T) {
+	// Given
+	setupFakeRedis(t)
+	calls := 0
+	r := setupIdempotentRouter(func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	// When
+	performIdempotentRequest(r, "", `{"amount":10}`)
+	performIdempotentRequest(r, "", `{"amount":10}`)
+
+	// Then
+	assert.Equal(t, 2, calls)
+}
+
+// setupIdempotentRouter returns a router serving the handler behind the middleware
+func setupIdempotentRouter(handler gin.HandlerFunc) *gin.Engine {
+	r := gin.Default()
+	r.POST("/transactions", Idempotency(), handler)
+
+	return r
+}
+
+// performIdempotentRequest posts the body with the Idempotency-Key, if any
+func performIdempotentRequest(r http.Handler, key string, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodPost, "/transactions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(idempotencyHeader, key)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+// fakeRedis is an in-memory Redis answering the commands of the middleware:
+// SET, with NX and an expiration, GET and DEL. Expirations are ignored.
+type fakeRedis struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// setupFakeRedis points cache.Rdb to a new fakeRedis
+func setupFakeRedis(t *testing.T) *fakeRedis {
+	store := &fakeRedis{values: make(map[string]string)}
+
+	cache.Rdb = redis.NewClient(&redis.Options{
+		Addr: "fake",
+		Dialer: func(ctx context.Context, network string, addr string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go store.serve(server)
+			return client, nil
+		},
+	})
+	t.Cleanup(func() {
+		cache.Rdb.Close()
+		cache.Rdb = nil
+	})
+
+	return store
+}
+
+// get returns the value stored under key, if any
+func (s *fakeRedis) get(key string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// set stores the value under key
+func (s *fakeRedis) set(key string, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value
+}
+
+// serve answers the commands read from the connection until it is closed
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		if _, err := conn.Write([]byte(s.execute(args))); err != nil {
+			return
+		}
+	}
+}
+
+// execute runs a command and returns its encoded reply
+func (s *fakeRedis) execute(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch strings.ToUpper(args[0]) {
+	case "SET":
+		key, value := args[1], args[2]
+		for _, option := range args[3:] {
+			if _, exists := s.values[key]; strings.ToUpper(option) == "NX" && exists {
+				return "$-1\r\n"
+			}
+		}
+		s.values[key] = value
+		return "+OK\r\n"
+	case "GET":
+		value, ok := s.values[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := s.values[key]; ok {
+				delete(s.values, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	default:
+		return "-ERR unknown command\r\n"
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		length, err := strconv.Atoi(strings.TrimSpace(header[1:]))
+		if err != nil {
+			return nil, err
+		}
+
+		data := make([]byte, length+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args = append(args, string(data[:length]))
+	}
+
+	return args, nil
+}
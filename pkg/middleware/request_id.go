@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// RequestID tags every request with an ID, taken from the X-Request-ID
+// header when the client sends one. The ID is stored in the context as
+// "request_id" and returned in the response header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > 64 {
+			requestID = newRequestID()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(requestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// newRequestID returns a random 128-bit hexadecimal ID
+// Private function, not exposed to the API
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(id)
+}
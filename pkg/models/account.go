@@ -1,15 +1,35 @@
 package models
 
-import "time"
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+)
 
+const (
+	AccountActive  = "active"
+	AccountFrozen  = "frozen"
+	AccountDormant = "dormant"
+	AccountClosed  = "closed"
+)
+
+// Account holds the client data of an account. Its Balance is derived
+// from the ledger postings and is never written directly, AvailableBalance
+// is the Balance minus the pending holds. Version is increased on every
+// write and used for optimistic concurrency. Accounts are never deleted,
+// they are closed.
 type Account struct {
-	ID        int       `json:"id" gorm:"type:integer;autoIncrement:true"`
-	Client    string    `json:"client"`
-	Email     string    `json:"email" gorm:"uniqueIndex"`
-	Account   int       `json:"account"  gorm:"primary_key"`
-	Balance   float32   `json:"balance" sql:"type:decimal(10,2);"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID               int          `json:"id" gorm:"type:integer;autoIncrement:true"`
+	Client           string       `json:"client"`
+	Email            string       `json:"email" gorm:"uniqueIndex"`
+	Account          int          `json:"account"  gorm:"primary_key"`
+	Balance          money.Amount `json:"balance" sql:"type:decimal(10,2);" swaggertype:"number"`
+	AvailableBalance money.Amount `json:"available_balance" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Version          int          `json:"version" gorm:"type:integer;default:1"`
+	Status           string       `json:"status" gorm:"default:active"`
+	StatusReason     string       `json:"status_reason,omitempty"`
+	StatusChangedAt  *time.Time   `json:"status_changed_at,omitempty"`
+	CreatedAt        time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
 type CreateAccount struct {
@@ -21,3 +41,19 @@ type UpdateAccount struct {
 	Client string `json:"client"`
 	Email  string `json:"email"`
 }
+
+type UpdateAccountStatus struct {
+	Status string `json:"status" binding:"required" enums:"active,frozen,dormant,closed"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+// AccountStatusChange records a transition of the status of an account
+type AccountStatusChange struct {
+	ID        int       `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	Account   int       `json:"account" gorm:"type:integer;column:account_id"`
+	From      string    `json:"from" gorm:"column:from_status"`
+	To        string    `json:"to" gorm:"column:to_status"`
+	Reason    string    `json:"reason"`
+	Actor     string    `json:"actor,omitempty"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+
+	AuditReverse      = "reverse"
+	AuditCapture      = "capture"
+	AuditRelease      = "release"
+	AuditProcess      = "process"
+	AuditApplyRules   = "apply_rules"
+	AuditClose        = "close"
+	AuditReopen       = "reopen"
+	AuditChangeStatus = "change_status"
+	AuditPause        = "pause"
+	AuditResume       = "resume"
+	AuditCancel       = "cancel"
+	AuditReconcile    = "reconcile"
+)
+
+// AuditEntry records a write made through the API: who made it, on which
+// entity, and the state of the entity before and after. Entries are never
+// updated nor deleted.
+type AuditEntry struct {
+	ID        int         `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	Actor     string      `json:"actor"`
+	Action    string      `json:"action"`
+	Entity    string      `json:"entity"`
+	EntityID  string      `json:"entity_id"`
+	Before    interface{} `json:"before,omitempty" gorm:"serializer:json" swaggertype:"object"`
+	After     interface{} `json:"after,omitempty" gorm:"serializer:json" swaggertype:"object"`
+	RequestID string      `json:"request_id"`
+	ClientIP  string      `json:"client_ip"`
+	CreatedAt time.Time   `json:"created_at" gorm:"autoCreateTime"`
+}
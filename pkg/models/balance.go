@@ -0,0 +1,26 @@
+package models
+
+import "github.com/wjoseperez20/zenwallet/pkg/money"
+
+// AccountBalance is the balance of an account at the end of a day, AsOf
+// formatted as 2006-01-02
+type AccountBalance struct {
+	Account int          `json:"account"`
+	AsOf    string       `json:"as_of"`
+	Balance money.Amount `json:"balance" swaggertype:"number"`
+}
+
+// BalanceSeries holds the end-of-day balances of an account over a range
+// of days, one per day from From to To
+type BalanceSeries struct {
+	Account  int            `json:"account"`
+	From     string         `json:"from"`
+	To       string         `json:"to"`
+	Balances []BalancePoint `json:"balances"`
+}
+
+// BalancePoint is the balance of an account at the end of a day
+type BalancePoint struct {
+	Date    string       `json:"date"`
+	Balance money.Amount `json:"balance" swaggertype:"number"`
+}
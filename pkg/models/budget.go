@@ -0,0 +1,60 @@
+package models
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+)
+
+// Budget caps the monthly debits of a category, either on one account or
+// on every account of a client. An alert is sent the first time the
+// spending of a month reaches each of its thresholds, expressed as
+// percentages of the limit.
+type Budget struct {
+	ID         int          `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	Name       string       `json:"name"`
+	Account    *int         `json:"account,omitempty" gorm:"type:integer;column:account_id"`
+	Client     string       `json:"client,omitempty"`
+	Category   string       `json:"category"`
+	Limit      money.Amount `json:"limit" gorm:"column:limit_amount" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Thresholds []int        `json:"thresholds" gorm:"serializer:json"`
+	CreatedAt  time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BudgetAlert records that the spending of a budget reached a threshold
+// during a month, so the alert is sent only once
+type BudgetAlert struct {
+	ID        int          `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	BudgetID  int          `json:"budget_id" gorm:"type:integer"`
+	Period    time.Time    `json:"period"`
+	Threshold int          `json:"threshold"`
+	Spent     money.Amount `json:"spent" sql:"type:decimal(10,2);" swaggertype:"number"`
+	CreatedAt time.Time    `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BudgetProgress is the consumption of a budget during a month
+type BudgetProgress struct {
+	BudgetID  int           `json:"budget_id"`
+	Period    string        `json:"period"`
+	Limit     money.Amount  `json:"limit" swaggertype:"number"`
+	Spent     money.Amount  `json:"spent" swaggertype:"number"`
+	Remaining money.Amount  `json:"remaining" swaggertype:"number"`
+	Percent   int           `json:"percent"`
+	Crossed   []int         `json:"crossed"`
+	Alerts    []BudgetAlert `json:"alerts"`
+}
+
+type CreateBudget struct {
+	Name       string       `json:"name" binding:"required"`
+	Account    *int         `json:"account"`
+	Client     string       `json:"client"`
+	Category   string       `json:"category" binding:"required"`
+	Limit      money.Amount `json:"limit" binding:"required" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Thresholds []int        `json:"thresholds"`
+}
+
+type UpdateBudget struct {
+	Name       string       `json:"name" binding:"required"`
+	Limit      money.Amount `json:"limit" binding:"required" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Thresholds []int        `json:"thresholds"`
+}
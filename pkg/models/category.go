@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Category classifies transactions. Transactions reference it by code.
+type Category struct {
+	ID        int       `json:"id" gorm:"type:integer;autoIncrement:true"`
+	Code      string    `json:"code" gorm:"primary_key"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type CreateCategory struct {
+	Code string `json:"code" binding:"required"`
+	Name string `json:"name" binding:"required"`
+}
+
+type UpdateCategory struct {
+	Name string `json:"name" binding:"required"`
+}
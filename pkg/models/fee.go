@@ -0,0 +1,58 @@
+package models
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+)
+
+// FeeCategory is the category of the fee transactions, reserved for them
+const FeeCategory = "fees"
+
+// FeeSchedule defines the fees charged to the accounts it is attached to.
+// Fees left empty are not charged. TransactionFee is charged on every
+// debit, and PercentageFeeBps is a rate, in basis points, of the debits
+// above PercentageThreshold. No fee is charged while the balance of the account
+// is at least WaiverBalance.
+type FeeSchedule struct {
+	ID                  int           `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	Name                string        `json:"name"`
+	MonthlyFee          *money.Amount `json:"monthly_fee,omitempty" sql:"type:decimal(10,2);" swaggertype:"number"`
+	TransactionFee      *money.Amount `json:"transaction_fee,omitempty" sql:"type:decimal(10,2);" swaggertype:"number"`
+	PercentageFeeBps    *int64        `json:"percentage_fee_bps,omitempty"`
+	PercentageThreshold money.Amount  `json:"percentage_threshold" sql:"type:decimal(10,2);" swaggertype:"number"`
+	WaiverBalance       *money.Amount `json:"waiver_balance,omitempty" sql:"type:decimal(10,2);" swaggertype:"number"`
+	CreatedAt           time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// AccountFee attaches a fee schedule to an account. NextCharge is the end
+// of the next month the monthly fee is charged for.
+type AccountFee struct {
+	Account       int       `json:"account" gorm:"type:integer;column:account_id;primary_key"`
+	FeeScheduleID int       `json:"fee_schedule_id" gorm:"type:integer"`
+	NextCharge    time.Time `json:"next_charge"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type CreateFeeSchedule struct {
+	Name                string        `json:"name" binding:"required"`
+	MonthlyFee          *money.Amount `json:"monthly_fee" sql:"type:decimal(10,2);" swaggertype:"number"`
+	TransactionFee      *money.Amount `json:"transaction_fee" sql:"type:decimal(10,2);" swaggertype:"number"`
+	PercentageFeeBps    *int64        `json:"percentage_fee_bps"`
+	PercentageThreshold money.Amount  `json:"percentage_threshold" sql:"type:decimal(10,2);" swaggertype:"number"`
+	WaiverBalance       *money.Amount `json:"waiver_balance" sql:"type:decimal(10,2);" swaggertype:"number"`
+}
+
+type UpdateFeeSchedule struct {
+	Name                string        `json:"name" binding:"required"`
+	MonthlyFee          *money.Amount `json:"monthly_fee" sql:"type:decimal(10,2);" swaggertype:"number"`
+	TransactionFee      *money.Amount `json:"transaction_fee" sql:"type:decimal(10,2);" swaggertype:"number"`
+	PercentageFeeBps    *int64        `json:"percentage_fee_bps"`
+	PercentageThreshold money.Amount  `json:"percentage_threshold" sql:"type:decimal(10,2);" swaggertype:"number"`
+	WaiverBalance       *money.Amount `json:"waiver_balance" sql:"type:decimal(10,2);" swaggertype:"number"`
+}
+
+type UpdateAccountFee struct {
+	FeeScheduleID int `json:"fee_schedule_id" binding:"required"`
+}
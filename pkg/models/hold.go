@@ -0,0 +1,41 @@
+package models
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+)
+
+const (
+	HoldPending  = "pending"
+	HoldCaptured = "captured"
+	HoldReleased = "released"
+	HoldExpired  = "expired"
+)
+
+// Hold is an authorization that reserves an amount of an account. A
+// pending hold reduces the available balance but not the balance, until
+// it is captured as a transaction, released or expired.
+type Hold struct {
+	ID            int          `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	Account       int          `json:"account" gorm:"type:integer;column:account_id"`
+	Amount        money.Amount `json:"amount" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Captured      money.Amount `json:"captured" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Description   string       `json:"description"`
+	Status        string       `json:"status"`
+	TransactionID *int         `json:"transaction_id,omitempty" gorm:"type:integer"`
+	ExpiresAt     time.Time    `json:"expires_at"`
+	CreatedAt     time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type CreateHold struct {
+	Account     int          `json:"account" binding:"required"`
+	Amount      money.Amount `json:"amount" binding:"required" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Description string       `json:"description"`
+	ExpiresAt   string       `json:"expires_at"`
+}
+
+type CaptureHold struct {
+	Amount money.Amount `json:"amount" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Date   string       `json:"date"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+)
+
+const (
+	DayCountActual365 = "ACT/365"
+	DayCountActual360 = "ACT/360"
+	DayCount30360     = "30/360"
+
+	CompoundingNone    = "none"
+	CompoundingDaily   = "daily"
+	CompoundingMonthly = "monthly"
+
+	PostingMonthly   = "monthly"
+	PostingQuarterly = "quarterly"
+	PostingAnnually  = "annually"
+)
+
+// InterestProduct makes an account earn interest on its end-of-day balance.
+// AnnualRate is a percentage. Accrued is the interest accrued and not posted
+// yet, in micro-cents, and Compounded is the part of it that already earns
+// interest. AccruedThrough is the last day accrued.
+type InterestProduct struct {
+	Account        int          `json:"account" gorm:"type:integer;column:account_id;primary_key"`
+	AnnualRate     float64      `json:"annual_rate"`
+	DayCount       string       `json:"day_count"`
+	Compounding    string       `json:"compounding"`
+	Posting        string       `json:"posting"`
+	StartDate      time.Time    `json:"start_date"`
+	AccruedThrough *time.Time   `json:"accrued_through,omitempty"`
+	Accrued        money.Micros `json:"accrued" sql:"type:decimal(20,8);" swaggertype:"number"`
+	Compounded     money.Micros `json:"compounded" sql:"type:decimal(20,8);" swaggertype:"number"`
+	CreatedAt      time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// InterestAccrual records the interest accrued on an account for one day,
+// with every input of the calculation. Base is the end-of-day Balance plus
+// the compounded interest, Fraction the part of the year the day accounts
+// for under the day count convention. Posted is set on the posting dates.
+type InterestAccrual struct {
+	ID            int           `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	Account       int           `json:"account" gorm:"type:integer;column:account_id"`
+	Date          time.Time     `json:"date"`
+	Balance       money.Amount  `json:"balance" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Base          money.Micros  `json:"base" sql:"type:decimal(20,8);" swaggertype:"number"`
+	AnnualRate    float64       `json:"annual_rate"`
+	DayCount      string        `json:"day_count"`
+	Fraction      float64       `json:"fraction"`
+	Interest      money.Micros  `json:"interest" sql:"type:decimal(20,8);" swaggertype:"number"`
+	Accrued       money.Micros  `json:"accrued" sql:"type:decimal(20,8);" swaggertype:"number"`
+	Posted        *money.Amount `json:"posted,omitempty" sql:"type:decimal(10,2);" swaggertype:"number"`
+	TransactionID *int          `json:"transaction_id,omitempty" gorm:"type:integer"`
+	CreatedAt     time.Time     `json:"created_at" gorm:"autoCreateTime"`
+}
+
+type UpdateInterestProduct struct {
+	AnnualRate  float64 `json:"annual_rate"`
+	DayCount    string  `json:"day_count" binding:"required" enums:"ACT/365,ACT/360,30/360"`
+	Compounding string  `json:"compounding" binding:"required" enums:"none,daily,monthly"`
+	Posting     string  `json:"posting" binding:"required" enums:"monthly,quarterly,annually"`
+}
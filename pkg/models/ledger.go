@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+)
+
+// JournalEntry groups the postings produced by a single ledger operation.
+// The amounts of its postings always add up to zero. TransactionID is zero
+// for entries that are not caused by a transaction, such as adjustments.
+type JournalEntry struct {
+	ID            int       `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	TransactionID int       `json:"transaction_id" gorm:"type:integer"`
+	Description   string    `json:"description"`
+	Date          time.Time `json:"date"`
+	Postings      []Posting `json:"postings"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Posting is one side of a journal entry. Positive amounts credit the
+// account and negative amounts debit it.
+type Posting struct {
+	ID             int          `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	JournalEntryID int          `json:"journal_entry_id" gorm:"type:integer"`
+	Account        int          `json:"account" gorm:"type:integer;column:account_id"`
+	Amount         money.Amount `json:"amount" sql:"type:decimal(10,2);" swaggertype:"number"`
+	CreatedAt      time.Time    `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+)
+
+const (
+	PeriodClosed = "closed"
+	PeriodOpen   = "open"
+)
+
+// Period is a business day closed by the closing job. Transactions dated
+// on or before the last closed day cannot be created nor changed, unless
+// their day was reopened.
+type Period struct {
+	Date       time.Time  `json:"date" gorm:"primary_key"`
+	Status     string     `json:"status"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty"`
+	ReopenedAt *time.Time `json:"reopened_at,omitempty"`
+	ReopenedBy string     `json:"reopened_by,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BalanceSnapshot holds the closing balance of an account at the end of a
+// closed day, and the totals of the transactions dated on that day
+type BalanceSnapshot struct {
+	Account          int          `json:"account" gorm:"type:integer;column:account_id;primary_key"`
+	Date             time.Time    `json:"date" gorm:"primary_key"`
+	OpeningBalance   money.Amount `json:"opening_balance" sql:"type:decimal(10,2);" swaggertype:"number"`
+	ClosingBalance   money.Amount `json:"closing_balance" sql:"type:decimal(10,2);" swaggertype:"number"`
+	TotalDebits      money.Amount `json:"total_debits" sql:"type:decimal(10,2);" swaggertype:"number"`
+	TotalCredits     money.Amount `json:"total_credits" sql:"type:decimal(10,2);" swaggertype:"number"`
+	DebitCount       int          `json:"debit_count"`
+	CreditCount      int          `json:"credit_count"`
+	TransactionCount int          `json:"transaction_count"`
+	CreatedAt        time.Time    `json:"created_at" gorm:"autoCreateTime"`
+}
+
+type ReopenPeriod struct {
+	Reason string `json:"reason" binding:"required"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+)
+
+// DefaultCurrency is the currency of the transactions that do not set one
+const DefaultCurrency = "USD"
+
+// AccountPolicy restricts the debits of an account. An account without a
+// policy cannot be overdrawn and has no other limit. Limits left empty are
+// not enforced, and an empty AllowedCurrencies accepts any currency.
+type AccountPolicy struct {
+	Account           int           `json:"account" gorm:"type:integer;column:account_id;primary_key"`
+	OverdraftLimit    money.Amount  `json:"overdraft_limit" sql:"type:decimal(10,2);" swaggertype:"number"`
+	MaxDebit          *money.Amount `json:"max_debit" sql:"type:decimal(10,2);" swaggertype:"number"`
+	DailyDebitLimit   *money.Amount `json:"daily_debit_limit" sql:"type:decimal(10,2);" swaggertype:"number"`
+	MonthlyDebitLimit *money.Amount `json:"monthly_debit_limit" sql:"type:decimal(10,2);" swaggertype:"number"`
+	AllowedCurrencies []string      `json:"allowed_currencies" gorm:"serializer:json"`
+	CreatedAt         time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type UpdateAccountPolicy struct {
+	OverdraftLimit    money.Amount  `json:"overdraft_limit" sql:"type:decimal(10,2);" swaggertype:"number"`
+	MaxDebit          *money.Amount `json:"max_debit" sql:"type:decimal(10,2);" swaggertype:"number"`
+	DailyDebitLimit   *money.Amount `json:"daily_debit_limit" sql:"type:decimal(10,2);" swaggertype:"number"`
+	MonthlyDebitLimit *money.Amount `json:"monthly_debit_limit" sql:"type:decimal(10,2);" swaggertype:"number"`
+	AllowedCurrencies []string      `json:"allowed_currencies"`
+}
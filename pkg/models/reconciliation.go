@@ -0,0 +1,25 @@
+package models
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+)
+
+// Discrepancy describes an account whose stored balance does not match
+// the sum of its transactions
+type Discrepancy struct {
+	Account                   int          `json:"account"`
+	Expected                  money.Amount `json:"expected" swaggertype:"number"`
+	Stored                    money.Amount `json:"stored" swaggertype:"number"`
+	Ledger                    money.Amount `json:"ledger" swaggertype:"number"`
+	FirstDivergentTransaction *int         `json:"first_divergent_transaction,omitempty"`
+	Fixed                     bool         `json:"fixed"`
+}
+
+// ReconciliationReport is the result of a reconciliation run
+type ReconciliationReport struct {
+	GeneratedAt     time.Time     `json:"generated_at"`
+	AccountsChecked int           `json:"accounts_checked"`
+	Fixed           bool          `json:"fixed"`
+	Discrepancies   []Discrepancy `json:"discrepancies"`
+}
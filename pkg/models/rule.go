@@ -0,0 +1,50 @@
+package models
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+)
+
+// Rule assigns a category and tags to the transactions matching all of
+// its conditions. Rules are evaluated by ascending priority and the first
+// matching rule applies. Amounts are compared signed, debits are negative.
+type Rule struct {
+	ID                  int           `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	Name                string        `json:"name"`
+	Priority            int           `json:"priority"`
+	Enabled             bool          `json:"enabled"`
+	DescriptionContains string        `json:"description_contains,omitempty"`
+	DescriptionPattern  string        `json:"description_pattern,omitempty"`
+	Counterparty        string        `json:"counterparty,omitempty"`
+	Account             *int          `json:"account,omitempty" gorm:"type:integer;column:account_id"`
+	MinAmount           *money.Amount `json:"min_amount,omitempty" sql:"type:decimal(10,2);" swaggertype:"number"`
+	MaxAmount           *money.Amount `json:"max_amount,omitempty" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Category            *string       `json:"category,omitempty"`
+	Tags                []string      `json:"tags,omitempty" gorm:"serializer:json"`
+	CreatedAt           time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type CreateRule struct {
+	Name                string        `json:"name" binding:"required"`
+	Priority            int           `json:"priority"`
+	Enabled             *bool         `json:"enabled"`
+	DescriptionContains string        `json:"description_contains"`
+	DescriptionPattern  string        `json:"description_pattern"`
+	Counterparty        string        `json:"counterparty"`
+	Account             *int          `json:"account"`
+	MinAmount           *money.Amount `json:"min_amount" sql:"type:decimal(10,2);" swaggertype:"number"`
+	MaxAmount           *money.Amount `json:"max_amount" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Category            string        `json:"category"`
+	Tags                []string      `json:"tags"`
+}
+
+// RuleMatch is a transaction a rule would change, with its current and new classification
+type RuleMatch struct {
+	TransactionID   int      `json:"transaction_id"`
+	Description     string   `json:"description"`
+	CurrentCategory *string  `json:"current_category,omitempty"`
+	Category        *string  `json:"category,omitempty"`
+	CurrentTags     []string `json:"current_tags,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+)
+
+const (
+	ScheduleDaily   = "daily"
+	ScheduleWeekly  = "weekly"
+	ScheduleMonthly = "monthly"
+
+	ScheduleActive    = "active"
+	SchedulePaused    = "paused"
+	ScheduleCancelled = "cancelled"
+	ScheduleCompleted = "completed"
+
+	RunPosted  = "posted"
+	RunFailed  = "failed"
+	RunSkipped = "skipped"
+)
+
+// Schedule is a standing order: a transaction template posted on every
+// occurrence of its schedule until its end date or its count is reached.
+type Schedule struct {
+	ID          int           `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	Account     int           `json:"account" gorm:"type:integer;column:account_id"`
+	Amount      money.Amount  `json:"amount" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Currency    string        `json:"currency"`
+	Description string        `json:"description"`
+	Frequency   string        `json:"frequency"`
+	DayOfMonth  int           `json:"day_of_month,omitempty"`
+	StartDate   time.Time     `json:"start_date"`
+	EndDate     *time.Time    `json:"end_date,omitempty"`
+	Count       *int          `json:"count,omitempty"`
+	Occurrences int           `json:"occurrences"`
+	NextRun     time.Time     `json:"next_run"`
+	Status      string        `json:"status"`
+	Runs        []ScheduleRun `json:"runs,omitempty" gorm:"foreignKey:ScheduleID"`
+	CreatedAt   time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// ScheduleRun records the outcome of one occurrence of a schedule
+type ScheduleRun struct {
+	ID            int       `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	ScheduleID    int       `json:"schedule_id" gorm:"type:integer"`
+	Date          time.Time `json:"date"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	TransactionID *int      `json:"transaction_id,omitempty" gorm:"type:integer"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type CreateSchedule struct {
+	Account     int          `json:"account" binding:"required"`
+	Amount      money.Amount `json:"amount" binding:"required" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Currency    string       `json:"currency"`
+	Description string       `json:"description"`
+	Frequency   string       `json:"frequency" binding:"required" enums:"daily,weekly,monthly"`
+	DayOfMonth  int          `json:"day_of_month"`
+	StartDate   string       `json:"start_date" binding:"required"`
+	EndDate     string       `json:"end_date"`
+	Count       int          `json:"count"`
+}
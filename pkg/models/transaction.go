@@ -1,24 +1,184 @@
 package models
 
-import "time"
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+)
 
 type Transaction struct {
-	ID        int       `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
-	Amount    float32   `json:"amount" sql:"type:decimal(10,2);"`
-	Date      time.Time `json:"date"`
-	Account   int       `json:"account" gorm:"type:integer;column:account_id;references:accounts(account)"`
+	ID         int          `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	Amount     money.Amount `json:"amount" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Date       time.Time    `json:"date"`
+	Account    int          `json:"account" gorm:"type:integer;column:account_id;references:accounts(account)"`
+	Currency   string       `json:"currency"`
+	TransferID *int         `json:"transfer_id,omitempty" gorm:"type:integer"`
+	ReversalOf *int         `json:"reversal_of,omitempty" gorm:"type:integer;column:reversal_of_id"`
+	ReversedBy *int         `json:"reversed_by,omitempty" gorm:"type:integer;column:reversed_by_id"`
+	FeeOf      *int         `json:"fee_of,omitempty" gorm:"type:integer;column:fee_of_id"`
+
+	// Fee marks the fees charged by the system, the only transactions in the fees category
+	Fee bool `json:"fee,omitempty"`
+
+	Description       string            `json:"description"`
+	Counterparty      string            `json:"counterparty"`
+	Category          *string           `json:"category,omitempty"`
+	ExternalReference string            `json:"external_reference"`
+	Metadata          map[string]string `json:"metadata,omitempty" gorm:"serializer:json"`
+	Tags              []string          `json:"tags,omitempty" gorm:"serializer:json"`
+
+	// RuleID is the rule that assigned the category, if it was not set explicitly
+	RuleID *int `json:"rule_id,omitempty" gorm:"type:integer"`
+
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// ReversalChain lists the transactions of the reversal chain, oldest first
+	ReversalChain []Transaction `json:"reversal_chain,omitempty" gorm:"-"`
+
+	// Fees lists the fee transactions charged for this transaction when it was posted
+	Fees []Transaction `json:"fees,omitempty" gorm:"-"`
+
+	// Splits lists the lines the amount is split into across categories, if any
+	Splits []TransactionSplit `json:"splits,omitempty" gorm:"-"`
+}
+
+// TransactionSplit is a line of a split transaction. The lines of a
+// transaction sum to its amount and replace its category in the reports.
+type TransactionSplit struct {
+	ID            int          `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	TransactionID int          `json:"transaction_id" gorm:"type:integer"`
+	Amount        money.Amount `json:"amount" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Category      string       `json:"category"`
+	Note          string       `json:"note"`
+	CreatedAt     time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type CreateTransactionSplit struct {
+	Amount   money.Amount `json:"amount" binding:"required" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Category string       `json:"category" binding:"required"`
+	Note     string       `json:"note"`
+}
+
+// UpdateTransactionSplits replaces the splits of a transaction, an empty
+// list removes them
+type UpdateTransactionSplits struct {
+	Splits []CreateTransactionSplit `json:"splits" binding:"dive"`
 }
 
 type CreateTransaction struct {
-	Account int     `json:"account" binding:"required"`
-	Date    string  `json:"date" binding:"required"`
-	Amount  float32 `json:"amount" binding:"required" sql:"type:decimal(10,2);"`
+	Account           int               `json:"account" binding:"required"`
+	Date              string            `json:"date" binding:"required"`
+	Amount            money.Amount      `json:"amount" binding:"required" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Currency          string            `json:"currency"`
+	Description       string            `json:"description"`
+	Counterparty      string            `json:"counterparty"`
+	Category          string            `json:"category"`
+	ExternalReference string            `json:"external_reference"`
+	Metadata          map[string]string `json:"metadata"`
+
+	// Splits optionally splits the amount across categories
+	Splits []CreateTransactionSplit `json:"splits" binding:"omitempty,dive"`
 }
 
 type UpdateTransaction struct {
-	Account int     `json:"account" binding:"required"`
-	Date    string  `json:"date" binding:"required"`
-	Amount  float32 `json:"amount" binding:"required" sql:"type:decimal(10,2);"`
+	Account           int               `json:"account" binding:"required"`
+	Date              string            `json:"date" binding:"required"`
+	Amount            money.Amount      `json:"amount" binding:"required" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Description       string            `json:"description"`
+	Counterparty      string            `json:"counterparty"`
+	Category          string            `json:"category"`
+	ExternalReference string            `json:"external_reference"`
+	Metadata          map[string]string `json:"metadata"`
+
+	// Splits replaces the splits of the transaction when set, an empty
+	// list removes them
+	Splits []CreateTransactionSplit `json:"splits" binding:"omitempty,dive"`
+}
+
+// CreateAccountTransaction creates a transaction on the account of the path
+type CreateAccountTransaction struct {
+	Date              string            `json:"date" binding:"required"`
+	Amount            money.Amount      `json:"amount" binding:"required" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Currency          string            `json:"currency"`
+	Description       string            `json:"description"`
+	Counterparty      string            `json:"counterparty"`
+	Category          string            `json:"category"`
+	ExternalReference string            `json:"external_reference"`
+	Metadata          map[string]string `json:"metadata"`
+
+	// Splits optionally splits the amount across categories
+	Splits []CreateTransactionSplit `json:"splits" binding:"omitempty,dive"`
+}
+
+// AccountTransaction is a transaction of an account with the balance of the
+// account right after it, in the order of the dates and then the IDs
+type AccountTransaction struct {
+	Transaction
+	RunningBalance money.Amount `json:"running_balance" swaggertype:"number"`
+}
+
+// AccountTransactionSummary totals the transactions of an account matching
+// a set of filters. The opening and closing balances are those of the
+// account before From and at the end of To, whatever the other filters.
+type AccountTransactionSummary struct {
+	Account          int          `json:"account"`
+	From             string       `json:"from,omitempty"`
+	To               string       `json:"to"`
+	TransactionCount int          `json:"transaction_count"`
+	DebitCount       int          `json:"debit_count"`
+	CreditCount      int          `json:"credit_count"`
+	TotalDebits      money.Amount `json:"total_debits" swaggertype:"number"`
+	TotalCredits     money.Amount `json:"total_credits" swaggertype:"number"`
+	Net              money.Amount `json:"net" swaggertype:"number"`
+	OpeningBalance   money.Amount `json:"opening_balance" swaggertype:"number"`
+	ClosingBalance   money.Amount `json:"closing_balance" swaggertype:"number"`
+}
+
+const (
+	// BulkAtomic posts the transactions of a bulk request all or none
+	BulkAtomic = "atomic"
+	// BulkBestEffort posts the valid transactions of a bulk request and skips the others
+	BulkBestEffort = "best_effort"
+)
+
+const (
+	BulkItemCreated   = "created"
+	BulkItemFailed    = "failed"
+	BulkItemNotPosted = "not_posted"
+)
+
+// BulkCreateTransactions creates many transactions in one request, in order
+type BulkCreateTransactions struct {
+	Mode         string              `json:"mode" binding:"omitempty,oneof=atomic best_effort" enums:"atomic,best_effort" default:"atomic"`
+	Transactions []CreateTransaction `json:"transactions" binding:"required,min=1,max=1000"`
+}
+
+// BulkTransactionResult is the outcome of a transaction of a bulk request:
+// the ID of the created transaction, or why it failed. In atomic mode the
+// valid transactions of a failed request are not posted.
+type BulkTransactionResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status" enums:"created,failed,not_posted"`
+	ID     *int   `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkTransactions is the outcome of a bulk request, with a result per
+// transaction in the order of the request
+type BulkTransactions struct {
+	Mode    string                  `json:"mode"`
+	Created int                     `json:"created"`
+	Failed  int                     `json:"failed"`
+	Results []BulkTransactionResult `json:"results"`
+}
+
+// CategoryTotal totals the transactions of a category, counting the lines
+// of the split transactions in their own categories
+type CategoryTotal struct {
+	Category     *string      `json:"category"`
+	Count        int          `json:"count"`
+	TotalDebits  money.Amount `json:"total_debits" swaggertype:"number"`
+	TotalCredits money.Amount `json:"total_credits" swaggertype:"number"`
+	Net          money.Amount `json:"net" swaggertype:"number"`
 }
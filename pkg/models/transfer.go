@@ -0,0 +1,33 @@
+package models
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+)
+
+const (
+	TransferPosted   = "posted"
+	TransferReversed = "reversed"
+)
+
+// Transfer moves money between two accounts. Both legs are stored as
+// transactions that reference the transfer.
+type Transfer struct {
+	ID           int           `json:"id" gorm:"type:integer;primary_key;autoIncrement:true"`
+	From         int           `json:"from" gorm:"type:integer;column:from_account_id"`
+	To           int           `json:"to" gorm:"type:integer;column:to_account_id"`
+	Amount       money.Amount  `json:"amount" sql:"type:decimal(10,2);" swaggertype:"number"`
+	Date         time.Time     `json:"date"`
+	Status       string        `json:"status"`
+	ReversalOf   *int          `json:"reversal_of,omitempty" gorm:"type:integer;column:reversal_of_id"`
+	Transactions []Transaction `json:"transactions,omitempty" gorm:"foreignKey:TransferID"`
+	CreatedAt    time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type CreateTransfer struct {
+	From   int          `json:"from" binding:"required"`
+	To     int          `json:"to" binding:"required"`
+	Date   string       `json:"date" binding:"required"`
+	Amount money.Amount `json:"amount" binding:"required" sql:"type:decimal(10,2);" swaggertype:"number"`
+}
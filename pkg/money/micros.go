@@ -0,0 +1,151 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// microsPerCent is the number of micro-cents in a cent
+const microsPerCent = 1000000
+
+// Micros is a monetary amount expressed in millionths of a cent, for the
+// values that are accumulated with more precision than a cent before being
+// rounded to an Amount, such as accrued interest. It matches the
+// DECIMAL(20,8) columns of the database.
+type Micros int64
+
+// ParseMicros converts a decimal string such as "-12.34567891" into Micros.
+// Digits beyond the micro-cent are rounded half away from zero.
+func ParseMicros(s string) (Micros, error) {
+	micros, err := parseFixed(s, 8)
+	if err != nil {
+		return 0, err
+	}
+
+	return Micros(micros), nil
+}
+
+// MustParseMicros is like ParseMicros but panics if the string is not a
+// valid amount. It is intended for constants and tests.
+func MustParseMicros(s string) Micros {
+	micros, err := ParseMicros(s)
+	if err != nil {
+		panic(fmt.Sprintf("money: cannot parse %q", s))
+	}
+
+	return micros
+}
+
+// Micros returns the amount in micro-cents
+func (a Amount) Micros() Micros {
+	return Micros(int64(a) * microsPerCent)
+}
+
+// Round returns the amount rounded to the cent, half away from zero
+func (m Micros) Round() Amount {
+	return Amount(divRound(int64(m), microsPerCent))
+}
+
+// MulDiv returns m * numerator / denominator, rounded half away from zero
+// to the micro-cent. The product is computed exactly, it cannot overflow.
+// Dividing by zero returns zero.
+func (m Micros) MulDiv(numerator int64, denominator int64) Micros {
+	if denominator == 0 {
+		return 0
+	}
+
+	product := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(numerator))
+	divisor := big.NewInt(denominator)
+	if divisor.Sign() < 0 {
+		product.Neg(product)
+		divisor.Neg(divisor)
+	}
+
+	// Round half away from zero on the absolute value, then restore the sign
+	negative := product.Sign() < 0
+	product.Abs(product)
+	quotient, remainder := new(big.Int).QuoRem(product, divisor, new(big.Int))
+	if remainder.Lsh(remainder, 1).Cmp(divisor) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	if negative {
+		quotient.Neg(quotient)
+	}
+
+	return Micros(quotient.Int64())
+}
+
+// String formats the amount with eight decimals, e.g. "-12.30000001"
+func (m Micros) String() string {
+	sign := ""
+	micros := int64(m)
+	if micros < 0 {
+		sign = "-"
+		micros = -micros
+	}
+
+	return fmt.Sprintf("%s%d.%08d", sign, micros/(100*microsPerCent), micros%(100*microsPerCent))
+}
+
+// MarshalJSON encodes the amount as a JSON number with eight decimals
+func (m Micros) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or string without going through
+// a floating point representation.
+func (m *Micros) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if string(data) == "null" {
+		return nil
+	}
+
+	micros, err := ParseMicros(string(data))
+	if err != nil {
+		return err
+	}
+
+	*m = micros
+	return nil
+}
+
+// Scan implements the sql.Scanner interface
+func (m *Micros) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	case int64:
+		*m = Micros(v * 100 * microsPerCent)
+		return nil
+	case float64:
+		*m = Micros(math.Round(v * 100 * microsPerCent))
+		return nil
+	default:
+		return fmt.Errorf("money: cannot scan %T into Micros", value)
+	}
+}
+
+// Value implements the driver.Valuer interface
+func (m Micros) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// scanString parses the textual representation returned by the database
+// Private function, not exposed to the API
+func (m *Micros) scanString(s string) error {
+	micros, err := ParseMicros(s)
+	if err != nil {
+		return err
+	}
+
+	*m = micros
+	return nil
+}
@@ -0,0 +1,241 @@
+// Package money provides an exact representation of monetary amounts.
+//
+// Amounts are stored as an integer number of cents, matching the
+// DECIMAL(10,2) columns of the database. Whenever a value carries more
+// precision than a cent, either when it is parsed or divided, it is
+// rounded half away from zero: 0.005 becomes 0.01 and -0.005 becomes -0.01.
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Amount is a monetary amount expressed in cents
+type Amount int64
+
+// Zero is the zero amount
+const Zero Amount = 0
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// FromCents returns the amount for the given number of cents
+func FromCents(cents int64) Amount {
+	return Amount(cents)
+}
+
+// Parse converts a decimal string such as "-1234.56" into an Amount.
+// Digits beyond the cent are rounded half away from zero.
+func Parse(s string) (Amount, error) {
+	cents, err := parseFixed(s, 2)
+	if err != nil {
+		return Zero, err
+	}
+
+	return Amount(cents), nil
+}
+
+// MustParse is like Parse but panics if the string is not a valid amount.
+// It is intended for constants and tests.
+func MustParse(s string) Amount {
+	amount, err := Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("money: cannot parse %q", s))
+	}
+
+	return amount
+}
+
+// Cents returns the amount as an integer number of cents
+func (a Amount) Cents() int64 {
+	return int64(a)
+}
+
+// Abs returns the absolute value of the amount
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+
+	return a
+}
+
+// Div divides the amount by n, rounding half away from zero.
+// Dividing by zero returns Zero.
+func (a Amount) Div(n int64) Amount {
+	if n == 0 {
+		return Zero
+	}
+
+	return Amount(divRound(int64(a), n))
+}
+
+// String formats the amount with two decimals, e.g. "-12.30"
+func (a Amount) String() string {
+	sign := ""
+	cents := int64(a)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// MarshalJSON encodes the amount as a JSON number with two decimals
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number or string without going through
+// a floating point representation.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if string(data) == "null" {
+		return nil
+	}
+
+	amount, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+
+	*a = amount
+	return nil
+}
+
+// Scan implements the sql.Scanner interface
+func (a *Amount) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*a = Zero
+		return nil
+	case []byte:
+		return a.scanString(string(v))
+	case string:
+		return a.scanString(v)
+	case int64:
+		*a = Amount(v * 100)
+		return nil
+	case float64:
+		*a = Amount(math.Round(v * 100))
+		return nil
+	default:
+		return fmt.Errorf("money: cannot scan %T into Amount", value)
+	}
+}
+
+// Value implements the driver.Valuer interface
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// scanString parses the textual representation returned by the database
+// Private function, not exposed to the API
+func (a *Amount) scanString(s string) error {
+	amount, err := Parse(s)
+	if err != nil {
+		return err
+	}
+
+	*a = amount
+	return nil
+}
+
+// parseFixed converts a decimal string into an integer number of units of
+// 10^-digits, rounding the digits beyond them half away from zero
+// Private function, not exposed to the API
+func parseFixed(s string, digits int) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" && fraction == "" {
+		return 0, ErrInvalidAmount
+	}
+	if !isDigits(whole) || !isDigits(fraction) {
+		return 0, ErrInvalidAmount
+	}
+
+	scale := int64(1)
+	for i := 0; i < digits; i++ {
+		scale *= 10
+	}
+
+	var units int64
+	if whole != "" {
+		var err error
+		units, err = strconv.ParseInt(whole, 10, 64)
+		if err != nil || units > math.MaxInt64/scale-1 {
+			return 0, ErrInvalidAmount
+		}
+	}
+
+	// Keep the digits and use the next one to round
+	fraction += strings.Repeat("0", digits+1)
+	parts, err := strconv.ParseInt(fraction[:digits], 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	if fraction[digits] >= '5' {
+		parts++
+	}
+
+	value := units*scale + parts
+	if negative {
+		value = -value
+	}
+
+	return value, nil
+}
+
+// divRound divides numerator by denominator, rounding half away from zero
+// Private function, not exposed to the API
+func divRound(numerator int64, denominator int64) int64 {
+	if denominator < 0 {
+		numerator, denominator = -numerator, -denominator
+	}
+
+	// Integer division truncates towards zero, round the remainder away from it
+	quotient, remainder := numerator/denominator, numerator%denominator
+	if remainder < 0 {
+		remainder = -remainder
+	}
+	if remainder*2 >= denominator {
+		if numerator < 0 {
+			quotient--
+		} else {
+			quotient++
+		}
+	}
+
+	return quotient
+}
+
+// isDigits reports whether s only contains decimal digits
+// Private function, not exposed to the API
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
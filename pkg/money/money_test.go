@@ -0,0 +1,118 @@
+package money
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]Amount{
+		"0":         0,
+		"12":        1200,
+		"12.3":      1230,
+		"-12.34":    -1234,
+		"+0.5":      50,
+		".75":       75,
+		"1.005":     101,
+		"-1.005":    -101,
+		"1.0049":    100,
+		"350000.01": 35000001,
+	}
+
+	for input, expected := range cases {
+		amount, err := Parse(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, amount, input)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, input := range []string{"", "-", ".", "1,50", "abc", "1.2.3", "1e3"} {
+		_, err := Parse(input)
+		require.ErrorIs(t, err, ErrInvalidAmount, input)
+	}
+}
+
+func TestString(t *testing.T) {
+	require.Equal(t, "0.00", Zero.String())
+	require.Equal(t, "12.30", Amount(1230).String())
+	require.Equal(t, "-0.05", Amount(-5).String())
+}
+
+func TestDiv(t *testing.T) {
+	require.Equal(t, Amount(333), Amount(1000).Div(3))
+	require.Equal(t, Amount(667), Amount(2000).Div(3))
+	require.Equal(t, Amount(-667), Amount(-2000).Div(3))
+	require.Equal(t, Amount(3), Amount(5).Div(2))
+	require.Equal(t, Amount(-3), Amount(-5).Div(2))
+	require.Equal(t, Zero, Amount(100).Div(0))
+}
+
+func TestJSON(t *testing.T) {
+	var payload struct {
+		Amount Amount `json:"amount"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": 19.99}`), &payload))
+	require.Equal(t, Amount(1999), payload.Amount)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": "-0.10"}`), &payload))
+	require.Equal(t, Amount(-10), payload.Amount)
+
+	encoded, err := json.Marshal(payload)
+	require.NoError(t, err)
+	require.Equal(t, `{"amount":-0.10}`, string(encoded))
+}
+
+func TestScan(t *testing.T) {
+	var amount Amount
+
+	require.NoError(t, amount.Scan([]byte("123456.78")))
+	require.Equal(t, Amount(12345678), amount)
+
+	require.NoError(t, amount.Scan(int64(3)))
+	require.Equal(t, Amount(300), amount)
+
+	require.NoError(t, amount.Scan(nil))
+	require.Equal(t, Zero, amount)
+}
+
+func TestParseMicros(t *testing.T) {
+	cases := map[string]Micros{
+		"0":            0,
+		"12.3":         1230000000,
+		"-0.00000001":  -1,
+		"0.000000005":  1,
+		"-0.000000005": -1,
+		"0.0000000049": 0,
+	}
+
+	for input, expected := range cases {
+		micros, err := ParseMicros(input)
+		require.NoError(t, err, input)
+		require.Equal(t, expected, micros, input)
+	}
+}
+
+func TestMicros_Round(t *testing.T) {
+	require.Equal(t, Amount(1), MustParseMicros("0.005").Round())
+	require.Equal(t, Amount(-1), MustParseMicros("-0.005").Round())
+	require.Equal(t, Zero, MustParseMicros("0.00499999").Round())
+	require.Equal(t, MustParse("12.34").Micros(), MustParseMicros("12.34"))
+}
+
+func TestMicros_MulDiv(t *testing.T) {
+	require.Equal(t, MustParseMicros("0.1"), MustParse("1000").Micros().MulDiv(365, 365*10000))
+	require.Equal(t, Micros(1), Micros(1).MulDiv(1, 2))
+	require.Equal(t, Micros(-1), Micros(-1).MulDiv(1, 2))
+
+	// The product is larger than an int64
+	balance := MustParse("99999999.99").Micros()
+	require.Equal(t, MustParseMicros("99999999.99"), balance.MulDiv(1000000, 1000000))
+}
+
+func TestMicros_String(t *testing.T) {
+	require.Equal(t, "0.00000000", Micros(0).String())
+	require.Equal(t, "-12.30000001", MustParseMicros("-12.30000001").String())
+}
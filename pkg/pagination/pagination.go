@@ -0,0 +1,182 @@
+// Package pagination pages through the lists of the API with opaque
+// cursors: a page starts right after the last row of the previous one, so
+// pages do not shift when rows are inserted and deep pages stay fast.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidLimit  = fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+	ErrInvalidTotal  = errors.New("Invalid total format")
+	ErrInvalidCursor = errors.New("Invalid cursor")
+)
+
+// Order is the order of a list: by a column, then by a unique key column
+// that breaks the ties. Column is empty to sort by the key only.
+type Order struct {
+	Column string
+	Key    string
+	Desc   bool
+}
+
+// Cursor is the position of the last row of a page
+type Cursor struct {
+	Order string `json:"o"`
+	Value string `json:"v,omitempty"`
+	ID    int    `json:"id"`
+}
+
+// Params are the pagination query params of a list
+type Params struct {
+	Limit     int
+	Cursor    string
+	WithTotal bool
+}
+
+// Page is a page of a list, with the cursor of the next one when there is more
+type Page[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// Parse reads the limit, the cursor returned with the previous page, and
+// total, which adds the number of rows matching the filters to the page
+func Parse(query url.Values) (Params, error) {
+	params := Params{Limit: DefaultLimit, Cursor: query.Get("cursor")}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return params, ErrInvalidLimit
+		}
+		params.Limit = limit
+	}
+
+	if value := query.Get("total"); value != "" {
+		total, err := strconv.ParseBool(value)
+		if err != nil {
+			return params, ErrInvalidTotal
+		}
+		params.WithTotal = total
+	}
+
+	return params, nil
+}
+
+// Key returns the params in a canonical form, to tell cached pages apart
+func (p Params) Key() string {
+	return fmt.Sprintf("limit_%d_cursor_%s_total_%t", p.Limit, p.Cursor, p.WithTotal)
+}
+
+// String identifies the order, so a cursor is not reused with another one
+func (o Order) String() string {
+	direction := "asc"
+	if o.Desc {
+		direction = "desc"
+	}
+
+	return o.Column + "," + o.Key + "," + direction
+}
+
+// Fetch returns the page of the rows of query described by params, in the
+// given order. cursor returns the position of a row in that order.
+func Fetch[T any](query *gorm.DB, params Params, order Order, cursor func(T) Cursor) (Page[T], error) {
+	page := Page[T]{Data: []T{}}
+
+	if params.WithTotal {
+		var total int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			return page, err
+		}
+		page.Total = &total
+	}
+
+	rows := query.Session(&gorm.Session{})
+	if params.Cursor != "" {
+		after, err := decode(params.Cursor, order)
+		if err != nil {
+			return page, err
+		}
+		rows = after.apply(rows, order)
+	}
+
+	direction := ""
+	if order.Desc {
+		direction = " DESC"
+	}
+	if order.Column != "" {
+		rows = rows.Order(order.Column + direction)
+	}
+	rows = rows.Order(order.Key + direction)
+
+	// One more row tells whether there is a next page
+	if err := rows.Limit(params.Limit + 1).Find(&page.Data).Error; err != nil {
+		return page, err
+	}
+
+	if len(page.Data) > params.Limit {
+		page.Data = page.Data[:params.Limit]
+		page.HasMore = true
+
+		next := cursor(page.Data[len(page.Data)-1])
+		next.Order = order.String()
+		page.NextCursor = encode(next)
+	}
+
+	return page, nil
+}
+
+// apply restricts the query to the rows after the cursor
+// Private function, not exposed to the API
+func (c Cursor) apply(query *gorm.DB, order Order) *gorm.DB {
+	operator := ">"
+	if order.Desc {
+		operator = "<"
+	}
+
+	if order.Column == "" {
+		return query.Where(order.Key+" "+operator+" ?", c.ID)
+	}
+
+	return query.Where("("+order.Column+", "+order.Key+") "+operator+" (?, ?)", c.Value, c.ID)
+}
+
+// encode returns the opaque form of a cursor
+// Private function, not exposed to the API
+func encode(c Cursor) string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decode parses an opaque cursor made for the given order
+// Private function, not exposed to the API
+func decode(value string, order Order) (Cursor, error) {
+	var c Cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.Order != order.String() {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
+}
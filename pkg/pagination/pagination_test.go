@@ -0,0 +1,86 @@
+package pagination
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"net/url"
+	"testing"
+)
+
+type row struct {
+	ID   int
+	Name string
+}
+
+func TestParse(t *testing.T) {
+	params, err := Parse(url.Values{})
+	require.NoError(t, err)
+	require.Equal(t, Params{Limit: DefaultLimit}, params)
+
+	params, err = Parse(url.Values{"limit": {"25"}, "cursor": {"abc"}, "total": {"true"}})
+	require.NoError(t, err)
+	require.Equal(t, Params{Limit: 25, Cursor: "abc", WithTotal: true}, params)
+
+	_, err = Parse(url.Values{"limit": {"101"}})
+	require.ErrorIs(t, err, ErrInvalidLimit)
+
+	_, err = Parse(url.Values{"total": {"maybe"}})
+	require.ErrorIs(t, err, ErrInvalidTotal)
+}
+
+func TestFetch(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	order := Order{Column: "name", Key: "id", Desc: true}
+	after := encode(Cursor{Order: order.String(), Value: "m", ID: 7})
+
+	dbMock.ExpectQuery(`SELECT count\(\*\) FROM "rows"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+	dbMock.ExpectQuery(`SELECT \* FROM "rows" WHERE \(name, id\) < \(\$1, \$2\) ORDER BY name DESC,id DESC LIMIT 3`).
+		WithArgs("m", 7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(4, "k").AddRow(9, "f").AddRow(2, "b"))
+
+	// When
+	page, err := Fetch(gormDB.Model(&row{}), Params{Limit: 2, Cursor: after, WithTotal: true}, order, func(r row) Cursor {
+		return Cursor{Value: r.Name, ID: r.ID}
+	})
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, []row{{ID: 4, Name: "k"}, {ID: 9, Name: "f"}}, page.Data)
+	require.True(t, page.HasMore)
+	require.Equal(t, int64(12), *page.Total)
+
+	next, err := decode(page.NextCursor, order)
+	require.NoError(t, err)
+	require.Equal(t, Cursor{Order: order.String(), Value: "f", ID: 9}, next)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestFetch_InvalidCursor(t *testing.T) {
+	_, gormDB := setupTestDatabase(t)
+	cursor := encode(Cursor{Order: Order{Key: "id"}.String(), ID: 7})
+
+	_, err := Fetch(gormDB.Model(&row{}), Params{Limit: 10, Cursor: cursor}, Order{Key: "id", Desc: true}, func(r row) Cursor {
+		return Cursor{ID: r.ID}
+	})
+	require.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = Fetch(gormDB.Model(&row{}), Params{Limit: 10, Cursor: "not a cursor"}, Order{Key: "id"}, func(r row) Cursor {
+		return Cursor{ID: r.ID}
+	})
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
@@ -0,0 +1,68 @@
+// Package posting creates transactions the way the API does: categorized
+// by the rules, posted with their splits and journal entry, and charged the
+// fees of their account.
+package posting
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/classifier"
+	"github.com/wjoseperez20/zenwallet/pkg/fees"
+	"github.com/wjoseperez20/zenwallet/pkg/ledger"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/splits"
+
+	"gorm.io/gorm"
+)
+
+// Recorder is called in the database transaction posting a transaction,
+// for each fee it incurred and then for the transaction itself, so what it
+// stores is kept only with the posting
+type Recorder func(tx *gorm.DB, transaction models.Transaction) error
+
+// Post creates the transaction with the enabled rules, as PostWith does.
+// It must be called inside a database transaction.
+func Post(tx *gorm.DB, transaction *models.Transaction, record Recorder) error {
+	engine, err := classifier.Load(tx)
+	if err != nil {
+		return err
+	}
+
+	return PostWith(tx, engine, transaction, record)
+}
+
+// PostWith categorizes the transaction with the engine, posts it with its
+// splits and journal entry, and charges the fees of its account, linked to
+// it and listed in its fees. record, when not nil, is called for each of
+// them. The splits must be validated already. It must be called inside a
+// database transaction.
+func PostWith(tx *gorm.DB, engine *classifier.Engine, transaction *models.Transaction, record Recorder) error {
+	engine.Apply(transaction)
+
+	if err := splits.EnsureCategories(tx, transaction.Splits); err != nil {
+		return err
+	}
+
+	if err := ledger.Post(tx, transaction); err != nil {
+		return err
+	}
+	if err := splits.CreateAll(tx, []*models.Transaction{transaction}); err != nil {
+		return err
+	}
+
+	charged, err := fees.Charge(tx, transaction)
+	if err != nil {
+		return err
+	}
+	transaction.Fees = charged
+
+	if record == nil {
+		return nil
+	}
+
+	for _, fee := range charged {
+		if err := record(tx, fee); err != nil {
+			return err
+		}
+	}
+
+	return record(tx, *transaction)
+}
@@ -0,0 +1,61 @@
+package posting
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/ledger"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestPost_CategorizesBeforePosting(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	dbMock.ExpectQuery(`SELECT \* FROM "rules" WHERE enabled = (.+) ORDER BY priority, id`).
+		WithArgs(true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "priority", "enabled", "description_contains", "category"}).
+			AddRow(4, 1, true, "rent", "housing"))
+	dbMock.ExpectQuery(`SELECT MAX\(date\) FROM "periods"`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) ORDER BY "accounts"."account" LIMIT 1 FOR UPDATE`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account", "balance"}).AddRow(1, 10001, "900.00"))
+	dbMock.ExpectQuery(`SELECT count\(\*\) FROM "categories" WHERE code = (.+)`).
+		WithArgs("housing").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	recorded := 0
+	transaction := models.Transaction{Account: 10001, Date: time.Now(), Amount: money.MustParse("-750"), Description: "Monthly rent"}
+
+	// When
+	err := Post(gormDB, &transaction, func(tx *gorm.DB, created models.Transaction) error {
+		recorded++
+		return nil
+	})
+
+	// Then
+	require.ErrorIs(t, err, ledger.ErrCategoryNotFound)
+	require.Equal(t, "housing", *transaction.Category)
+	require.Equal(t, 4, *transaction.RuleID)
+	require.Zero(t, recorded)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	// Create a mock database for testing
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// Replace the actual database with the mock database for testing
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
@@ -0,0 +1,178 @@
+// Package reconciliation detects drift between the stored balance of the
+// accounts, their ledger postings and their transactions.
+package reconciliation
+
+import (
+	"github.com/wjoseperez20/zenwallet/pkg/ledger"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// accountTotal is the sum of the amounts of one account
+type accountTotal struct {
+	Account int
+	Total   money.Amount
+}
+
+// transactionTotal is the sum of the postings recorded for one transaction
+type transactionTotal struct {
+	TransactionID int
+	Total         money.Amount
+}
+
+// Recorder is called in the database transaction adjusting an account, with
+// the discrepancy fixed, so what it stores is kept only with the adjustment
+type Recorder func(tx *gorm.DB, discrepancy models.Discrepancy) error
+
+// Run compares the stored balance of every account with the sum of its
+// transactions and with its ledger postings. When fix is set, every
+// divergent account gets an adjusting journal entry so that its ledger and
+// stored balance match its transactions again, and record, when not nil,
+// is called for each of them.
+func Run(db *gorm.DB, fix bool, record Recorder) (*models.ReconciliationReport, error) {
+	var accounts []models.Account
+
+	if err := db.Select("account", "balance").Order("account").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+
+	expected, err := totalsByAccount(db, "transactions")
+	if err != nil {
+		return nil, err
+	}
+
+	posted, err := totalsByAccount(db, "postings")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ReconciliationReport{
+		GeneratedAt:     time.Now().UTC(),
+		AccountsChecked: len(accounts),
+		Fixed:           fix,
+		Discrepancies:   []models.Discrepancy{},
+	}
+
+	for _, account := range accounts {
+		discrepancy := models.Discrepancy{
+			Account:  account.Account,
+			Expected: expected[account.Account],
+			Stored:   account.Balance,
+			Ledger:   posted[account.Account],
+		}
+		if discrepancy.Expected == discrepancy.Stored && discrepancy.Ledger == discrepancy.Expected {
+			continue
+		}
+
+		discrepancy.FirstDivergentTransaction, err = firstDivergentTransaction(db, account.Account)
+		if err != nil {
+			return nil, err
+		}
+
+		if fix {
+			discrepancy.Fixed = true
+			if err := adjust(db, discrepancy, record); err != nil {
+				return nil, err
+			}
+		}
+
+		report.Discrepancies = append(report.Discrepancies, discrepancy)
+	}
+
+	return report, nil
+}
+
+// adjust aligns the ledger of the account of the discrepancy with its
+// transactions. The account is locked and its transactions summed again so
+// postings made since the report started are taken into account.
+// Private function, not exposed to the API
+func adjust(db *gorm.DB, discrepancy models.Discrepancy, record Recorder) error {
+	account := discrepancy.Account
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var locked models.Account
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("account = ?", account).First(&locked).Error; err != nil {
+			return err
+		}
+
+		var expected money.Amount
+		if err := tx.Model(&models.Transaction{}).
+			Select("COALESCE(SUM(amount), 0)").
+			Where("account_id = ?", account).
+			Scan(&expected).Error; err != nil {
+			return err
+		}
+
+		if err := ledger.Adjust(tx, account, expected, "reconciliation adjustment"); err != nil {
+			return err
+		}
+
+		if record == nil {
+			return nil
+		}
+
+		return record(tx, discrepancy)
+	})
+}
+
+// totalsByAccount sums the amount column of the given table per account
+// Private function, not exposed to the API
+func totalsByAccount(db *gorm.DB, table string) (map[int]money.Amount, error) {
+	var rows []accountTotal
+
+	err := db.Table(table).
+		Select("account_id AS account, SUM(amount) AS total").
+		Group("account_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[int]money.Amount, len(rows))
+	for _, row := range rows {
+		totals[row.Account] = row.Total
+	}
+
+	return totals, nil
+}
+
+// firstDivergentTransaction returns the oldest transaction of the account
+// whose amount differs from what the ledger posted for it, or nil when every
+// transaction was posted correctly.
+// Private function, not exposed to the API
+func firstDivergentTransaction(db *gorm.DB, account int) (*int, error) {
+	var transactions []models.Transaction
+	var rows []transactionTotal
+
+	if err := db.Where("account_id = ?", account).Order("date, id").Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	err := db.Table("postings").
+		Select("journal_entries.transaction_id AS transaction_id, SUM(postings.amount) AS total").
+		Joins("JOIN journal_entries ON journal_entries.id = postings.journal_entry_id").
+		Where("postings.account_id = ?", account).
+		Group("journal_entries.transaction_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	posted := make(map[int]money.Amount, len(rows))
+	for _, row := range rows {
+		posted[row.TransactionID] = row.Total
+	}
+
+	for _, transaction := range transactions {
+		if posted[transaction.ID] != transaction.Amount {
+			id := transaction.ID
+			return &id, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,143 @@
+package reconciliation
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestRun_NoDiscrepancies(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+
+	expectAccounts(dbMock, sqlmock.NewRows([]string{"account", "balance"}).AddRow(10001, "25.00"))
+	expectTotals(dbMock, "transactions", sqlmock.NewRows([]string{"account", "total"}).AddRow(10001, "25.00"))
+	expectTotals(dbMock, "postings", sqlmock.NewRows([]string{"account", "total"}).AddRow(10001, "25.00"))
+
+	// When
+	report, err := Run(gormDB, false, nil)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, 1, report.AccountsChecked)
+	require.Empty(t, report.Discrepancies)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRun_ReportsFirstDivergentTransaction(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	date := time.Date(2023, 11, 25, 0, 0, 0, 0, time.UTC)
+
+	expectAccounts(dbMock, sqlmock.NewRows([]string{"account", "balance"}).AddRow(10001, "40.00"))
+	expectTotals(dbMock, "transactions", sqlmock.NewRows([]string{"account", "total"}).AddRow(10001, "25.00"))
+	expectTotals(dbMock, "postings", sqlmock.NewRows([]string{"account", "total"}).AddRow(10001, "40.00"))
+	dbMock.ExpectQuery(`SELECT \* FROM "transactions" WHERE account_id = (.+) ORDER BY date, id`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "date", "amount"}).
+			AddRow(1, 10001, date, "10.00").
+			AddRow(2, 10001, date, "15.00"))
+	dbMock.ExpectQuery(`SELECT journal_entries.transaction_id AS transaction_id, SUM\(postings.amount\) AS total FROM "postings" JOIN journal_entries`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"transaction_id", "total"}).AddRow(1, "10.00").AddRow(2, "30.00"))
+
+	// When
+	report, err := Run(gormDB, false, nil)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, report.Discrepancies, 1)
+
+	discrepancy := report.Discrepancies[0]
+	require.Equal(t, money.MustParse("25.00"), discrepancy.Expected)
+	require.Equal(t, money.MustParse("40.00"), discrepancy.Stored)
+	require.NotNil(t, discrepancy.FirstDivergentTransaction)
+	require.Equal(t, 2, *discrepancy.FirstDivergentTransaction)
+	require.False(t, discrepancy.Fixed)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRun_FixRecordsEachAdjustment(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	date := time.Date(2023, 11, 25, 0, 0, 0, 0, time.UTC)
+
+	expectAccounts(dbMock, sqlmock.NewRows([]string{"account", "balance"}).AddRow(10001, "40.00"))
+	expectTotals(dbMock, "transactions", sqlmock.NewRows([]string{"account", "total"}).AddRow(10001, "25.00"))
+	expectTotals(dbMock, "postings", sqlmock.NewRows([]string{"account", "total"}).AddRow(10001, "25.00"))
+	dbMock.ExpectQuery(`SELECT \* FROM "transactions" WHERE account_id = (.+) ORDER BY date, id`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "date", "amount"}).AddRow(1, 10001, date, "25.00"))
+	dbMock.ExpectQuery(`SELECT journal_entries.transaction_id AS transaction_id, SUM\(postings.amount\) AS total FROM "postings" JOIN journal_entries`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"transaction_id", "total"}).AddRow(1, "25.00"))
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) FOR UPDATE`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account", "balance"}).AddRow(10001, "40.00"))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "transactions" WHERE account_id = (.+)`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("25.00"))
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) LIMIT 1`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account", "balance"}).AddRow(10001, "40.00"))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "postings" WHERE account_id = (.+)`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("25.00"))
+	dbMock.ExpectQuery(`SELECT "account","version" FROM "accounts" WHERE account = (.+)`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"account", "version"}).AddRow(10001, 1))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "postings" WHERE account_id = (.+)`).
+		WithArgs(10001).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("25.00"))
+	dbMock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "holds"`).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("0.00"))
+	dbMock.ExpectExec(`UPDATE "accounts" SET`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbMock.ExpectCommit()
+
+	// When
+	var recorded []models.Discrepancy
+	report, err := Run(gormDB, true, func(tx *gorm.DB, discrepancy models.Discrepancy) error {
+		recorded = append(recorded, discrepancy)
+		return nil
+	})
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, recorded, 1)
+	require.Equal(t, 10001, recorded[0].Account)
+	require.True(t, recorded[0].Fixed)
+	require.Equal(t, recorded, report.Discrepancies)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// expectAccounts mocks the query listing the stored balances
+func expectAccounts(dbMock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+	dbMock.ExpectQuery(`SELECT "account","balance" FROM "accounts" ORDER BY account`).WillReturnRows(rows)
+}
+
+// expectTotals mocks the query summing the amounts of a table per account
+func expectTotals(dbMock sqlmock.Sqlmock, table string, rows *sqlmock.Rows) {
+	dbMock.ExpectQuery(`SELECT account_id AS account, SUM\(amount\) AS total FROM "` + table + `" GROUP BY "account_id"`).
+		WillReturnRows(rows)
+}
+
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	// Create a mock database for testing
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// Replace the actual database with the mock database for testing
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
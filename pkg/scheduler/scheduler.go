@@ -0,0 +1,205 @@
+// Package scheduler materializes the occurrences of the standing orders
+// as transactions.
+package scheduler
+
+import (
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/posting"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxRunAttempts bounds the attempts to post an occurrence before it is skipped
+const maxRunAttempts = 3
+
+var ErrInvalidFrequency = errors.New("frequency must be daily, weekly or monthly")
+
+// FirstOccurrence returns the first date on or after the start date of
+// the schedule on which it runs
+func FirstOccurrence(schedule models.Schedule) (time.Time, error) {
+	start := schedule.StartDate
+
+	switch schedule.Frequency {
+	case models.ScheduleDaily, models.ScheduleWeekly:
+		return start, nil
+	case models.ScheduleMonthly:
+		first := dayOfMonth(start.Year(), start.Month(), schedule.DayOfMonth, start.Location())
+		if first.Before(start) {
+			first = dayOfMonth(start.Year(), start.Month()+1, schedule.DayOfMonth, start.Location())
+		}
+		return first, nil
+	default:
+		return time.Time{}, ErrInvalidFrequency
+	}
+}
+
+// NextOccurrence returns the occurrence of the schedule following the given one
+func NextOccurrence(schedule models.Schedule, occurrence time.Time) time.Time {
+	switch schedule.Frequency {
+	case models.ScheduleWeekly:
+		return occurrence.AddDate(0, 0, 7)
+	case models.ScheduleMonthly:
+		return dayOfMonth(occurrence.Year(), occurrence.Month()+1, schedule.DayOfMonth, occurrence.Location())
+	default:
+		return occurrence.AddDate(0, 0, 1)
+	}
+}
+
+// Finished reports whether the schedule has no occurrence left
+func Finished(schedule models.Schedule) bool {
+	if schedule.Count != nil && schedule.Occurrences >= *schedule.Count {
+		return true
+	}
+
+	return schedule.EndDate != nil && schedule.NextRun.After(*schedule.EndDate)
+}
+
+// Resume reactivates a paused schedule. The occurrences missed while it
+// was paused are not posted, the next run is moved to today or later.
+func Resume(schedule *models.Schedule, today time.Time) {
+	for schedule.NextRun.Before(today) {
+		schedule.NextRun = NextOccurrence(*schedule, schedule.NextRun)
+	}
+
+	schedule.Status = models.ScheduleActive
+	if Finished(*schedule) {
+		schedule.Status = models.ScheduleCompleted
+	}
+}
+
+// RunDue posts every occurrence due by today of the active schedules and
+// returns how many transactions were posted. A failed occurrence is retried
+// on the next call, and skipped once it failed maxRunAttempts times.
+func RunDue(db *gorm.DB, today time.Time) (int, error) {
+	var ids []int
+
+	err := db.Model(&models.Schedule{}).
+		Where("status = ? AND next_run <= ?", models.ScheduleActive, today).
+		Order("next_run, id").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for _, id := range ids {
+		// Each schedule runs in its own transaction so a failure does not stop the others
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var schedules []models.Schedule
+
+			// A schedule locked by another process or changed since is left alone
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("id = ? AND status = ? AND next_run <= ?", id, models.ScheduleActive, today).
+				Limit(1).
+				Find(&schedules).Error
+			if err != nil || len(schedules) == 0 {
+				return err
+			}
+			schedule := &schedules[0]
+
+			for schedule.Status == models.ScheduleActive && !schedule.NextRun.After(today) {
+				run, err := runOccurrence(tx, schedule)
+				if err != nil {
+					return err
+				}
+				if run.Status == models.RunPosted {
+					posted++
+				}
+				if run.Status == models.RunFailed {
+					break
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			log.Printf("Schedule %d: %v", id, err)
+		}
+	}
+
+	return posted, nil
+}
+
+// runOccurrence posts the next occurrence of the schedule and records the
+// run. The schedule only moves to its following occurrence when the run is
+// posted or skipped.
+// Private function, not exposed to the API
+func runOccurrence(tx *gorm.DB, schedule *models.Schedule) (*models.ScheduleRun, error) {
+	var runs []models.ScheduleRun
+
+	if err := tx.Where("schedule_id = ? AND date = ?", schedule.ID, schedule.NextRun).Limit(1).Find(&runs).Error; err != nil {
+		return nil, err
+	}
+
+	run := models.ScheduleRun{ScheduleID: schedule.ID, Date: schedule.NextRun}
+	if len(runs) > 0 {
+		run = runs[0]
+	}
+	run.Attempts++
+
+	transaction := models.Transaction{
+		Account:     schedule.Account,
+		Date:        schedule.NextRun,
+		Amount:      schedule.Amount,
+		Currency:    schedule.Currency,
+		Description: schedule.Description,
+	}
+
+	// The posting runs in a savepoint so a rejected transaction leaves the run to record.
+	// It is categorized and charged its fees as a transaction created through the API.
+	postErr := tx.Transaction(func(inner *gorm.DB) error {
+		return posting.Post(inner, &transaction, nil)
+	})
+
+	switch {
+	case postErr == nil:
+		run.Status = models.RunPosted
+		run.TransactionID = &transaction.ID
+		run.Error = ""
+	case run.Attempts < maxRunAttempts:
+		run.Status = models.RunFailed
+		run.Error = postErr.Error()
+	default:
+		run.Status = models.RunSkipped
+		run.Error = postErr.Error()
+	}
+
+	if err := tx.Save(&run).Error; err != nil {
+		return nil, err
+	}
+
+	if run.Status == models.RunFailed {
+		return &run, nil
+	}
+
+	schedule.Occurrences++
+	schedule.NextRun = NextOccurrence(*schedule, schedule.NextRun)
+	if Finished(*schedule) {
+		schedule.Status = models.ScheduleCompleted
+	}
+
+	err := tx.Model(schedule).Updates(map[string]interface{}{
+		"occurrences": schedule.Occurrences,
+		"next_run":    schedule.NextRun,
+		"status":      schedule.Status,
+	}).Error
+
+	return &run, err
+}
+
+// dayOfMonth returns the given day of the month, or the last day of the
+// month when it is shorter
+// Private function, not exposed to the API
+func dayOfMonth(year int, month time.Month, day int, location *time.Location) time.Time {
+	// Day 0 of the following month is the last day of this one
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, location).Day()
+	if day > last {
+		day = last
+	}
+
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
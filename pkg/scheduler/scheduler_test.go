@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestFirstOccurrence_Monthly(t *testing.T) {
+	// Given
+	schedule := models.Schedule{Frequency: models.ScheduleMonthly, DayOfMonth: 15, StartDate: date(2023, 11, 20)}
+
+	// When
+	first, err := FirstOccurrence(schedule)
+
+	// Then
+	require.NoError(t, err)
+	require.Equal(t, date(2023, 12, 15), first)
+}
+
+func TestFirstOccurrence_InvalidFrequency(t *testing.T) {
+	// When
+	_, err := FirstOccurrence(models.Schedule{Frequency: "yearly", StartDate: date(2023, 11, 20)})
+
+	// Then
+	require.ErrorIs(t, err, ErrInvalidFrequency)
+}
+
+func TestNextOccurrence_MonthlyEndOfMonth(t *testing.T) {
+	// Given
+	schedule := models.Schedule{Frequency: models.ScheduleMonthly, DayOfMonth: 31}
+
+	// When
+	february := NextOccurrence(schedule, date(2024, 1, 31))
+	march := NextOccurrence(schedule, february)
+
+	// Then
+	require.Equal(t, date(2024, 2, 29), february)
+	require.Equal(t, date(2024, 3, 31), march)
+	require.Equal(t, date(2025, 1, 31), NextOccurrence(schedule, date(2024, 12, 31)))
+}
+
+func TestNextOccurrence_DailyAndWeekly(t *testing.T) {
+	require.Equal(t, date(2023, 12, 1), NextOccurrence(models.Schedule{Frequency: models.ScheduleDaily}, date(2023, 11, 30)))
+	require.Equal(t, date(2023, 12, 7), NextOccurrence(models.Schedule{Frequency: models.ScheduleWeekly}, date(2023, 11, 30)))
+}
+
+func TestFinished(t *testing.T) {
+	count := 3
+	endDate := date(2023, 12, 31)
+
+	require.False(t, Finished(models.Schedule{Count: &count, Occurrences: 2}))
+	require.True(t, Finished(models.Schedule{Count: &count, Occurrences: 3}))
+	require.False(t, Finished(models.Schedule{EndDate: &endDate, NextRun: endDate}))
+	require.True(t, Finished(models.Schedule{EndDate: &endDate, NextRun: date(2024, 1, 1)}))
+}
+
+func TestResume_SkipsMissedOccurrences(t *testing.T) {
+	// Given
+	schedule := models.Schedule{Frequency: models.ScheduleWeekly, Status: models.SchedulePaused, NextRun: date(2023, 11, 1)}
+
+	// When
+	Resume(&schedule, date(2023, 11, 20))
+
+	// Then
+	require.Equal(t, models.ScheduleActive, schedule.Status)
+	require.Equal(t, date(2023, 11, 22), schedule.NextRun)
+	require.Zero(t, schedule.Occurrences)
+}
+
+func TestRunDue_FailedOccurrenceIsRetried(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	today := date(2023, 11, 25)
+
+	dbMock.ExpectQuery(`SELECT "id" FROM "schedules" WHERE status = (.+) AND next_run <= (.+) ORDER BY next_run, id`).
+		WithArgs(models.ScheduleActive, today).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery(`SELECT \* FROM "schedules" WHERE id = (.+) AND status = (.+) AND next_run <= (.+) LIMIT 1 FOR UPDATE SKIP LOCKED`).
+		WithArgs(1, models.ScheduleActive, today).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "amount", "frequency", "next_run", "status"}).
+			AddRow(1, 999, "-25.00", models.ScheduleDaily, today, models.ScheduleActive))
+	dbMock.ExpectQuery(`SELECT \* FROM "schedule_runs" WHERE schedule_id = (.+) AND date = (.+) LIMIT 1`).
+		WithArgs(1, today).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	dbMock.ExpectExec(`SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectQuery(`SELECT \* FROM "rules" WHERE enabled = (.+) ORDER BY priority, id`).
+		WithArgs(true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "priority", "enabled", "description_contains", "category"}))
+	dbMock.ExpectQuery(`SELECT MAX\(date\) FROM "periods"`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(nil))
+	dbMock.ExpectQuery(`SELECT \* FROM "accounts" WHERE account = (.+) FOR UPDATE`).
+		WithArgs(999).
+		WillReturnError(gorm.ErrRecordNotFound)
+	dbMock.ExpectExec(`ROLLBACK TO SAVEPOINT`).WillReturnResult(sqlmock.NewResult(0, 0))
+	dbMock.ExpectQuery(`INSERT INTO "schedule_runs"`).
+		WithArgs(1, today, models.RunFailed, 1, nil, "account not found", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	dbMock.ExpectCommit()
+
+	// When
+	posted, err := RunDue(gormDB, today)
+
+	// Then
+	require.NoError(t, err)
+	require.Zero(t, posted)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	// Create a mock database for testing
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	// Replace the actual database with the mock database for testing
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,249 @@
+// Package splits splits transactions across categories: the lines of a
+// split transaction replace its category in the category reports, the
+// budgets and the statements.
+package splits
+
+import (
+	"errors"
+	"github.com/wjoseperez20/zenwallet/pkg/ledger"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// batchSize bounds the splits inserted per statement
+const batchSize = 500
+
+var (
+	ErrSumMismatch  = errors.New("splits must sum to the amount of the transaction")
+	ErrInvalidSplit = errors.New("split amounts must not be zero and must have the sign of the transaction")
+)
+
+// FromInput returns the splits described by the input of a request
+func FromInput(input []models.CreateTransactionSplit) []models.TransactionSplit {
+	if input == nil {
+		return nil
+	}
+
+	lines := make([]models.TransactionSplit, 0, len(input))
+	for _, line := range input {
+		lines = append(lines, models.TransactionSplit{
+			Amount:   line.Amount,
+			Category: strings.ToLower(strings.TrimSpace(line.Category)),
+			Note:     line.Note,
+		})
+	}
+
+	return lines
+}
+
+// Validate checks that the lines split amount: each one has its sign and
+// together they sum to it. No line leaves the transaction unsplit, and none
+// is in the fees category, reserved for the fees.
+func Validate(amount money.Amount, lines []models.TransactionSplit) error {
+	var sum money.Amount
+
+	for _, line := range lines {
+		if line.Category == models.FeeCategory {
+			return ledger.ErrReservedCategory
+		}
+		if line.Amount == 0 || (line.Amount < 0) != (amount < 0) {
+			return ErrInvalidSplit
+		}
+		sum += line.Amount
+	}
+
+	if len(lines) > 0 && sum != amount {
+		return ErrSumMismatch
+	}
+
+	return nil
+}
+
+// KnownCategories returns which of the category codes exist
+func KnownCategories(tx *gorm.DB, codes []string) (map[string]bool, error) {
+	var existing []string
+
+	known := make(map[string]bool)
+	if len(codes) == 0 {
+		return known, nil
+	}
+
+	if err := tx.Model(&models.Category{}).Where("code IN ?", codes).Pluck("code", &existing).Error; err != nil {
+		return nil, err
+	}
+	for _, code := range existing {
+		known[code] = true
+	}
+
+	return known, nil
+}
+
+// EnsureCategories returns ledger.ErrCategoryNotFound when the category of
+// a line does not exist
+func EnsureCategories(tx *gorm.DB, lines []models.TransactionSplit) error {
+	codes := make([]string, 0, len(lines))
+	for _, line := range lines {
+		codes = append(codes, line.Category)
+	}
+
+	known, err := KnownCategories(tx, codes)
+	if err != nil {
+		return err
+	}
+	for _, code := range codes {
+		if !known[code] {
+			return ledger.ErrCategoryNotFound
+		}
+	}
+
+	return nil
+}
+
+// Replace validates the lines and stores them as the splits of the
+// transaction, in place of its previous ones. No line removes the splits.
+// It must be called inside a database transaction.
+func Replace(tx *gorm.DB, transaction *models.Transaction, lines []models.TransactionSplit) error {
+	if err := Validate(transaction.Amount, lines); err != nil {
+		return err
+	}
+	if err := EnsureCategories(tx, lines); err != nil {
+		return err
+	}
+
+	if err := tx.Where("transaction_id = ?", transaction.ID).Delete(&models.TransactionSplit{}).Error; err != nil {
+		return err
+	}
+	transaction.Splits = nil
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	for i := range lines {
+		lines[i].ID = 0
+		lines[i].TransactionID = transaction.ID
+	}
+	if err := tx.CreateInBatches(&lines, batchSize).Error; err != nil {
+		return err
+	}
+	transaction.Splits = lines
+
+	return nil
+}
+
+// CreateAll stores the splits set on transactions that were just posted.
+// The splits must be validated already.
+func CreateAll(tx *gorm.DB, transactions []*models.Transaction) error {
+	var lines []models.TransactionSplit
+
+	for _, transaction := range transactions {
+		for _, line := range transaction.Splits {
+			line.TransactionID = transaction.ID
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	if err := tx.CreateInBatches(&lines, batchSize).Error; err != nil {
+		return err
+	}
+
+	// Hand the stored splits, with their IDs, back to their transactions
+	next := 0
+	for _, transaction := range transactions {
+		count := len(transaction.Splits)
+		transaction.Splits = lines[next : next+count]
+		next += count
+	}
+
+	return nil
+}
+
+// Load sets the splits of the transactions, in a single query
+func Load(db *gorm.DB, transactions []models.Transaction) error {
+	var lines []models.TransactionSplit
+
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(transactions))
+	for _, transaction := range transactions {
+		ids = append(ids, transaction.ID)
+	}
+
+	if err := db.Where("transaction_id IN ?", ids).Order("id").Find(&lines).Error; err != nil {
+		return err
+	}
+
+	byTransaction := make(map[int][]models.TransactionSplit)
+	for _, line := range lines {
+		byTransaction[line.TransactionID] = append(byTransaction[line.TransactionID], line)
+	}
+	for i := range transactions {
+		transactions[i].Splits = byTransaction[transactions[i].ID]
+	}
+
+	return nil
+}
+
+// LinesOf returns the category lines of a transaction: its splits, or the
+// whole transaction in its category when it is not split. The splits must
+// be loaded.
+func LinesOf(transaction models.Transaction) []models.TransactionSplit {
+	if len(transaction.Splits) > 0 {
+		return transaction.Splits
+	}
+
+	line := models.TransactionSplit{TransactionID: transaction.ID, Amount: transaction.Amount}
+	if transaction.Category != nil {
+		line.Category = *transaction.Category
+	}
+
+	return []models.TransactionSplit{line}
+}
+
+// Lines returns a query of the category lines of every transaction, with
+// the columns of the transactions: a split transaction has a row per split,
+// with the amount and the category of the split, the other ones a single
+// row. It is meant to be queried as the transactions table.
+func Lines(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Transaction{}).
+		Select(`transactions.id, transactions.account_id, transactions.date, transactions.currency, transactions.description, transactions.reversed_by_id,
+			COALESCE(transaction_splits.amount, transactions.amount) AS amount,
+			COALESCE(transaction_splits.category, transactions.category) AS category`).
+		Joins("LEFT JOIN transaction_splits ON transaction_splits.transaction_id = transactions.id")
+}
+
+// Report totals the debits and the credits of each category between from
+// and to, both optional, on the account when set. The lines of split
+// transactions count in their own categories, the uncategorized
+// transactions under a nil category.
+func Report(db *gorm.DB, account *int, from *time.Time, to *time.Time) ([]models.CategoryTotal, error) {
+	totals := []models.CategoryTotal{}
+
+	query := db.Table("(?) AS transactions", Lines(db)).
+		Select(`category,
+			COUNT(*) AS count,
+			COALESCE(SUM(CASE WHEN amount < 0 THEN -amount ELSE 0 END), 0) AS total_debits,
+			COALESCE(SUM(CASE WHEN amount >= 0 THEN amount ELSE 0 END), 0) AS total_credits,
+			COALESCE(SUM(amount), 0) AS net`)
+	if account != nil {
+		query = query.Where("account_id = ?", *account)
+	}
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date <= ?", *to)
+	}
+
+	err := query.Group("category").Order("category").Scan(&totals).Error
+	return totals, err
+}
@@ -0,0 +1,141 @@
+package splits
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"github.com/wjoseperez20/zenwallet/pkg/ledger"
+	"github.com/wjoseperez20/zenwallet/pkg/models"
+	"github.com/wjoseperez20/zenwallet/pkg/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	amount := money.MustParse("-80.00")
+
+	require.NoError(t, Validate(amount, nil))
+	require.NoError(t, Validate(amount, []models.TransactionSplit{
+		{Amount: money.MustParse("-55.20"), Category: "groceries"},
+		{Amount: money.MustParse("-24.80"), Category: "housing"},
+	}))
+	require.ErrorIs(t, Validate(amount, []models.TransactionSplit{
+		{Amount: money.MustParse("-55.20"), Category: "groceries"},
+		{Amount: money.MustParse("-24.79"), Category: "housing"},
+	}), ErrSumMismatch)
+	require.ErrorIs(t, Validate(amount, []models.TransactionSplit{
+		{Amount: money.MustParse("-90.00"), Category: "groceries"},
+		{Amount: money.MustParse("10.00"), Category: "housing"},
+	}), ErrInvalidSplit)
+	require.ErrorIs(t, Validate(amount, []models.TransactionSplit{
+		{Amount: money.Zero, Category: "groceries"},
+		{Amount: amount, Category: "housing"},
+	}), ErrInvalidSplit)
+	require.ErrorIs(t, Validate(amount, []models.TransactionSplit{
+		{Amount: money.MustParse("-55.20"), Category: "groceries"},
+		{Amount: money.MustParse("-24.80"), Category: models.FeeCategory},
+	}), ledger.ErrReservedCategory)
+}
+
+func TestLinesOf(t *testing.T) {
+	category := "groceries"
+	whole := models.Transaction{ID: 1, Amount: money.MustParse("-20.00"), Category: &category}
+	split := models.Transaction{ID: 2, Amount: money.MustParse("-30.00"), Category: &category, Splits: []models.TransactionSplit{
+		{TransactionID: 2, Amount: money.MustParse("-10.00"), Category: "groceries"},
+		{TransactionID: 2, Amount: money.MustParse("-20.00"), Category: "housing"},
+	}}
+
+	require.Equal(t, []models.TransactionSplit{{TransactionID: 1, Amount: money.MustParse("-20.00"), Category: "groceries"}}, LinesOf(whole))
+	require.Equal(t, split.Splits, LinesOf(split))
+	require.Equal(t, "", LinesOf(models.Transaction{ID: 3, Amount: 10})[0].Category)
+}
+
+func TestReplace_CategoryNotFound(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	dbMock.ExpectQuery(`SELECT "code" FROM "categories" WHERE code IN (.+)`).
+		WithArgs("groceries", "garden").
+		WillReturnRows(sqlmock.NewRows([]string{"code"}).AddRow("groceries"))
+	transaction := models.Transaction{ID: 7, Amount: money.MustParse("-80.00")}
+
+	// When
+	err := Replace(gormDB, &transaction, FromInput([]models.CreateTransactionSplit{
+		{Amount: money.MustParse("-60.00"), Category: "Groceries"},
+		{Amount: money.MustParse("-20.00"), Category: "garden"},
+	}))
+
+	// Then
+	require.ErrorIs(t, err, ledger.ErrCategoryNotFound)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReplace_RemovesSplits(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	dbMock.ExpectExec(`DELETE FROM "transaction_splits" WHERE transaction_id = (.+)`).
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	transaction := models.Transaction{ID: 7, Amount: money.MustParse("-80.00"), Splits: []models.TransactionSplit{{ID: 1}, {ID: 2}}}
+
+	// When
+	err := Replace(gormDB, &transaction, []models.TransactionSplit{})
+
+	// Then
+	require.NoError(t, err)
+	require.Empty(t, transaction.Splits)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestLoad(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	dbMock.ExpectQuery(`SELECT \* FROM "transaction_splits" WHERE transaction_id IN (.+) ORDER BY id`).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "transaction_id", "amount", "category"}).
+			AddRow(5, 2, "-10.00", "groceries").
+			AddRow(6, 2, "-20.00", "housing"))
+	transactions := []models.Transaction{{ID: 1}, {ID: 2}}
+
+	// When
+	err := Load(gormDB, transactions)
+
+	// Then
+	require.NoError(t, err)
+	require.Empty(t, transactions[0].Splits)
+	require.Len(t, transactions[1].Splits, 2)
+	require.Equal(t, "housing", transactions[1].Splits[1].Category)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReport(t *testing.T) {
+	// Given
+	dbMock, gormDB := setupTestDatabase(t)
+	account := 10001
+	dbMock.ExpectQuery(`SELECT category, (.+) FROM \(SELECT (.+) LEFT JOIN transaction_splits ON (.+)\) AS transactions WHERE account_id = (.+) GROUP BY "category" ORDER BY category`).
+		WithArgs(account).
+		WillReturnRows(sqlmock.NewRows([]string{"category", "count", "total_debits", "total_credits", "net"}).
+			AddRow("groceries", 2, "30.00", "0.00", "-30.00").
+			AddRow(nil, 1, "0.00", "100.00", "100.00"))
+
+	// When
+	totals, err := Report(gormDB, &account, nil, nil)
+
+	// Then
+	require.NoError(t, err)
+	require.Len(t, totals, 2)
+	require.Equal(t, "groceries", *totals[0].Category)
+	require.Equal(t, money.MustParse("30.00"), totals[0].TotalDebits)
+	require.Nil(t, totals[1].Category)
+	require.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// setupTestDatabase sets up a mock database for testing.
+func setupTestDatabase(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	db, dbMock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{SkipDefaultTransaction: true})
+	require.NoError(t, err)
+
+	return dbMock, gormDB
+}